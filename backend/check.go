@@ -0,0 +1,47 @@
+package main
+
+import (
+	"algoBharat/backend/pkg/database"
+	"fmt"
+	"log"
+	"os"
+)
+
+const checkUsage = `usage: backend check [--repair]
+
+Reports rows that reference missing rows, such as shows of deleted movies.
+--repair deletes them (bookings of deleted users keep the booking and lose the user).`
+
+// runCheck implements the "check" subcommand. It exits with status 1 when orphaned rows remain.
+func runCheck(args []string) {
+	repair := false
+	for _, arg := range args {
+		if arg != "--repair" {
+			fmt.Fprintln(os.Stderr, checkUsage)
+			os.Exit(2)
+		}
+		repair = true
+	}
+
+	database.Connect()
+	if err := database.CheckSchemaVersion(); err != nil {
+		log.Fatal(err)
+	}
+
+	reports, err := database.CheckIntegrity(repair)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, report := range reports {
+		fmt.Println(report)
+	}
+
+	if len(reports) == 0 {
+		fmt.Println("no orphaned rows found")
+		return
+	}
+	if !repair {
+		fmt.Println("run with --repair to remove them")
+		os.Exit(1)
+	}
+}
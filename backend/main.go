@@ -3,6 +3,8 @@ package main
 import (
 	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/handlers"
+	"algoBharat/backend/pkg/middleware"
+	"algoBharat/backend/pkg/repository"
 	"algoBharat/backend/pkg/routes"
 	"algoBharat/backend/pkg/services"
 	"log"
@@ -21,16 +23,38 @@ func main() {
 		log.Println("Warning: .env file not found, using system environment variables")
 	}
 
+	// "backend migrate ..." and "backend check" maintain the database instead of starting the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			runMigrate(os.Args[2:])
+			return
+		case "check":
+			runCheck(os.Args[2:])
+			return
+		}
+	}
+
+	// Refuse to start without usable token keys rather than sign tokens anyone could forge
+	signingKeys, err := services.LoadSigningKeys()
+	if err != nil {
+		log.Fatalf("Error loading JWT keys: %v", err)
+	}
+
 	database.InitDB()
 
+	// Create repositories
+	repos := repository.NewSQLRepositories(database.DB)
+
 	// Create services
-	movieService := &services.MovieServiceImpl{}
-	theatreService := &services.TheatreServiceImpl{}
-	hallService := &services.HallServiceImpl{}
-	showService := &services.ShowServiceImpl{}
-	bookingService := &services.BookingServiceImpl{}
-	analyticsService := &services.AnalyticsServiceImpl{}
-	userService := &services.UserServiceImpl{}
+	movieService := services.NewMovieService(repos.Movies, repos.Shows)
+	theatreService := services.NewTheatreService(repos.Theatres)
+	hallService := services.NewHallService(repos.Halls, theatreService)
+	showService := services.NewShowService(repos.Shows, repos.Bookings, repos.SeatHolds, movieService, hallService)
+	bookingService := services.NewBookingService(repos.Bookings, repos.SeatHolds, repos.Shows, hallService)
+	seatHoldService := services.NewSeatHoldService(repos.SeatHolds, repos.Bookings, repos.Shows, hallService)
+	analyticsService := services.NewAnalyticsService(repos.Bookings)
+	userService := services.NewUserService(repos.Users, repos.Tokens, repos.Logins, signingKeys, theatreService)
 
 	// Create handlers
 	movieHandler := handlers.NewMovieHandler(movieService)
@@ -38,19 +62,27 @@ func main() {
 	hallHandler := handlers.NewHallHandler(hallService)
 	showHandler := handlers.NewShowHandler(showService)
 	bookingHandler := handlers.NewBookingHandler(bookingService)
+	seatHoldHandler := handlers.NewSeatHoldHandler(seatHoldService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
 	userHandler := handlers.NewUserHandler(userService)
 
+	// Release expired seat holds in the background
+	seatHoldService.StartExpirySweeper()
+
 	r := mux.NewRouter()
+	// Bound the database work of every request; queries are also cancelled when the client disconnects
+	r.Use(middleware.DBTimeoutMiddleware)
 
 	// Register routes
 	routes.RegisterRoutes(
 		r,
+		userService,
 		movieHandler,
 		theatreHandler,
 		hallHandler,
 		showHandler,
 		bookingHandler,
+		seatHoldHandler,
 		analyticsHandler,
 		userHandler,
 	)
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"algoBharat/backend/pkg/database"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: backend migrate <command>
+
+commands:
+  up           apply all pending migrations
+  down [N]     revert the last N applied migrations (default 1)
+  status       list migrations and whether they are applied`
+
+// runMigrate implements the "migrate" subcommand.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	database.Connect()
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp()
+		for _, m := range applied {
+			fmt.Printf("applied  %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, "down expects a positive number of migrations to revert")
+				os.Exit(2)
+			}
+		}
+		reverted, err := database.MigrateDown(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations to revert")
+		}
+	case "status":
+		statuses, err := database.GetMigrationStatus()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+				if status.AppliedAt != nil {
+					state += " " + status.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+			}
+			fmt.Printf("%4d  %-30s %s\n", status.Version, status.Name, state)
+		}
+		if err := database.CheckSchemaVersion(); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
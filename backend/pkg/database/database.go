@@ -1,7 +1,9 @@
 package database
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"os"
@@ -16,7 +18,22 @@ import (
 
 var DB *sql.DB
 
+// Driver is the name of the database driver DB was opened with, "sqlite3" or "mysql".
+var Driver string
+
+// InitDB connects to the database, brings its schema up to date and ensures the default admin exists.
+// It refuses to start against a schema migrated by a newer version of the server.
 func InitDB() {
+	Connect()
+
+	if _, err := MigrateUp(); err != nil {
+		log.Fatal(err)
+	}
+	createDefaultAdmin() // Call the function to create default admin
+}
+
+// Connect opens DB using the DB_* environment variables without touching the schema.
+func Connect() {
 	var err error
 
 	// Get database configuration from environment variables
@@ -53,8 +70,9 @@ func InitDB() {
 		dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&tls=true",
 			dbUser, dbPassword, dbHost, dbPort, dbName)
 	} else {
-		// SQLite configuration (default)
-		dsn = "./movies.db"
+		// SQLite configuration (default). Foreign keys are off by default in SQLite,
+		// so enable them on every connection for ON DELETE CASCADE to take effect.
+		dsn = "./movies.db?_foreign_keys=on"
 	}
 
 	DB, err = sql.Open(dbDriver, dsn)
@@ -67,138 +85,92 @@ func InitDB() {
 		log.Fatal(err)
 	}
 
+	Driver = dbDriver
 	log.Printf("Connected to %s database", dbDriver)
 
-	createTables()
-	createDefaultAdmin() // Call the function to create default admin
-}
-
-func createTables() {
-	createMoviesTable := `
-	CREATE TABLE IF NOT EXISTS movies (
-		id VARCHAR(36) PRIMARY KEY,
-		title VARCHAR(255),
-		duration_minutes INT
-	);
-	`
-
-	createTheatresTable := `
-	CREATE TABLE IF NOT EXISTS theatres (
-		id VARCHAR(36) PRIMARY KEY,
-		name VARCHAR(255)
-	);
-	`
-
-	createHallsTable := `
-	CREATE TABLE IF NOT EXISTS halls (
-		id VARCHAR(36) PRIMARY KEY,
-		name VARCHAR(255),
-		theatre_id VARCHAR(36),
-		seat_map TEXT
-	);
-	`
-
-	createShowsTable := `
-	CREATE TABLE IF NOT EXISTS shows (
-		id VARCHAR(36) PRIMARY KEY,
-		movie_id VARCHAR(36),
-		hall_id VARCHAR(36),
-		time DATETIME,
-		price DECIMAL(10,2)
-	);
-	`
-
-	createSeatsTable := `
-	CREATE TABLE IF NOT EXISTS seats (
-		id VARCHAR(36) PRIMARY KEY,
-		` + "`row`" + ` INT,
-		` + "`number`" + ` INT,
-		hall_id VARCHAR(36),
-		` + "`column`" + ` INT
-	);
-	`
-
-	createBookingsTable := `
-	CREATE TABLE IF NOT EXISTS bookings (
-		id VARCHAR(36) PRIMARY KEY,
-		show_id VARCHAR(36),
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-	    seat_ids TEXT
-	);
-	`
-
-	createBookedSeatsTable := `
-	CREATE TABLE IF NOT EXISTS booked_seats (
-		show_id VARCHAR(36),
-		seat_id VARCHAR(36),
-		booking_id VARCHAR(36),
-		PRIMARY KEY (show_id, seat_id),
-		FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
-	);
-	`
-
-	createUsersTable := `
-	CREATE TABLE IF NOT EXISTS users (
-		id VARCHAR(36) PRIMARY KEY,
-		username VARCHAR(255) UNIQUE NOT NULL,
-		password_hash VARCHAR(255) NOT NULL,
-		role VARCHAR(50) NOT NULL
-	);
-	`
-
-	_, err := DB.Exec(
-		createMoviesTable +
-			createTheatresTable +
-			createHallsTable +
-			createShowsTable +
-			createSeatsTable +
-			createBookingsTable +
-			createBookedSeatsTable +
-			createUsersTable,
-	)
-	if err != nil {
-		log.Fatal(err)
+	if dbDriver == "sqlite3" {
+		var foreignKeys int
+		if err := DB.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil || foreignKeys != 1 {
+			log.Fatalf("SQLite foreign key enforcement could not be enabled: %v", err)
+		}
 	}
 }
 
+// legacyAdminPassword is the password the default admin used to be created with.
+const legacyAdminPassword = "admin123"
+
+// createDefaultAdmin creates the bootstrap admin with the password from ADMIN_PASSWORD, or a
+// random one that is logged once if it is not set. Either way the admin must change it on
+// first login. An existing admin still on the old well-known password is made to change it too.
 func createDefaultAdmin() {
 	// Check if admin user already exists
-	var count int
-	err := DB.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", "admin").Scan(&count)
-	if err != nil {
+	var passwordHash string
+	err := DB.QueryRow("SELECT password_hash FROM users WHERE username = ?", "admin").Scan(&passwordHash)
+	if err == nil {
+		if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(legacyAdminPassword)) == nil {
+			if _, err := DB.Exec("UPDATE users SET must_change_password = ? WHERE username = ?", true, "admin"); err != nil {
+				log.Printf("Error flagging default admin password: %v", err)
+				return
+			}
+			log.Println("Warning: the admin user still has the default password; it must be changed on next login")
+		} else {
+			log.Println("Default admin user already exists.")
+		}
+		return
+	}
+	if err != sql.ErrNoRows {
 		log.Printf("Error checking for default admin: %v", err)
 		return
 	}
 
-	if count == 0 {
-		// Admin user does not exist, create it
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+	// Admin user does not exist, create it
+	password := os.Getenv("ADMIN_PASSWORD")
+	generated := password == ""
+	if generated {
+		password, err = randomPassword()
 		if err != nil {
-			log.Printf("Error hashing default admin password: %v", err)
+			log.Printf("Error generating default admin password: %v", err)
 			return
 		}
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Error hashing default admin password: %v", err)
+		return
+	}
 
-		adminUser := models.User{
-			ID:           strconv.Itoa(1), // Assign a fixed ID for the default admin
-			Username:     "admin",
-			PasswordHash: string(hashedPassword),
-			Role:         "admin",
-		}
+	adminUser := models.User{
+		ID:                 strconv.Itoa(1), // Assign a fixed ID for the default admin
+		Username:           "admin",
+		PasswordHash:       string(hashedPassword),
+		Role:               models.RoleAdmin,
+		MustChangePassword: true,
+	}
 
-		stmt, err := DB.Prepare("INSERT INTO users(id, username, password_hash, role) VALUES(?, ?, ?, ?)")
-		if err != nil {
-			log.Printf("Error preparing default admin insert statement: %v", err)
-			return
-		}
-		defer stmt.Close()
+	stmt, err := DB.Prepare("INSERT INTO users(id, username, password_hash, role, must_change_password) VALUES(?, ?, ?, ?, ?)")
+	if err != nil {
+		log.Printf("Error preparing default admin insert statement: %v", err)
+		return
+	}
+	defer stmt.Close()
 
-		_, err = stmt.Exec(adminUser.ID, adminUser.Username, adminUser.PasswordHash, adminUser.Role)
-		if err != nil {
-			log.Printf("Error inserting default admin: %v", err)
-			return
-		}
-		log.Println("Default admin user created: admin/admin123")
+	_, err = stmt.Exec(adminUser.ID, adminUser.Username, adminUser.PasswordHash, adminUser.Role, adminUser.MustChangePassword)
+	if err != nil {
+		log.Printf("Error inserting default admin: %v", err)
+		return
+	}
+	if generated {
+		log.Printf("Default admin user created: admin/%s (ADMIN_PASSWORD is not set; change this password on first login)", password)
 	} else {
-		log.Println("Default admin user already exists.")
+		log.Println("Default admin user created with the password from ADMIN_PASSWORD; change it on first login")
+	}
+}
+
+// randomPassword returns a random password for the bootstrap admin.
+func randomPassword() (string, error) {
+	value := make([]byte, 12)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
 }
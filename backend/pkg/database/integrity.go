@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// referenceCheck describes one foreign key whose rows can be orphaned in databases created
+// before the constraint existed. When SetNull is true repairs clear the reference instead of
+// deleting the row.
+type referenceCheck struct {
+	Table      string
+	Column     string
+	References string
+	SetNull    bool
+}
+
+// referenceChecks are ordered parents before children, so a single repair pass also removes
+// rows orphaned by the repair of an earlier check.
+var referenceChecks = []referenceCheck{
+	{Table: "halls", Column: "theatre_id", References: "theatres"},
+	{Table: "shows", Column: "movie_id", References: "movies"},
+	{Table: "shows", Column: "hall_id", References: "halls"},
+	{Table: "seats", Column: "hall_id", References: "halls"},
+	{Table: "bookings", Column: "show_id", References: "shows"},
+	{Table: "bookings", Column: "user_id", References: "users", SetNull: true},
+	{Table: "booked_seats", Column: "booking_id", References: "bookings"},
+	{Table: "seat_holds", Column: "show_id", References: "shows"},
+	{Table: "seat_holds", Column: "user_id", References: "users"},
+	{Table: "held_seats", Column: "hold_id", References: "seat_holds"},
+}
+
+// OrphanReport describes rows whose reference points at a row that does not exist.
+type OrphanReport struct {
+	Table      string
+	Column     string
+	References string
+	Count      int
+	MissingIDs []string // Up to maxReportedMissingIDs of the referenced IDs that do not exist
+	Repaired   bool
+}
+
+const maxReportedMissingIDs = 10
+
+// orphanCondition selects the rows of check.Table whose reference is dangling.
+func orphanCondition(check referenceCheck) string {
+	return fmt.Sprintf("%s IS NOT NULL AND %s NOT IN (SELECT id FROM %s)", check.Column, check.Column, check.References)
+}
+
+// CheckIntegrity reports orphaned rows for every reference check. With repair set, orphaned
+// rows are deleted (or their reference cleared) in one transaction; dependents of deleted rows
+// are removed by the later checks of the same pass.
+func CheckIntegrity(repair bool) ([]OrphanReport, error) {
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Foreign key actions would otherwise fire on repairs and hide the orphans they create
+	if repair {
+		if err := setForeignKeys(ctx, conn, false); err != nil {
+			return nil, err
+		}
+		defer setForeignKeys(ctx, conn, true)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var reports []OrphanReport
+	for _, check := range referenceChecks {
+		report := OrphanReport{Table: check.Table, Column: check.Column, References: check.References}
+		condition := orphanCondition(check)
+
+		if err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", check.Table, condition)).Scan(&report.Count); err != nil {
+			return nil, fmt.Errorf("checking %s.%s: %w", check.Table, check.Column, err)
+		}
+		if report.Count == 0 {
+			continue
+		}
+
+		rows, err := tx.Query(fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s ORDER BY %s LIMIT %d",
+			check.Column, check.Table, condition, check.Column, maxReportedMissingIDs))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var missingID string
+			if err := rows.Scan(&missingID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			report.MissingIDs = append(report.MissingIDs, missingID)
+		}
+		rows.Close()
+
+		if repair {
+			statement := fmt.Sprintf("DELETE FROM %s WHERE %s", check.Table, condition)
+			if check.SetNull {
+				statement = fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s", check.Table, check.Column, condition)
+			}
+			if _, err := tx.Exec(statement); err != nil {
+				return nil, fmt.Errorf("repairing %s.%s: %w", check.Table, check.Column, err)
+			}
+			report.Repaired = true
+		}
+
+		reports = append(reports, report)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// String summarises the report for the check command.
+func (r OrphanReport) String() string {
+	action := "found"
+	if r.Repaired {
+		action = "repaired"
+	}
+	return fmt.Sprintf("%s %d %s rows whose %s points at missing %s (%s)",
+		action, r.Count, r.Table, r.Column, r.References, strings.Join(r.MissingIDs, ", "))
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Migration is one numbered, reversible schema change. Up and Down hold the SQL statements
+// to run for each driver ("sqlite3" or "mysql"), executed in order. UpFunc, when set, runs
+// after the Up statements for changes that depend on the current state of the schema.
+// DisableForeignKeys turns foreign key enforcement off while the migration runs, for
+// migrations that rebuild tables other tables reference.
+type Migration struct {
+	Version            int
+	Name               string
+	Up                 map[string][]string
+	Down               map[string][]string
+	UpFunc             func(tx *sql.Tx, driver string) error
+	DisableForeignKeys bool
+}
+
+// MigrationStatus reports whether a known migration has been applied, and when.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// ErrSchemaTooNew is returned when the database has migrations applied that this binary does not know about.
+type ErrSchemaTooNew struct {
+	Current int
+	Latest  int
+}
+
+func (e *ErrSchemaTooNew) Error() string {
+	return fmt.Sprintf("database schema version %d is newer than the latest version %d this server understands; upgrade the server", e.Current, e.Latest)
+}
+
+const createSchemaMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+// latestVersion returns the highest migration version known to this binary.
+func latestVersion() int {
+	latest := 0
+	for _, m := range migrations {
+		if m.Version > latest {
+			latest = m.Version
+		}
+	}
+	return latest
+}
+
+// sortedMigrations returns the known migrations in ascending version order.
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+// appliedVersions returns the applied migration versions with the time each was applied.
+func appliedVersions() (map[int]*time.Time, error) {
+	if _, err := DB.Exec(createSchemaMigrationsTable); err != nil {
+		return nil, fmt.Errorf("could not create schema_migrations table: %w", err)
+	}
+
+	rows, err := DB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]*time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = nil
+		if appliedAt.Valid {
+			t := appliedAt.Time.UTC()
+			applied[version] = &t
+		}
+	}
+	return applied, rows.Err()
+}
+
+// CheckSchemaVersion refuses databases migrated by a newer version of the server.
+func CheckSchemaVersion() error {
+	applied, err := appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	if latest := latestVersion(); current > latest {
+		return &ErrSchemaTooNew{Current: current, Latest: latest}
+	}
+	return nil
+}
+
+// MigrateUp applies every pending migration in version order and returns the ones it applied.
+func MigrateUp() ([]Migration, error) {
+	if err := CheckSchemaVersion(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range sortedMigrations() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := runMigration(m, true); err != nil {
+			return done, err
+		}
+		log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// MigrateDown reverts the most recently applied steps migrations, newest first, and returns the ones it reverted.
+func MigrateDown(steps int) ([]Migration, error) {
+	if err := CheckSchemaVersion(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := sortedMigrations()
+	var done []Migration
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		m := sorted[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := runMigration(m, false); err != nil {
+			return done, err
+		}
+		log.Printf("Reverted migration %d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// GetMigrationStatus lists every known migration in version order with its applied time, if any.
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range sortedMigrations() {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// runMigration applies (up) or reverts (down) one migration and records it in schema_migrations.
+// SQLite runs the whole migration in one transaction; MySQL commits each DDL statement
+// implicitly, so a failed MySQL migration may need manual cleanup before it is retried.
+func runMigration(m Migration, up bool) error {
+	statements := m.Down[Driver]
+	if up {
+		statements = m.Up[Driver]
+	}
+	if statements == nil && (!up || m.UpFunc == nil) {
+		return fmt.Errorf("migration %d_%s has no SQL for driver %s", m.Version, m.Name, Driver)
+	}
+
+	// Use a single connection so foreign key settings apply to the migration's transaction
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.DisableForeignKeys {
+		if err := setForeignKeys(ctx, conn, false); err != nil {
+			return err
+		}
+		defer func() {
+			if err := setForeignKeys(ctx, conn, true); err != nil {
+				log.Printf("Error re-enabling foreign keys after migration %d_%s: %v", m.Version, m.Name, err)
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	if up {
+		if m.UpFunc != nil {
+			if err := m.UpFunc(tx, Driver); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+		}
+		_, err = tx.Exec("INSERT INTO schema_migrations(version, name, applied_at) VALUES(?, ?, ?)", m.Version, m.Name, time.Now().UTC())
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setForeignKeys turns foreign key enforcement on or off for one connection. SQLite ignores
+// the setting inside a transaction, so it must be changed before the transaction begins.
+func setForeignKeys(ctx context.Context, conn *sql.Conn, enabled bool) error {
+	var statement string
+	switch {
+	case Driver == "mysql" && enabled:
+		statement = "SET FOREIGN_KEY_CHECKS = 1"
+	case Driver == "mysql":
+		statement = "SET FOREIGN_KEY_CHECKS = 0"
+	case enabled:
+		statement = "PRAGMA foreign_keys = ON"
+	default:
+		statement = "PRAGMA foreign_keys = OFF"
+	}
+	_, err := conn.ExecContext(ctx, statement)
+	return err
+}
+
+// addColumnIfMissing adds a column to a table unless the table already has it.
+func addColumnIfMissing(tx *sql.Tx, driver string, table string, column string, definition string) error {
+	var count int
+	var err error
+	if driver == "mysql" {
+		err = tx.QueryRow("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?",
+			table, column).Scan(&count)
+	} else {
+		err = tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	}
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	log.Printf("Adding missing column %s.%s", table, column)
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN `%s` %s", table, column, definition))
+	return err
+}
@@ -0,0 +1,556 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// migrations lists every schema change in order. Never edit a migration that has shipped;
+// add a new one with the next version instead. Migrations up to seat_categories replay what
+// createTables used to do, so they tolerate tables and columns that already exist.
+var migrations = []Migration{
+	{
+		// The schema as created by createTables before migrations existed. IF NOT EXISTS lets
+		// databases created by that code adopt it without changes.
+		Version: 1,
+		Name:    "initial_schema",
+		Up: map[string][]string{
+			"sqlite3": initialSchema(""),
+			"mysql":   initialSchema(" ENGINE=InnoDB"),
+		},
+		Down: sameForAllDrivers(
+			"DROP TABLE IF EXISTS booked_seats",
+			"DROP TABLE IF EXISTS bookings",
+			"DROP TABLE IF EXISTS seats",
+			"DROP TABLE IF EXISTS shows",
+			"DROP TABLE IF EXISTS halls",
+			"DROP TABLE IF EXISTS theatres",
+			"DROP TABLE IF EXISTS movies",
+			"DROP TABLE IF EXISTS users",
+		),
+	},
+	{
+		// Columns that createTables added over time never reached databases created before them.
+		Version: 2,
+		Name:    "backfill_legacy_columns",
+		Down:    sameForAllDrivers(),
+		UpFunc: addColumns(
+			column{"movies", "duration_minutes", "INT"},
+			column{"shows", "price", "DECIMAL(10,2)"},
+			column{"seats", "column", "INT"},
+			column{"bookings", "seat_ids", "TEXT"},
+		),
+	},
+	{
+		Version: 3,
+		Name:    "booking_and_show_lifecycle",
+		UpFunc: addColumns(
+			column{"shows", "status", "VARCHAR(20) NOT NULL DEFAULT 'scheduled'"},
+			column{"bookings", "user_id", "VARCHAR(36)"},
+			column{"bookings", "status", "VARCHAR(20) NOT NULL DEFAULT 'confirmed'"},
+			column{"bookings", "cancelled_at", "TIMESTAMP NULL"},
+			column{"bookings", "unit_price", "DECIMAL(10,2)"},
+			column{"bookings", "show_changed_at", "TIMESTAMP NULL"},
+			column{"bookings", "notice", "VARCHAR(255)"},
+		),
+		Down: sameForAllDrivers(
+			"ALTER TABLE bookings DROP COLUMN notice",
+			"ALTER TABLE bookings DROP COLUMN show_changed_at",
+			"ALTER TABLE bookings DROP COLUMN unit_price",
+			"ALTER TABLE bookings DROP COLUMN cancelled_at",
+			"ALTER TABLE bookings DROP COLUMN status",
+			"ALTER TABLE bookings DROP COLUMN user_id",
+			"ALTER TABLE shows DROP COLUMN status",
+		),
+	},
+	{
+		Version: 4,
+		Name:    "seat_holds",
+		Up: map[string][]string{
+			"sqlite3": seatHoldTables(""),
+			"mysql":   seatHoldTables(" ENGINE=InnoDB"),
+		},
+		Down: sameForAllDrivers(
+			"DROP TABLE IF EXISTS held_seats",
+			"DROP TABLE IF EXISTS seat_holds",
+		),
+	},
+	{
+		Version: 5,
+		Name:    "hall_missing_seats",
+		UpFunc:  addColumns(column{"halls", "missing_seats", "TEXT"}),
+		Down:    sameForAllDrivers("ALTER TABLE halls DROP COLUMN missing_seats"),
+	},
+	{
+		Version: 6,
+		Name:    "seat_categories",
+		UpFunc: addColumns(
+			column{"halls", "seat_categories", "TEXT"},
+			column{"shows", "category_prices", "TEXT"},
+			column{"bookings", "total_price", "DECIMAL(10,2)"},
+			column{"booked_seats", "category", "VARCHAR(50)"},
+			column{"booked_seats", "price", "DECIMAL(10,2)"},
+		),
+		Down: sameForAllDrivers(
+			"ALTER TABLE booked_seats DROP COLUMN price",
+			"ALTER TABLE booked_seats DROP COLUMN category",
+			"ALTER TABLE bookings DROP COLUMN total_price",
+			"ALTER TABLE shows DROP COLUMN category_prices",
+			"ALTER TABLE halls DROP COLUMN seat_categories",
+		),
+	},
+	{
+		// SQLite cannot add a foreign key to an existing table, so its tables are rebuilt.
+		// Existing rows are not validated; run "backend check" to find orphans.
+		Version:            7,
+		Name:               "foreign_keys_and_indexes",
+		DisableForeignKeys: true,
+		Up: map[string][]string{
+			"sqlite3": append(sqliteRebuildTables(true), createIndexes...),
+			"mysql": append(createIndexes,
+				"ALTER TABLE halls ADD CONSTRAINT fk_halls_theatre FOREIGN KEY (theatre_id) REFERENCES theatres(id)",
+				"ALTER TABLE shows ADD CONSTRAINT fk_shows_movie FOREIGN KEY (movie_id) REFERENCES movies(id)",
+				"ALTER TABLE shows ADD CONSTRAINT fk_shows_hall FOREIGN KEY (hall_id) REFERENCES halls(id)",
+				"ALTER TABLE seats ADD CONSTRAINT fk_seats_hall FOREIGN KEY (hall_id) REFERENCES halls(id) ON DELETE CASCADE",
+				"ALTER TABLE bookings ADD CONSTRAINT fk_bookings_show FOREIGN KEY (show_id) REFERENCES shows(id)",
+				"ALTER TABLE bookings ADD CONSTRAINT fk_bookings_user FOREIGN KEY (user_id) REFERENCES users(id)",
+				"ALTER TABLE seat_holds ADD CONSTRAINT fk_seat_holds_show FOREIGN KEY (show_id) REFERENCES shows(id) ON DELETE CASCADE",
+				"ALTER TABLE seat_holds ADD CONSTRAINT fk_seat_holds_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE",
+			),
+		},
+		Down: map[string][]string{
+			"sqlite3": append(dropIndexes(""), sqliteRebuildTables(false)...),
+			"mysql": append([]string{
+				"ALTER TABLE seat_holds DROP FOREIGN KEY fk_seat_holds_user",
+				"ALTER TABLE seat_holds DROP FOREIGN KEY fk_seat_holds_show",
+				"ALTER TABLE bookings DROP FOREIGN KEY fk_bookings_user",
+				"ALTER TABLE bookings DROP FOREIGN KEY fk_bookings_show",
+				"ALTER TABLE seats DROP FOREIGN KEY fk_seats_hall",
+				"ALTER TABLE shows DROP FOREIGN KEY fk_shows_hall",
+				"ALTER TABLE shows DROP FOREIGN KEY fk_shows_movie",
+				"ALTER TABLE halls DROP FOREIGN KEY fk_halls_theatre",
+			}, dropIndexes(" ON ")...),
+		},
+	},
+	{
+		Version: 8,
+		Name:    "archived_at",
+		UpFunc: addColumns(
+			column{"movies", "archived_at", "TIMESTAMP NULL"},
+			column{"theatres", "archived_at", "TIMESTAMP NULL"},
+			column{"halls", "archived_at", "TIMESTAMP NULL"},
+			column{"shows", "archived_at", "TIMESTAMP NULL"},
+		),
+		Down: sameForAllDrivers(
+			"ALTER TABLE shows DROP COLUMN archived_at",
+			"ALTER TABLE halls DROP COLUMN archived_at",
+			"ALTER TABLE theatres DROP COLUMN archived_at",
+			"ALTER TABLE movies DROP COLUMN archived_at",
+		),
+	},
+	{
+		Version: 9,
+		Name:    "auth_tokens",
+		Up: map[string][]string{
+			"sqlite3": authTokenTables(""),
+			"mysql":   authTokenTables(" ENGINE=InnoDB"),
+		},
+		Down: sameForAllDrivers(
+			"DROP TABLE IF EXISTS revoked_tokens",
+			"DROP TABLE IF EXISTS refresh_tokens",
+			"ALTER TABLE users DROP COLUMN token_version",
+		),
+	},
+	{
+		// The catch-all "user" role became "customer" when roles gained permissions
+		Version: 10,
+		Name:    "customer_role",
+		Up:      sameForAllDrivers("UPDATE users SET role = 'customer' WHERE role = 'user'"),
+		Down:    sameForAllDrivers("UPDATE users SET role = 'user' WHERE role <> 'admin'"),
+	},
+	{
+		Version: 11,
+		Name:    "theatre_members",
+		Up: map[string][]string{
+			"sqlite3": theatreMembersTable(""),
+			"mysql":   theatreMembersTable(" ENGINE=InnoDB"),
+		},
+		Down: sameForAllDrivers("DROP TABLE IF EXISTS theatre_members"),
+	},
+	{
+		Version: 12,
+		Name:    "password_resets",
+		Up: map[string][]string{
+			"sqlite3": passwordResetTables(""),
+			"mysql":   passwordResetTables(" ENGINE=InnoDB"),
+		},
+		Down: sameForAllDrivers(
+			"DROP TABLE IF EXISTS password_reset_tokens",
+			"ALTER TABLE users DROP COLUMN must_change_password",
+		),
+	},
+	{
+		Version: 13,
+		Name:    "login_attempts",
+		Up: map[string][]string{
+			"sqlite3": loginAttemptsTable(""),
+			"mysql":   loginAttemptsTable(" ENGINE=InnoDB"),
+		},
+		Down: sameForAllDrivers("DROP TABLE IF EXISTS login_attempts"),
+	},
+	{
+		// Show times are filtered by comparing the stored text, which needs one format and offset.
+		Version: 14,
+		Name:    "normalize_show_times",
+		UpFunc:  normalizeShowTimes,
+		Down:    sameForAllDrivers(),
+	},
+}
+
+// indexes added by foreign_keys_and_indexes, as name, table and column.
+var indexes = [][3]string{
+	{"idx_halls_theatre_id", "halls", "theatre_id"},
+	{"idx_shows_movie_id", "shows", "movie_id"},
+	{"idx_shows_hall_id", "shows", "hall_id"},
+	{"idx_seats_hall_id", "seats", "hall_id"},
+	{"idx_bookings_show_id", "bookings", "show_id"},
+	{"idx_bookings_user_id", "bookings", "user_id"},
+	{"idx_booked_seats_booking_id", "booked_seats", "booking_id"},
+	{"idx_seat_holds_show_id", "seat_holds", "show_id"},
+	{"idx_seat_holds_user_id", "seat_holds", "user_id"},
+	{"idx_held_seats_hold_id", "held_seats", "hold_id"},
+}
+
+var createIndexes = func() []string {
+	var statements []string
+	for _, index := range indexes {
+		statements = append(statements, fmt.Sprintf("CREATE INDEX %s ON %s (%s)", index[0], index[1], index[2]))
+	}
+	return statements
+}()
+
+// dropIndexes returns DROP INDEX statements; MySQL needs the table named after " ON ".
+func dropIndexes(onTable string) []string {
+	var statements []string
+	for _, index := range indexes {
+		statement := "DROP INDEX " + index[0]
+		if onTable != "" {
+			statement += onTable + index[1]
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// sqliteRebuildTables recreates the tables that gain foreign keys in foreign_keys_and_indexes,
+// with the constraints when withForeignKeys is set and without them otherwise, keeping their rows.
+func sqliteRebuildTables(withForeignKeys bool) []string {
+	tables := []struct {
+		name        string
+		columns     string
+		definition  string
+		foreignKeys string
+	}{
+		{
+			name:    "halls",
+			columns: "id, name, theatre_id, seat_map, missing_seats, seat_categories",
+			definition: `id VARCHAR(36) PRIMARY KEY,
+				name VARCHAR(255),
+				theatre_id VARCHAR(36),
+				seat_map TEXT,
+				missing_seats TEXT,
+				seat_categories TEXT`,
+			foreignKeys: `,
+				FOREIGN KEY (theatre_id) REFERENCES theatres(id)`,
+		},
+		{
+			name:    "shows",
+			columns: "id, movie_id, hall_id, time, price, status, category_prices",
+			definition: `id VARCHAR(36) PRIMARY KEY,
+				movie_id VARCHAR(36),
+				hall_id VARCHAR(36),
+				time DATETIME,
+				price DECIMAL(10,2),
+				status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+				category_prices TEXT`,
+			foreignKeys: `,
+				FOREIGN KEY (movie_id) REFERENCES movies(id),
+				FOREIGN KEY (hall_id) REFERENCES halls(id)`,
+		},
+		{
+			name:    "seats",
+			columns: "id, `row`, `number`, hall_id, `column`",
+			definition: `id VARCHAR(36) PRIMARY KEY,
+				` + "`row`" + ` INT,
+				` + "`number`" + ` INT,
+				hall_id VARCHAR(36),
+				` + "`column`" + ` INT`,
+			foreignKeys: `,
+				FOREIGN KEY (hall_id) REFERENCES halls(id) ON DELETE CASCADE`,
+		},
+		{
+			name:    "bookings",
+			columns: "id, show_id, created_at, seat_ids, user_id, status, cancelled_at, unit_price, total_price, show_changed_at, notice",
+			definition: `id VARCHAR(36) PRIMARY KEY,
+				show_id VARCHAR(36),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				seat_ids TEXT,
+				user_id VARCHAR(36),
+				status VARCHAR(20) NOT NULL DEFAULT 'confirmed',
+				cancelled_at TIMESTAMP NULL,
+				unit_price DECIMAL(10,2),
+				total_price DECIMAL(10,2),
+				show_changed_at TIMESTAMP NULL,
+				notice VARCHAR(255)`,
+			foreignKeys: `,
+				FOREIGN KEY (show_id) REFERENCES shows(id),
+				FOREIGN KEY (user_id) REFERENCES users(id)`,
+		},
+		{
+			name:    "seat_holds",
+			columns: "id, show_id, user_id, expires_at, created_at",
+			definition: `id VARCHAR(36) PRIMARY KEY,
+				show_id VARCHAR(36),
+				user_id VARCHAR(36),
+				expires_at DATETIME,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`,
+			foreignKeys: `,
+				FOREIGN KEY (show_id) REFERENCES shows(id) ON DELETE CASCADE,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE`,
+		},
+	}
+
+	var statements []string
+	for _, table := range tables {
+		definition := table.definition
+		if withForeignKeys {
+			definition += table.foreignKeys
+		}
+		statements = append(statements,
+			fmt.Sprintf("CREATE TABLE %s_rebuild (%s)", table.name, definition),
+			fmt.Sprintf("INSERT INTO %s_rebuild (%s) SELECT %s FROM %s", table.name, table.columns, table.columns, table.name),
+			fmt.Sprintf("DROP TABLE %s", table.name),
+			fmt.Sprintf("ALTER TABLE %s_rebuild RENAME TO %s", table.name, table.name),
+		)
+	}
+	return statements
+}
+
+// normalizeShowTimes rewrites every show time in UTC RFC3339, the format new shows are stored
+// in. MySQL DATETIME columns hold no offset, so only SQLite has anything to rewrite. Times that
+// do not parse are logged and left as they are.
+func normalizeShowTimes(tx *sql.Tx, driver string) error {
+	if driver != "sqlite3" {
+		return nil
+	}
+
+	rows, err := tx.Query("SELECT id, time FROM shows")
+	if err != nil {
+		return err
+	}
+	normalized := map[string]string{}
+	for rows.Next() {
+		var id string
+		var value sql.NullString
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		if !value.Valid {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value.String)
+		if err != nil {
+			log.Printf("Leaving show %s with unparseable time %q", id, value.String)
+			continue
+		}
+		normalized[id] = t.UTC().Format(time.RFC3339)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, value := range normalized {
+		if _, err := tx.Exec("UPDATE shows SET time = ? WHERE id = ?", value, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// column describes a column added by a migration.
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+// addColumns returns an UpFunc that adds each column its table does not already have.
+func addColumns(columns ...column) func(tx *sql.Tx, driver string) error {
+	return func(tx *sql.Tx, driver string) error {
+		for _, c := range columns {
+			if err := addColumnIfMissing(tx, driver, c.table, c.name, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// sameForAllDrivers uses the same statements for every supported driver.
+func sameForAllDrivers(statements ...string) map[string][]string {
+	if statements == nil {
+		statements = []string{}
+	}
+	return map[string][]string{
+		"sqlite3": statements,
+		"mysql":   statements,
+	}
+}
+
+// initialSchema returns the original tables. tableOptions is appended to each CREATE TABLE;
+// MySQL needs InnoDB for its foreign keys to be enforced.
+func initialSchema(tableOptions string) []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS movies (
+			id VARCHAR(36) PRIMARY KEY,
+			title VARCHAR(255),
+			duration_minutes INT
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS theatres (
+			id VARCHAR(36) PRIMARY KEY,
+			name VARCHAR(255)
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS halls (
+			id VARCHAR(36) PRIMARY KEY,
+			name VARCHAR(255),
+			theatre_id VARCHAR(36),
+			seat_map TEXT
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS shows (
+			id VARCHAR(36) PRIMARY KEY,
+			movie_id VARCHAR(36),
+			hall_id VARCHAR(36),
+			time DATETIME,
+			price DECIMAL(10,2)
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS seats (
+			id VARCHAR(36) PRIMARY KEY,
+			` + "`row`" + ` INT,
+			` + "`number`" + ` INT,
+			hall_id VARCHAR(36),
+			` + "`column`" + ` INT
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS bookings (
+			id VARCHAR(36) PRIMARY KEY,
+			show_id VARCHAR(36),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			seat_ids TEXT
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS booked_seats (
+			show_id VARCHAR(36),
+			seat_id VARCHAR(36),
+			booking_id VARCHAR(36),
+			PRIMARY KEY (show_id, seat_id),
+			FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS users (
+			id VARCHAR(36) PRIMARY KEY,
+			username VARCHAR(255) UNIQUE NOT NULL,
+			password_hash VARCHAR(255) NOT NULL,
+			role VARCHAR(50) NOT NULL
+		)` + tableOptions,
+	}
+}
+
+// seatHoldTables returns the tables backing temporary seat holds.
+func seatHoldTables(tableOptions string) []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS seat_holds (
+			id VARCHAR(36) PRIMARY KEY,
+			show_id VARCHAR(36),
+			user_id VARCHAR(36),
+			expires_at DATETIME,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)` + tableOptions,
+		`CREATE TABLE IF NOT EXISTS held_seats (
+			show_id VARCHAR(36),
+			seat_id VARCHAR(36),
+			hold_id VARCHAR(36),
+			PRIMARY KEY (show_id, seat_id),
+			FOREIGN KEY (hold_id) REFERENCES seat_holds(id) ON DELETE CASCADE
+		)` + tableOptions,
+	}
+}
+
+// authTokenTables returns the user token version, the stored refresh tokens and the
+// revocation list of access tokens.
+func authTokenTables(tableOptions string) []string {
+	return []string{
+		"ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0",
+		`CREATE TABLE refresh_tokens (
+			id VARCHAR(64) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL,
+			family_id VARCHAR(36) NOT NULL,
+			token_version INT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			revoked_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)` + tableOptions,
+		"CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id)",
+		"CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id)",
+		`CREATE TABLE revoked_tokens (
+			id VARCHAR(36) PRIMARY KEY,
+			expires_at DATETIME NOT NULL
+		)` + tableOptions,
+	}
+}
+
+// theatreMembersTable returns the theatres each staff user may manage.
+func theatreMembersTable(tableOptions string) []string {
+	return []string{
+		`CREATE TABLE theatre_members (
+			user_id VARCHAR(36) NOT NULL,
+			theatre_id VARCHAR(36) NOT NULL,
+			PRIMARY KEY (user_id, theatre_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (theatre_id) REFERENCES theatres(id) ON DELETE CASCADE
+		)` + tableOptions,
+		"CREATE INDEX idx_theatre_members_theatre_id ON theatre_members (theatre_id)",
+	}
+}
+
+// passwordResetTables returns the flag forcing a user to change their password and the
+// one-time password reset tokens issued by admins.
+func passwordResetTables(tableOptions string) []string {
+	return []string{
+		"ALTER TABLE users ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE",
+		`CREATE TABLE password_reset_tokens (
+			id VARCHAR(64) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL,
+			expires_at DATETIME NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)` + tableOptions,
+		"CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens (user_id)",
+	}
+}
+
+// loginAttemptsTable returns the recent failed logins and lockouts of usernames and client IPs.
+func loginAttemptsTable(tableOptions string) []string {
+	return []string{
+		`CREATE TABLE login_attempts (
+			kind VARCHAR(16) NOT NULL,
+			value VARCHAR(255) NOT NULL,
+			failures INT NOT NULL,
+			last_failure_at DATETIME NOT NULL,
+			locked_until DATETIME NULL,
+			PRIMARY KEY (kind, value)
+		)` + tableOptions,
+		"CREATE INDEX idx_login_attempts_locked_until ON login_attempts (locked_until)",
+	}
+}
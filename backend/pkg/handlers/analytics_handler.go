@@ -23,9 +23,9 @@ func (h *AnalyticsHandler) GetMovieRevenue(w http.ResponseWriter, r *http.Reques
 	params := mux.Vars(r)
 	movieID := params["id"]
 
-	revenue, err := h.service.GetMovieRevenue(movieID)
+	revenue, err := h.service.GetMovieRevenue(r.Context(), movieID)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 
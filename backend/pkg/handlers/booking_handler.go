@@ -1,11 +1,12 @@
 package handlers
 
 import (
+	"algoBharat/backend/pkg/middleware"
 	"algoBharat/backend/pkg/services"
 	"algoBharat/backend/pkg/utils"
-	"encoding/json"
 	"net/http"
-	"strings"
+
+	"github.com/gorilla/mux"
 )
 
 // BookingHandler handles HTTP requests for bookings.
@@ -21,31 +22,37 @@ func NewBookingHandler(service services.BookingService) *BookingHandler { // Cor
 // CreateBooking handles the POST /bookings request.
 func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	var request services.BookingRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &request) {
 		return
 	}
+	identity, _ := middleware.IdentityFromContext(r.Context())
+	request.UserID = identity.UserID
 
-	createdBooking, err := h.service.CreateBooking(request)
+	createdBooking, err := h.service.CreateBooking(r.Context(), request)
 	if err != nil {
-		// Check if it's a no contiguous seats error or show not found error
-		if _, ok := err.(*services.ErrNoContiguousSeats); ok ||
-			(err != nil && (strings.Contains(err.Error(), "no show found") || strings.Contains(err.Error(), "no contiguous seats"))) {
-			alternatives, altErr := h.service.FindAlternativeShows(request.Time, request.NumSeats)
-			if altErr != nil {
-				utils.RespondError(w, http.StatusInternalServerError, "Seats are booked and failed to find alternatives")
-				return
-			}
-			if len(alternatives) > 0 {
-				utils.RespondJSON(w, http.StatusConflict, map[string]interface{}{
-					"message":      "Could not book seats together for the requested show. Here are some alternatives for the same day:",
-					"alternatives": alternatives,
-				})
-			} else {
-				utils.RespondJSON(w, http.StatusConflict, map[string]string{"message": "Could not book seats together for the requested show, and no same-day alternatives are available."})
-			}
+		// When the show has no block of seats large enough, suggest other shows that day
+		noSeatsErr, noSeats := err.(*services.ErrNoContiguousSeats)
+		if !noSeats {
+			utils.RespondServiceError(w, err)
+			return
+		}
+
+		searchTime := request.Time
+		if noSeatsErr.ShowTime != "" {
+			searchTime = noSeatsErr.ShowTime
+		}
+		alternatives, altErr := h.service.FindAlternativeShows(r.Context(), searchTime, request.NumSeats, request.Category)
+		if altErr != nil {
+			utils.RespondServiceError(w, altErr)
+			return
+		}
+		if len(alternatives) > 0 {
+			utils.RespondErrorData(w, http.StatusConflict, noSeatsErr.Code(),
+				"Could not book seats together for the requested show. Here are some alternatives for the same day:",
+				map[string]interface{}{"alternatives": alternatives})
 		} else {
-			utils.RespondError(w, http.StatusInternalServerError, err.Error())
+			utils.RespondErrorData(w, http.StatusConflict, noSeatsErr.Code(),
+				"Could not book seats together for the requested show, and no same-day alternatives are available.", nil)
 		}
 		return
 	}
@@ -57,14 +64,42 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 func (h *BookingHandler) GetBookings(w http.ResponseWriter, r *http.Request) {
 	showID := r.URL.Query().Get("showId")
 	if showID == "" {
-		utils.RespondError(w, http.StatusBadRequest, "Missing showId query parameter")
+		utils.RespondServiceError(w, services.NewValidationError("showId", "is required"))
 		return
 	}
 
-	bookings, err := h.service.GetBookingsByShowID(showID)
+	bookings, err := h.service.GetBookingsByShowID(r.Context(), showID)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, bookings)
+}
+
+// GetMyBookings handles the GET /me/bookings request.
+func (h *BookingHandler) GetMyBookings(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.IdentityFromContext(r.Context())
+
+	bookings, err := h.service.GetBookingsByUserID(r.Context(), identity.UserID)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, bookings)
 }
+
+// CancelBooking handles the DELETE /bookings/{id} request.
+func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	identity, _ := middleware.IdentityFromContext(r.Context())
+	if !allowQueryParams(w, r) {
+		return
+	}
+
+	booking, err := h.service.CancelBooking(r.Context(), params["id"], identity.UserID, identity.Can(services.PermCancelBookings))
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, booking)
+}
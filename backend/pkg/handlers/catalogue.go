@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"algoBharat/backend/pkg/middleware"
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/services"
+	"algoBharat/backend/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+// boolParam is a boolean query parameter and the variable it is read into.
+type boolParam struct {
+	name string
+	dest *bool
+}
+
+// parseBoolParams reads boolean query parameters. It responds with 400 and returns false if one is invalid.
+func parseBoolParams(w http.ResponseWriter, r *http.Request, params ...boolParam) bool {
+	query := r.URL.Query()
+	for _, param := range params {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondServiceError(w, services.NewValidationError(param.name, "expected true or false"))
+			return false
+		}
+		*param.dest = parsed
+	}
+	return true
+}
+
+// parseDeleteOptions reads the permanent, dry_run and force query parameters of a theatre or
+// hall delete, rejecting any other. Without permanent the record is archived, which dry_run
+// and force do not apply to.
+func parseDeleteOptions(w http.ResponseWriter, r *http.Request) (opts services.DeleteOptions, permanent bool, ok bool) {
+	if !allowQueryParams(w, r, "permanent", "dry_run", "force") {
+		return opts, permanent, false
+	}
+	if !parseBoolParams(w, r, boolParam{"permanent", &permanent}, boolParam{"dry_run", &opts.DryRun}, boolParam{"force", &opts.Force}) {
+		return opts, permanent, false
+	}
+	if !permanent && (opts.DryRun || opts.Force) {
+		utils.RespondServiceError(w, services.NewValidationError("permanent", "dry_run and force only apply to permanent deletes"))
+		return opts, permanent, false
+	}
+	return opts, permanent, true
+}
+
+// respondDeleted reports the outcome of a cascading delete of the named entity ("Theatre" or "Hall").
+func respondDeleted(w http.ResponseWriter, entity string, summary models.DeletionSummary, err error) {
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	message := entity + " deleted successfully"
+	if summary.DryRun {
+		message = "Dry run: nothing was deleted"
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"summary": summary,
+	})
+}
+
+// includeArchived reads the includeArchived query parameter of a public listing. Only users
+// allowed to view archived records may see them; anyone else asking for them gets 403 and
+// false as ok.
+func includeArchived(w http.ResponseWriter, r *http.Request) (include bool, ok bool) {
+	if !parseBoolParams(w, r, boolParam{"includeArchived", &include}) {
+		return false, false
+	}
+	if identity, _ := middleware.IdentityFromContext(r.Context()); include && !identity.Can(services.PermViewArchived) {
+		utils.RespondServiceError(w, &services.ErrForbidden{Reason: "requires the archive:view permission to include archived records"})
+		return false, false
+	}
+	return include, true
+}
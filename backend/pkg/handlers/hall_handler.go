@@ -4,7 +4,6 @@ import (
 	"algoBharat/backend/pkg/models"
 	"algoBharat/backend/pkg/services"
 	"algoBharat/backend/pkg/utils"
-	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -21,11 +20,16 @@ func NewHallHandler(service services.HallService) *HallHandler {
 }
 
 // GetHalls handles the GET /halls request.
+// Admins can pass includeArchived=true to also list archived halls.
 func (h *HallHandler) GetHalls(w http.ResponseWriter, r *http.Request) {
 	theatreID := r.URL.Query().Get("theatreId") // Get theatreId from query parameter
-	halls, err := h.service.GetHalls(theatreID)
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	halls, err := h.service.GetHalls(r.Context(), theatreID, include)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, halls)
@@ -34,9 +38,17 @@ func (h *HallHandler) GetHalls(w http.ResponseWriter, r *http.Request) {
 // GetHall handles the GET /halls/{id} request.
 func (h *HallHandler) GetHall(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	hall, err := h.service.GetHall(params["id"])
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	hall, err := h.service.GetHall(r.Context(), params["id"])
 	if err != nil {
-		utils.RespondError(w, http.StatusNotFound, err.Error())
+		utils.RespondServiceError(w, err)
+		return
+	}
+	if hall.ArchivedAt != nil && !include {
+		utils.RespondServiceError(w, &services.ErrHallNotFound{})
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, hall)
@@ -45,14 +57,17 @@ func (h *HallHandler) GetHall(w http.ResponseWriter, r *http.Request) {
 // CreateHall handles the POST /halls request.
 func (h *HallHandler) CreateHall(w http.ResponseWriter, r *http.Request) {
 	var hall models.Hall
-	if err := json.NewDecoder(r.Body).Decode(&hall); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &hall) {
 		return
 	}
 
-	createdHall, err := h.service.CreateHall(hall)
+	createdHall, err := h.service.CreateHall(r.Context(), hall)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		if _, ok := err.(*services.ErrInvalidLayout); ok {
+			utils.RespondServiceError(w, err)
+			return
+		}
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusCreated, createdHall)
@@ -62,38 +77,69 @@ func (h *HallHandler) CreateHall(w http.ResponseWriter, r *http.Request) {
 func (h *HallHandler) UpdateHall(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	var hall models.Hall
-	if err := json.NewDecoder(r.Body).Decode(&hall); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &hall) {
 		return
 	}
 
 	hall.ID = params["id"] // Ensure the ID from URL is used
-	updatedHall, err := h.service.UpdateHall(hall)
+	updatedHall, err := h.service.UpdateHall(r.Context(), hall)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		if _, ok := err.(*services.ErrInvalidLayout); ok {
+			utils.RespondServiceError(w, err)
+			return
+		}
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, updatedHall)
 }
 
-// DeleteHall handles the DELETE /halls/{id} request.
+// DeleteHall handles the DELETE /halls/{id} request. The hall and its shows are archived,
+// unless permanent=true is passed; permanent deletes support dry_run and force as for
+// DELETE /theatres/{id}.
 func (h *HallHandler) DeleteHall(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	err := h.service.DeleteHall(params["id"])
+	opts, permanent, ok := parseDeleteOptions(w, r)
+	if !ok {
+		return
+	}
+
+	if !permanent {
+		if err := h.service.ArchiveHall(r.Context(), params["id"]); err != nil {
+			utils.RespondServiceError(w, err)
+			return
+		}
+		utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Hall archived successfully"})
+		return
+	}
+
+	summary, err := h.service.DeleteHall(r.Context(), params["id"], opts)
+	respondDeleted(w, "Hall", summary, err)
+}
+
+// RestoreHall handles the POST /halls/{id}/restore request.
+func (h *HallHandler) RestoreHall(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	hall, err := h.service.RestoreHall(r.Context(), params["id"])
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
-	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Hall deleted successfully"})
+	utils.RespondJSON(w, http.StatusOK, hall)
 }
 
 // GetHallSeats handles the GET /halls/{id}/seats request.
 func (h *HallHandler) GetHallSeats(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	seats, err := h.service.GetHallSeats(params["id"])
+	seats, err := h.service.GetHallSeats(r.Context(), params["id"])
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, seats)
 }
+
+// GetLayoutLimits handles the GET /halls/layout-limits request.
+func (h *HallHandler) GetLayoutLimits(w http.ResponseWriter, r *http.Request) {
+	utils.RespondJSON(w, http.StatusOK, h.service.GetLayoutLimits())
+}
@@ -4,7 +4,6 @@ import (
 	"algoBharat/backend/pkg/models"
 	"algoBharat/backend/pkg/services"
 	"algoBharat/backend/pkg/utils"
-	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -21,10 +20,15 @@ func NewMovieHandler(service services.MovieService) *MovieHandler {
 }
 
 // GetMovies handles the GET /movies request.
+// Admins can pass includeArchived=true to also list archived movies.
 func (h *MovieHandler) GetMovies(w http.ResponseWriter, r *http.Request) {
-	movies, err := h.service.GetMovies()
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	movies, err := h.service.GetMovies(r.Context(), include)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, movies)
@@ -33,9 +37,17 @@ func (h *MovieHandler) GetMovies(w http.ResponseWriter, r *http.Request) {
 // GetMovie handles the GET /movies/{id} request.
 func (h *MovieHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	movie, err := h.service.GetMovie(params["id"])
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	movie, err := h.service.GetMovie(r.Context(), params["id"])
 	if err != nil {
-		utils.RespondError(w, http.StatusNotFound, err.Error())
+		utils.RespondServiceError(w, err)
+		return
+	}
+	if movie.ArchivedAt != nil && !include {
+		utils.RespondServiceError(w, &services.ErrMovieNotFound{})
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, movie)
@@ -44,14 +56,13 @@ func (h *MovieHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
 // CreateMovie handles the POST /movies request.
 func (h *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 	var movie models.Movie
-	if err := json.NewDecoder(r.Body).Decode(&movie); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &movie) {
 		return
 	}
 
-	createdMovie, err := h.service.CreateMovie(movie)
+	createdMovie, err := h.service.CreateMovie(r.Context(), movie)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusCreated, createdMovie)
@@ -61,25 +72,50 @@ func (h *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 func (h *MovieHandler) UpdateMovie(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	var movie models.Movie
-	if err := json.NewDecoder(r.Body).Decode(&movie); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &movie) {
 		return
 	}
 
-	updatedMovie, err := h.service.UpdateMovie(params["id"], movie)
+	updatedMovie, err := h.service.UpdateMovie(r.Context(), params["id"], movie)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, updatedMovie)
 }
 
-// DeleteMovie handles the DELETE /movies/{id} request.
+// DeleteMovie handles the DELETE /movies/{id} request. The movie and its shows are archived,
+// unless permanent=true is passed to delete a movie that has no shows.
 func (h *MovieHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	if err := h.service.DeleteMovie(params["id"]); err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+	var permanent bool
+	if !allowQueryParams(w, r, "permanent") || !parseBoolParams(w, r, boolParam{"permanent", &permanent}) {
+		return
+	}
+
+	if !permanent {
+		if err := h.service.ArchiveMovie(r.Context(), params["id"]); err != nil {
+			utils.RespondServiceError(w, err)
+			return
+		}
+		utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Movie archived successfully"})
+		return
+	}
+
+	if err := h.service.DeleteMovie(r.Context(), params["id"]); err != nil {
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Movie deleted successfully"})
 }
+
+// RestoreMovie handles the POST /movies/{id}/restore request.
+func (h *MovieHandler) RestoreMovie(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	movie, err := h.service.RestoreMovie(r.Context(), params["id"])
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, movie)
+}
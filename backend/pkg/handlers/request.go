@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"algoBharat/backend/pkg/services"
+	"algoBharat/backend/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxBodyBytes caps the size of JSON request bodies.
+const maxBodyBytes = 1 << 20
+
+// decodeJSON decodes the JSON request body into dest, rejecting fields dest does not have. It
+// responds with 400 and field errors, and returns false, if the body is not valid.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dest interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dest)
+	if err == nil && decoder.More() {
+		err = errors.New("body must contain a single JSON object")
+	}
+	if err != nil {
+		utils.RespondServiceError(w, &services.ErrValidation{Fields: []services.FieldError{bodyFieldError(err)}})
+		return false
+	}
+	return true
+}
+
+// allowQueryParams rejects query parameters other than allowed, as decodeJSON rejects unknown
+// body fields. Destructive endpoints use it so that a misspelt flag fails instead of being
+// ignored, which could turn a preview into a real delete. It responds with 400 and field
+// errors, and returns false, if there are any.
+func allowQueryParams(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
+	known := make(map[string]bool)
+	for _, name := range allowed {
+		known[name] = true
+	}
+
+	var fields []services.FieldError
+	for name := range r.URL.Query() {
+		if !known[name] {
+			fields = append(fields, services.FieldError{Field: name, Message: "is not a known parameter"})
+		}
+	}
+	if len(fields) == 0 {
+		return true
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	utils.RespondServiceError(w, &services.ErrValidation{Fields: fields})
+	return false
+}
+
+// bodyFieldError describes a JSON decoding error as an error on the field it concerns, or on
+// the whole body.
+func bodyFieldError(err error) services.FieldError {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return services.FieldError{Field: typeErr.Field, Message: fmt.Sprintf("must be a %s", jsonTypeName(typeErr.Type.Kind().String()))}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return services.FieldError{Field: field, Message: "is not a known field"}
+	case errors.As(err, &maxBytesErr):
+		return services.FieldError{Field: "body", Message: fmt.Sprintf("must not be larger than %d bytes", maxBodyBytes)}
+	case errors.Is(err, io.EOF):
+		return services.FieldError{Field: "body", Message: "is required"}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return services.FieldError{Field: "body", Message: "must be valid JSON"}
+	default:
+		return services.FieldError{Field: "body", Message: err.Error()}
+	}
+}
+
+// jsonTypeName names a Go kind the way the JSON it decodes from is described to clients.
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "number"
+	case kind == "slice", kind == "array":
+		return "list"
+	case kind == "map", kind == "struct":
+		return "object"
+	case kind == "bool":
+		return "boolean"
+	default:
+		return kind
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"algoBharat/backend/pkg/middleware"
+	"algoBharat/backend/pkg/services"
+	"algoBharat/backend/pkg/utils"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SeatHoldHandler handles HTTP requests for seat holds.
+type SeatHoldHandler struct {
+	service services.SeatHoldService
+}
+
+// NewSeatHoldHandler creates a new SeatHoldHandler.
+func NewSeatHoldHandler(service services.SeatHoldService) *SeatHoldHandler {
+	return &SeatHoldHandler{service: service}
+}
+
+// CreateHold handles the POST /holds request.
+func (h *SeatHoldHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	var request services.BookingRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	identity, _ := middleware.IdentityFromContext(r.Context())
+	request.UserID = identity.UserID
+
+	hold, err := h.service.CreateHold(r.Context(), request)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, hold)
+}
+
+// GetHold handles the GET /holds/{id} request.
+func (h *SeatHoldHandler) GetHold(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	identity, _ := middleware.IdentityFromContext(r.Context())
+
+	hold, err := h.service.GetHold(r.Context(), params["id"], identity.UserID)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, hold)
+}
+
+// ConfirmHold handles the POST /holds/{id}/confirm request.
+func (h *SeatHoldHandler) ConfirmHold(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	identity, _ := middleware.IdentityFromContext(r.Context())
+
+	booking, err := h.service.ConfirmHold(r.Context(), params["id"], identity.UserID)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, booking)
+}
+
+// ReleaseHold handles the DELETE /holds/{id} request.
+func (h *SeatHoldHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	identity, _ := middleware.IdentityFromContext(r.Context())
+	if !allowQueryParams(w, r) {
+		return
+	}
+
+	if err := h.service.ReleaseHold(r.Context(), params["id"], identity.UserID); err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Seat hold released successfully"})
+}
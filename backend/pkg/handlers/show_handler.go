@@ -4,8 +4,11 @@ import (
 	"algoBharat/backend/pkg/models"
 	"algoBharat/backend/pkg/services"
 	"algoBharat/backend/pkg/utils"
-	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // ShowHandler handles HTTP requests for shows.
@@ -19,10 +22,56 @@ func NewShowHandler(service services.ShowService) *ShowHandler {
 }
 
 // GetShows handles the GET /shows request.
+// Supported query parameters: movieId, hallId, theatreId, from, to (RFC3339), minFreeSeats, limit and offset.
+// Admins can pass includeArchived=true to also list archived shows.
 func (h *ShowHandler) GetShows(w http.ResponseWriter, r *http.Request) {
-	shows, err := h.service.GetShows()
+	query := r.URL.Query()
+	filter := services.ShowFilter{
+		MovieID:   query.Get("movieId"),
+		HallID:    query.Get("hallId"),
+		TheatreID: query.Get("theatreId"),
+	}
+
+	var ok bool
+	if filter.IncludeArchived, ok = includeArchived(w, r); !ok {
+		return
+	}
+
+	for _, param := range []struct {
+		name string
+		dest *string
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.RespondServiceError(w, services.NewValidationError(param.name, "expected an RFC3339 time"))
+			return
+		}
+		*param.dest = parsed.UTC().Format(time.RFC3339)
+	}
+
+	for _, param := range []struct {
+		name string
+		dest *int
+	}{{"minFreeSeats", &filter.MinFreeSeats}, {"limit", &filter.Limit}, {"offset", &filter.Offset}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			utils.RespondServiceError(w, services.NewValidationError(param.name, "expected a non-negative integer"))
+			return
+		}
+		*param.dest = parsed
+	}
+
+	shows, err := h.service.GetShows(r.Context(), filter)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, shows)
@@ -31,15 +80,112 @@ func (h *ShowHandler) GetShows(w http.ResponseWriter, r *http.Request) {
 // CreateShow handles the POST /shows request.
 func (h *ShowHandler) CreateShow(w http.ResponseWriter, r *http.Request) {
 	var show models.Show
-	if err := json.NewDecoder(r.Body).Decode(&show); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &show) {
 		return
 	}
 
-	createdShow, err := h.service.CreateShow(show)
+	createdShow, err := h.service.CreateShow(r.Context(), show)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusCreated, createdShow)
 }
+
+// GetShow handles the GET /shows/{id} request.
+func (h *ShowHandler) GetShow(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	show, err := h.service.GetShow(r.Context(), params["id"])
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	if show.ArchivedAt != nil && !include {
+		utils.RespondServiceError(w, &services.ErrShowNotFound{})
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, show)
+}
+
+// UpdateShow handles the PUT /shows/{id} request.
+func (h *ShowHandler) UpdateShow(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	var show models.Show
+	if !decodeJSON(w, r, &show) {
+		return
+	}
+
+	updatedShow, err := h.service.UpdateShow(r.Context(), params["id"], show)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, updatedShow)
+}
+
+// CancelShow handles the POST /shows/{id}/cancel request.
+func (h *ShowHandler) CancelShow(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cancelledShow, err := h.service.CancelShow(r.Context(), params["id"])
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, cancelledShow)
+}
+
+// DeleteShow handles the DELETE /shows/{id} request. The show is archived, unless
+// permanent=true is passed to delete a show that has never been booked.
+func (h *ShowHandler) DeleteShow(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	var permanent bool
+	if !allowQueryParams(w, r, "permanent") || !parseBoolParams(w, r, boolParam{"permanent", &permanent}) {
+		return
+	}
+
+	if !permanent {
+		if err := h.service.ArchiveShow(r.Context(), params["id"]); err != nil {
+			utils.RespondServiceError(w, err)
+			return
+		}
+		utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Show archived successfully"})
+		return
+	}
+
+	if err := h.service.DeleteShow(r.Context(), params["id"]); err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Show deleted successfully"})
+}
+
+// RestoreShow handles the POST /shows/{id}/restore request.
+func (h *ShowHandler) RestoreShow(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	show, err := h.service.RestoreShow(r.Context(), params["id"])
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, show)
+}
+
+// GetShowSeats handles the GET /shows/{id}/seats request. Archived shows are only found with
+// includeArchived=true.
+func (h *ShowHandler) GetShowSeats(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	availability, err := h.service.GetShowSeats(r.Context(), params["id"], include)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, availability)
+}
@@ -4,7 +4,6 @@ import (
 	"algoBharat/backend/pkg/models"
 	"algoBharat/backend/pkg/services"
 	"algoBharat/backend/pkg/utils"
-	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -21,10 +20,15 @@ func NewTheatreHandler(service services.TheatreService) *TheatreHandler {
 }
 
 // GetTheatres handles the GET /theatres request.
+// Admins can pass includeArchived=true to also list archived theatres.
 func (h *TheatreHandler) GetTheatres(w http.ResponseWriter, r *http.Request) {
-	theatres, err := h.service.GetTheatres()
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	theatres, err := h.service.GetTheatres(r.Context(), include)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, theatres)
@@ -33,9 +37,17 @@ func (h *TheatreHandler) GetTheatres(w http.ResponseWriter, r *http.Request) {
 // GetTheatre handles the GET /theatres/{id} request.
 func (h *TheatreHandler) GetTheatre(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	theatre, err := h.service.GetTheatre(params["id"])
+	include, ok := includeArchived(w, r)
+	if !ok {
+		return
+	}
+	theatre, err := h.service.GetTheatre(r.Context(), params["id"])
 	if err != nil {
-		utils.RespondError(w, http.StatusNotFound, err.Error())
+		utils.RespondServiceError(w, err)
+		return
+	}
+	if theatre.ArchivedAt != nil && !include {
+		utils.RespondServiceError(w, &services.ErrTheatreNotFound{})
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, theatre)
@@ -44,14 +56,13 @@ func (h *TheatreHandler) GetTheatre(w http.ResponseWriter, r *http.Request) {
 // CreateTheatre handles the POST /theatres request.
 func (h *TheatreHandler) CreateTheatre(w http.ResponseWriter, r *http.Request) {
 	var theatre models.Theatre
-	if err := json.NewDecoder(r.Body).Decode(&theatre); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &theatre) {
 		return
 	}
 
-	createdTheatre, err := h.service.CreateTheatre(theatre)
+	createdTheatre, err := h.service.CreateTheatre(r.Context(), theatre)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusCreated, createdTheatre)
@@ -61,25 +72,49 @@ func (h *TheatreHandler) CreateTheatre(w http.ResponseWriter, r *http.Request) {
 func (h *TheatreHandler) UpdateTheatre(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	var theatre models.Theatre
-	if err := json.NewDecoder(r.Body).Decode(&theatre); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &theatre) {
 		return
 	}
 
-	updatedTheatre, err := h.service.UpdateTheatre(params["id"], theatre)
+	updatedTheatre, err := h.service.UpdateTheatre(r.Context(), params["id"], theatre)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, updatedTheatre)
 }
 
-// DeleteTheatre handles the DELETE /theatres/{id} request.
+// DeleteTheatre handles the DELETE /theatres/{id} request. The theatre, its halls and their
+// shows are archived, unless permanent=true is passed to delete them with their bookings.
+// Permanent deletes support dry_run, to only count what would be deleted, and force, to delete
+// even when upcoming shows have confirmed bookings.
 func (h *TheatreHandler) DeleteTheatre(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	if err := h.service.DeleteTheatre(params["id"]); err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+	opts, permanent, ok := parseDeleteOptions(w, r)
+	if !ok {
+		return
+	}
+
+	if !permanent {
+		if err := h.service.ArchiveTheatre(r.Context(), params["id"]); err != nil {
+			utils.RespondServiceError(w, err)
+			return
+		}
+		utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Theatre archived successfully"})
 		return
 	}
-	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Theatre deleted successfully"})
+
+	summary, err := h.service.DeleteTheatre(r.Context(), params["id"], opts)
+	respondDeleted(w, "Theatre", summary, err)
+}
+
+// RestoreTheatre handles the POST /theatres/{id}/restore request.
+func (h *TheatreHandler) RestoreTheatre(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	theatre, err := h.service.RestoreTheatre(r.Context(), params["id"])
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, theatre)
 }
@@ -1,9 +1,10 @@
 package handlers
 
 import (
+	"algoBharat/backend/pkg/middleware"
+	"algoBharat/backend/pkg/models"
 	"algoBharat/backend/pkg/services"
 	"algoBharat/backend/pkg/utils"
-	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -22,14 +23,13 @@ func NewUserHandler(service services.UserService) *UserHandler {
 // Register handles the POST /register request.
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var creds services.Credentials
-	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &creds) {
 		return
 	}
 
-	user, err := h.service.Register(creds)
+	user, err := h.service.Register(r.Context(), creds)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 
@@ -39,25 +39,64 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 // Login handles the POST /login request.
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var creds services.Credentials
-	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &creds) {
 		return
 	}
 
-	token, err := h.service.Login(creds)
+	tokens, err := h.service.Login(r.Context(), creds, middleware.ClientIP(r))
 	if err != nil {
-		utils.RespondError(w, http.StatusUnauthorized, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 
-	utils.RespondJSON(w, http.StatusOK, map[string]string{"token": token})
+	utils.RespondJSON(w, http.StatusOK, tokens)
+}
+
+// refreshTokenRequest is the body of POST /token/refresh and POST /logout.
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken handles the POST /token/refresh request.
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var requestBody refreshTokenRequest
+	if !decodeJSON(w, r, &requestBody) {
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), requestBody.RefreshToken)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, tokens)
+}
+
+// Logout handles the POST /logout request. It revokes the access token the request was made
+// with and, when the optional body names one, the refresh token of the same login.
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.IdentityFromContext(r.Context())
+
+	var requestBody refreshTokenRequest
+	if r.ContentLength != 0 && !decodeJSON(w, r, &requestBody) {
+		return
+	}
+
+	err := h.service.Logout(r.Context(), identity.UserID, identity.TokenID, identity.TokenExpiresAt, requestBody.RefreshToken)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
 
 // GetUsers handles the GET /users request.
 func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.service.GetUsers()
+	users, err := h.service.GetUsers(r.Context())
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, users)
@@ -71,16 +110,129 @@ func (h *UserHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 	var requestBody struct {
 		Role string `json:"role"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &requestBody) {
 		return
 	}
 
-	updatedUser, err := h.service.UpdateUserRole(userID, requestBody.Role)
+	updatedUser, err := h.service.UpdateUserRole(r.Context(), userID, requestBody.Role)
 	if err != nil {
-		utils.RespondError(w, http.StatusInternalServerError, err.Error())
+		utils.RespondServiceError(w, err)
 		return
 	}
 
 	utils.RespondJSON(w, http.StatusOK, updatedUser)
 }
+
+// SetUserTheatres handles the PUT /users/{id}/theatres request.
+func (h *UserHandler) SetUserTheatres(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID := params["id"]
+
+	var requestBody struct {
+		TheatreIDs []string `json:"theatre_ids"`
+	}
+	if !decodeJSON(w, r, &requestBody) {
+		return
+	}
+
+	updatedUser, err := h.service.SetUserTheatres(r.Context(), userID, requestBody.TheatreIDs)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, updatedUser)
+}
+
+// GetPasswordPolicy handles the GET /password-policy request.
+func (h *UserHandler) GetPasswordPolicy(w http.ResponseWriter, r *http.Request) {
+	utils.RespondJSON(w, http.StatusOK, h.service.GetPasswordPolicy())
+}
+
+// ChangePassword handles the PUT /me/password request. It responds with new tokens, because
+// the ones the request was made with stop working.
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.IdentityFromContext(r.Context())
+
+	var requestBody struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if !decodeJSON(w, r, &requestBody) {
+		return
+	}
+
+	tokens, err := h.service.ChangePassword(r.Context(), identity.UserID, requestBody.CurrentPassword, requestBody.NewPassword)
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, tokens)
+}
+
+// IssuePasswordReset handles the POST /users/{id}/password-reset request.
+func (h *UserHandler) IssuePasswordReset(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	reset, err := h.service.IssuePasswordReset(r.Context(), params["id"])
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, reset)
+}
+
+// ResetPassword handles the POST /password/reset request.
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		ResetToken  string `json:"reset_token"`
+		NewPassword string `json:"new_password"`
+	}
+	if !decodeJSON(w, r, &requestBody) {
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), requestBody.ResetToken, requestBody.NewPassword); err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Password has been reset; log in with the new password"})
+}
+
+// GetLoginLockouts handles the GET /login-lockouts request.
+func (h *UserHandler) GetLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.service.GetLoginLockouts(r.Context())
+	if err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+	if lockouts == nil {
+		lockouts = []models.LoginAttempts{}
+	}
+	utils.RespondJSON(w, http.StatusOK, lockouts)
+}
+
+// ClearLoginLockout handles the DELETE /login-lockouts?kind=username|ip&value=... request.
+func (h *UserHandler) ClearLoginLockout(w http.ResponseWriter, r *http.Request) {
+	if !allowQueryParams(w, r, "kind", "value") {
+		return
+	}
+	query := r.URL.Query()
+	if err := h.service.ClearLoginLockout(r.Context(), query.Get("kind"), query.Get("value")); err != nil {
+		utils.RespondServiceError(w, err)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Lockout cleared"})
+}
+
+// GetJWKS handles the GET /.well-known/jwks.json request. The key set is served in the standard
+// JWKS format so that other services' JWT libraries can fetch it directly.
+func (h *UserHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	// Let verifiers cache the keys briefly; a new key is published before it starts signing
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.RespondRawJSON(w, http.StatusOK, h.service.GetJWKS())
+}
@@ -1,87 +1,133 @@
 package middleware
 
 import (
+	"algoBharat/backend/pkg/services"
+	"algoBharat/backend/pkg/utils"
 	"context"
+	"fmt"
 	"net/http"
-	"os"
 	"strings"
-
-	"github.com/golang-jwt/jwt/v4"
 )
 
-// getJWTKey returns the JWT secret key from environment variables
-func getJWTKey() []byte {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "my_secret_key" // Default fallback
+// Authenticator verifies access tokens. services.UserService implements it.
+type Authenticator interface {
+	// Authenticate returns the claims of a valid access token, or an error if it is invalid,
+	// expired or revoked.
+	Authenticate(ctx context.Context, accessToken string) (*services.Claims, error)
+}
+
+// bearerToken returns the token of a request's Authorization header. It returns the message to
+// reject the request with instead if the header is missing or malformed.
+func bearerToken(r *http.Request) (string, string) {
+	// 1. Get the token from the header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", "Authorization header required"
 	}
-	return []byte(secret)
+
+	// 2. The header should be in the format "Bearer <token>"
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", "Authorization header format must be Bearer {token}"
+	}
+
+	return parts[1], ""
 }
 
-// AuthMiddleware verifies the JWT token from the Authorization header.
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 1. Get the token from the header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
+// withUser stores the user identified by claims, and the theatres they may manage, in the
+// request context for downstream handlers and services.
+func withUser(r *http.Request, claims *services.Claims) *http.Request {
+	identity := Identity{
+		UserID:         claims.Subject,
+		Role:           claims.Role,
+		Permissions:    claims.Permissions,
+		TokenID:        claims.ID,
+		TokenExpiresAt: claims.ExpiresAt.Time,
+	}
+	scope := services.TheatreScope{
+		All:        identity.Can(services.PermAllTheatres),
+		TheatreIDs: claims.Theatres,
+	}
+	ctx := services.WithTheatreScope(WithIdentity(r.Context(), identity), scope)
+	return r.WithContext(ctx)
+}
 
-		// 2. The header should be in the format "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Authorization header format must be Bearer {token}", http.StatusUnauthorized)
-			return
-		}
+// AuthMiddleware verifies the access token from the Authorization header with authenticator.
+// Tokens of users who must change their password are refused.
+func AuthMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return authMiddleware(authenticator, false)
+}
 
-		tokenString := parts[1]
-		claims := &jwt.RegisteredClaims{}
+// PasswordChangeAuthMiddleware is AuthMiddleware for the routes a user who must change their
+// password can still use: changing it and logging out.
+func PasswordChangeAuthMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return authMiddleware(authenticator, true)
+}
 
-		// 3. Parse and validate the token
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return getJWTKey(), nil
-		})
+func authMiddleware(authenticator Authenticator, allowPasswordChange bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString, message := bearerToken(r)
+			if tokenString == "" {
+				utils.RespondError(w, http.StatusUnauthorized, message)
+				return
+			}
 
-		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				http.Error(w, "Invalid token signature", http.StatusUnauthorized)
+			// 3. Verify the token, including that it has not been revoked
+			claims, err := authenticator.Authenticate(r.Context(), tokenString)
+			if err != nil {
+				utils.RespondServiceError(w, err)
+				return
+			}
+			if claims.PasswordChangeRequired && !allowPasswordChange {
+				utils.RespondServiceError(w, &services.ErrPasswordChangeRequired{})
 				return
 			}
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
 
-		if !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
+			// 4. Token is valid. Store user info in the request context for downstream handlers.
+			next.ServeHTTP(w, withUser(r, claims))
+		})
+	}
+}
 
-		// 4. Token is valid. Store user info in the request context for downstream handlers.
-		ctx := context.WithValue(r.Context(), "userID", claims.Subject)
-		ctx = context.WithValue(ctx, "userRole", claims.Issuer) // We stored the role in the Issuer field
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// OptionalAuthMiddleware identifies the user of a valid token like AuthMiddleware, but lets
+// requests without a valid token through anonymously, as well as users who must change their
+// password. It is used on public routes that show admins more, such as archived records.
+func OptionalAuthMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tokenString, _ := bearerToken(r); tokenString != "" {
+				if claims, err := authenticator.Authenticate(r.Context(), tokenString); err == nil && !claims.PasswordChangeRequired {
+					r = withUser(r, claims)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-// AdminOnlyMiddleware checks if the user has the 'admin' role.
-// This middleware MUST run AFTER AuthMiddleware.
-func AdminOnlyMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 1. Get the user role from the context (set by AuthMiddleware)
-		role := r.Context().Value("userRole") // Corrected from GetValue to Value
-		if role == nil {
-			http.Error(w, "User role not found in context", http.StatusInternalServerError)
-			return
-		}
+// RequirePermission lets through only users whose token grants every one of permissions.
+// It MUST run AFTER AuthMiddleware.
+func RequirePermission(permissions ...services.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 1. Get the user from the context (set by AuthMiddleware)
+			identity, ok := IdentityFromContext(r.Context())
+			if !ok {
+				utils.RespondError(w, http.StatusInternalServerError, "User identity not found in context")
+				return
+			}
 
-		// 2. Check if the role is 'admin'
-		if role.(string) != "admin" {
-			http.Error(w, "Forbidden: Admins only", http.StatusForbidden)
-			return
-		}
+			// 2. Check the user has every permission the route needs
+			for _, permission := range permissions {
+				if !identity.Can(permission) {
+					utils.RespondServiceError(w, &services.ErrForbidden{Reason: fmt.Sprintf("requires the %s permission", permission)})
+					return
+				}
+			}
 
-		// 3. User is an admin. Proceed to the next handler.
-		next.ServeHTTP(w, r)
-	})
+			// 3. User is allowed. Proceed to the next handler.
+			next.ServeHTTP(w, r)
+		})
+	}
 }
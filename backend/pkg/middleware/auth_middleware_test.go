@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"algoBharat/backend/pkg/services"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// staticAuthenticator accepts every token as the user of its claims.
+type staticAuthenticator struct {
+	claims services.Claims
+}
+
+func (a staticAuthenticator) Authenticate(ctx context.Context, accessToken string) (*services.Claims, error) {
+	claims := a.claims
+	return &claims, nil
+}
+
+func TestPasswordChangeGate(t *testing.T) {
+	tests := []struct {
+		name           string
+		middleware     func(Authenticator) func(http.Handler) http.Handler
+		mustChange     bool
+		wantStatus     int
+		wantIdentified bool
+	}{
+		{
+			name:           "auth, password current",
+			middleware:     AuthMiddleware,
+			wantStatus:     http.StatusOK,
+			wantIdentified: true,
+		},
+		{
+			name:       "auth, password change required",
+			middleware: AuthMiddleware,
+			mustChange: true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:           "password change routes, password change required",
+			middleware:     PasswordChangeAuthMiddleware,
+			mustChange:     true,
+			wantStatus:     http.StatusOK,
+			wantIdentified: true,
+		},
+		{
+			name:           "optional auth, password current",
+			middleware:     OptionalAuthMiddleware,
+			wantStatus:     http.StatusOK,
+			wantIdentified: true,
+		},
+		{
+			name:       "optional auth, password change required is anonymous",
+			middleware: OptionalAuthMiddleware,
+			mustChange: true,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := staticAuthenticator{claims: services.Claims{
+				Role:                   "admin",
+				PasswordChangeRequired: tt.mustChange,
+				RegisteredClaims: jwt.RegisteredClaims{
+					ID:        "token",
+					Subject:   "1",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				},
+			}}
+			var identified bool
+			handler := tt.middleware(authenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, identified = IdentityFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if identified != tt.wantIdentified {
+				t.Errorf("identified = %t, want %t", identified, tt.wantIdentified)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// trustedProxyHops returns how many reverse proxies in front of the server the
+// TRUST_PROXY_HEADERS environment variable says to trust: a count, or true for one.
+func trustedProxyHops() int {
+	value := os.Getenv("TRUST_PROXY_HEADERS")
+	if hops, err := strconv.Atoi(value); err == nil && hops > 0 {
+		return hops
+	}
+	if trusted, _ := strconv.ParseBool(value); trusted {
+		return 1
+	}
+	return 0
+}
+
+// ClientIP returns the address a request came from. X-Forwarded-For is only believed when
+// TRUST_PROXY_HEADERS is set, since any client can send it; otherwise it would let a client
+// dodge per-IP limits by claiming a new address on every request. Even then only the entries
+// appended by the trusted proxies count: each appends the address it received the request
+// from, so behind n proxies the client is the nth entry from the right, and anything further
+// left was written by the client. A header too short to have passed through every trusted
+// proxy is ignored.
+func ClientIP(r *http.Request) string {
+	if hops := trustedProxyHops(); hops > 0 {
+		var entries []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			entries = append(entries, strings.Split(header, ",")...)
+		}
+		if len(entries) >= hops {
+			if ip := strings.TrimSpace(entries[len(entries)-hops]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trust      string
+		remoteAddr string
+		// forwardedFor holds the X-Forwarded-For header lines, each of which may list several entries
+		forwardedFor []string
+		want         string
+	}{
+		{
+			name:         "header ignored unless trusted",
+			forwardedFor: []string{"203.0.113.7"},
+			want:         "192.0.2.1",
+		},
+		{
+			name:         "one trusted proxy",
+			trust:        "1",
+			forwardedFor: []string{"203.0.113.7"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "true trusts one proxy",
+			trust:        "true",
+			forwardedFor: []string{"203.0.113.7"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "spoofed leftmost entry",
+			trust:        "1",
+			forwardedFor: []string{"6.6.6.6, 203.0.113.7"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "two trusted proxies",
+			trust:        "2",
+			forwardedFor: []string{"6.6.6.6, 203.0.113.7, 10.0.0.2"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "entries split over header lines",
+			trust:        "2",
+			forwardedFor: []string{"6.6.6.6, 203.0.113.7", "10.0.0.2"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "fewer entries than trusted proxies",
+			trust:        "2",
+			forwardedFor: []string{"203.0.113.7"},
+			want:         "192.0.2.1",
+		},
+		{
+			name:         "empty entry",
+			trust:        "1",
+			forwardedFor: []string{"203.0.113.7, "},
+			want:         "192.0.2.1",
+		},
+		{
+			name:  "no header",
+			trust: "1",
+			want:  "192.0.2.1",
+		},
+		{
+			name:       "remote address without a port",
+			remoteAddr: "192.0.2.1",
+			want:       "192.0.2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUST_PROXY_HEADERS", tt.trust)
+			r := httptest.NewRequest(http.MethodPost, "/login", nil)
+			r.RemoteAddr = "192.0.2.1:54321"
+			if tt.remoteAddr != "" {
+				r.RemoteAddr = tt.remoteAddr
+			}
+			for _, header := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"algoBharat/backend/pkg/services"
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Identity is the authenticated user a request is made by.
+type Identity struct {
+	UserID      string
+	Role        string
+	Permissions []services.Permission
+	// TokenID and TokenExpiresAt identify the access token the request was authenticated
+	// with, so logging out can revoke it
+	TokenID        string
+	TokenExpiresAt time.Time
+}
+
+// Can reports whether the user has permission.
+func (i Identity) Can(permission services.Permission) bool {
+	for _, granted := range i.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// identityKey is the context key of the Identity; being unexported, no other package can collide with it.
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the user stored by AuthMiddleware or OptionalAuthMiddleware.
+// ok is false for anonymous requests.
+func IdentityFromContext(ctx context.Context) (identity Identity, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+const defaultDBTimeout = 10 * time.Second
+
+// getDBTimeout returns how long a request may spend on database work, from the DB_TIMEOUT environment variable
+func getDBTimeout() time.Duration {
+	value := os.Getenv("DB_TIMEOUT")
+	if value == "" {
+		return defaultDBTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Printf("Warning: invalid DB_TIMEOUT %q, using default %s", value, defaultDBTimeout)
+		return defaultDBTimeout
+	}
+	return timeout
+}
+
+// DBTimeoutMiddleware gives every request a deadline of DB_TIMEOUT. Services and repositories
+// run their queries with the request context, so queries still running at the deadline, or
+// when the client disconnects, are cancelled.
+func DBTimeoutMiddleware(next http.Handler) http.Handler {
+	timeout := getDBTimeout()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,26 +1,48 @@
 package models
 
+import "time"
+
 // Movie represents a movie
 type Movie struct {
 	ID              string `json:"id"`
 	Title           string `json:"title"`
 	DurationMinutes int    `json:"duration_minutes"` // Added DurationMinutes field
+	// ArchivedAt is set when the movie has been archived; archived records are hidden from public listings
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 // Theatre represents a movie theatre
 type Theatre struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	// ArchivedAt is set when the theatre has been archived
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 // Hall represents a hall in a theatre
 type Hall struct {
-	ID        string         `json:"id"`
-	Name      string         `json:"name"`
-	TheatreID string         `json:"theatre_id"`
-	SeatMap   map[string][]int `json:"seat_map"` // Updated to support column-based layout
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	TheatreID string           `json:"theatre_id"`
+	SeatMap   map[string][]int `json:"seat_map"` // Row number -> seats in each column block; blocks are separated by aisles
+	// MissingSeats lists seat IDs (row-column-number) inside the seat map that do not exist, e.g. gaps or pillars
+	MissingSeats []string `json:"missing_seats,omitempty"`
+	// SeatCategories maps a row number or a seat ID to a seat category such as "premium" or "recliner".
+	// Seat entries override their row's entry; seats without one are SeatCategoryRegular.
+	SeatCategories map[string]string `json:"seat_categories,omitempty"`
+	// ArchivedAt is set when the hall has been archived
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
+// SeatCategoryRegular is the category of seats that a hall layout does not assign one to.
+const SeatCategoryRegular = "regular"
+
+// Show statuses. Cancelled shows are kept for booking history but can no longer be booked.
+const (
+	ShowStatusScheduled = "scheduled"
+	ShowStatusCancelled = "cancelled"
+)
+
 // Show represents a movie show
 type Show struct {
 	ID      string  `json:"id"`
@@ -28,28 +50,211 @@ type Show struct {
 	HallID  string  `json:"hall_id"`
 	Time    string  `json:"time"`
 	Price   float64 `json:"price"` // Added Price field
+	Status  string  `json:"status"`
+	// CategoryPrices sets the seat price per seat category; categories not listed cost Price
+	CategoryPrices map[string]float64 `json:"category_prices,omitempty"`
+	// ArchivedAt is set when the show has been archived
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// ShowPage represents one page of a show listing
+type ShowPage struct {
+	Shows  []Show `json:"shows"`
+	Total  int    `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// DeletionSummary counts the records removed by deleting a theatre or hall, or that would be removed in a dry run
+type DeletionSummary struct {
+	Halls          int  `json:"halls"`
+	Shows          int  `json:"shows"`
+	Bookings       int  `json:"bookings"`
+	UpcomingShows  int  `json:"upcoming_shows"`  // Scheduled shows yet to start that have confirmed bookings
+	ActiveBookings int  `json:"active_bookings"` // Confirmed bookings for those upcoming shows
+	DryRun         bool `json:"dry_run"`
 }
 
 // Seat represents a seat in a hall
 type Seat struct {
-	ID      string `json:"id"`
-	Row     int    `json:"row"`
-	Number  int    `json:"number"`
-	HallID  string `json:"hall_id"`
-	Column  int    `json:"column"` // Added to store the column number
+	ID       string `json:"id"`
+	Row      int    `json:"row"`
+	Number   int    `json:"number"`
+	HallID   string `json:"hall_id"`
+	Column   int    `json:"column"` // Added to store the column number
+	Category string `json:"category,omitempty"`
+}
+
+// Seat availability statuses for a show. Free seats of an archived show are unavailable.
+const (
+	SeatStatusAvailable   = "available"
+	SeatStatusBooked      = "booked"
+	SeatStatusHeld        = "held"
+	SeatStatusUnavailable = "unavailable"
+)
+
+// ShowSeat represents a seat of a hall together with its availability for a specific show
+type ShowSeat struct {
+	ID       string  `json:"id"`
+	Row      int     `json:"row"`
+	Column   int     `json:"column"`
+	Number   int     `json:"number"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	Status   string  `json:"status"`
+}
+
+// ShowSeatAvailability represents the live seat availability of a show
+type ShowSeatAvailability struct {
+	ShowID    string     `json:"show_id"`
+	HallID    string     `json:"hall_id"`
+	Total     int        `json:"total"`
+	Available int        `json:"available"`
+	Booked    int        `json:"booked"`
+	Held      int        `json:"held"`
+	Seats     []ShowSeat `json:"seats"`
 }
 
+// Booking statuses. Cancelled bookings are kept for history but no longer hold seats.
+const (
+	BookingStatusConfirmed     = "confirmed"
+	BookingStatusCancelled     = "cancelled"
+	BookingStatusShowCancelled = "show_cancelled"
+)
+
 // Booking represents a ticket booking
 type Booking struct {
-	ID      string   `json:"id"`
-	ShowID  string   `json:"show_id"`
-	SeatIDs []string `json:"seat_ids"`
+	ID          string       `json:"id"`
+	ShowID      string       `json:"show_id"`
+	UserID      string       `json:"user_id"`
+	SeatIDs     []string     `json:"seat_ids"`
+	UnitPrice   float64      `json:"unit_price"`  // Base show price at the time of booking
+	TotalPrice  float64      `json:"total_price"` // Sum of the seat prices actually charged
+	Seats       []BookedSeat `json:"seats,omitempty"`
+	Status      string       `json:"status"`
+	CancelledAt *time.Time   `json:"cancelled_at,omitempty"`
+	// ShowChangedAt and Notice flag bookings whose show was rescheduled or cancelled after booking
+	ShowChangedAt *time.Time `json:"show_changed_at,omitempty"`
+	Notice        string     `json:"notice,omitempty"`
 }
 
+// BookedSeat represents one seat of a booking with the category and price it was booked at
+type BookedSeat struct {
+	SeatID   string  `json:"seat_id"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+}
+
+// BookingDetails represents a booking joined with its show, movie, hall and theatre
+type BookingDetails struct {
+	Booking
+	ShowTime    string `json:"show_time"`
+	MovieID     string `json:"movie_id"`
+	MovieTitle  string `json:"movie_title"`
+	HallID      string `json:"hall_id"`
+	HallName    string `json:"hall_name"`
+	TheatreID   string `json:"theatre_id"`
+	TheatreName string `json:"theatre_name"`
+}
+
+// SeatHold represents seats of a show temporarily reserved for a user before checkout
+type SeatHold struct {
+	ID        string    `json:"id"`
+	ShowID    string    `json:"show_id"`
+	UserID    string    `json:"user_id"`
+	SeatIDs   []string  `json:"seat_ids"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// User roles, from least to most privileged
+const (
+	RoleCustomer       = "customer"
+	RoleBoxOffice      = "box_office"
+	RoleTheatreManager = "theatre_manager"
+	RoleAdmin          = "admin"
+)
+
 // User represents an application user.
 type User struct {
 	ID           string `json:"id"`
 	Username     string `json:"username"`
 	PasswordHash string `json:"-"` // Do not expose password hash in JSON responses
-	Role         string `json:"role"` // e.g., "user", "admin"
+	Role         string `json:"role"` // One of the Role constants
+	// TokenVersion is bumped whenever the user's role, theatres or password change, which
+	// invalidates every token issued before
+	TokenVersion int `json:"-"`
+	// TheatreIDs lists the theatres the user is a member of; staff act only on these theatres
+	TheatreIDs []string `json:"theatre_ids,omitempty"`
+	// MustChangePassword is set for users who may do nothing but change their password, such as
+	// the bootstrap admin until their first password change
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+}
+
+// RefreshToken represents a stored refresh token. Only a hash of the token is kept. Tokens
+// issued by rotating one another share the FamilyID of the login that started them.
+type RefreshToken struct {
+	ID           string // SHA-256 hash of the token
+	UserID       string
+	FamilyID     string
+	TokenVersion int // The user's token version when the token was issued
+	ExpiresAt    time.Time
+	RevokedAt    *time.Time
+}
+
+// TokenPair represents the tokens returned by logging in or refreshing
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds until the access token expires
+	RefreshToken string `json:"refresh_token"`
+	// PasswordChangeRequired means the tokens only allow changing the password and logging out
+	PasswordChangeRequired bool `json:"password_change_required,omitempty"`
+}
+
+// JSONWebKey is a public key access tokens can be verified with, as defined by RFC 7517.
+// RSA keys set N and E; Ed25519 keys set Curve and X.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PasswordResetToken represents a stored one-time password reset token. Only a hash of the
+// token is kept.
+type PasswordResetToken struct {
+	ID        string // SHA-256 hash of the token
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// PasswordReset represents a password reset token issued by an admin, to be handed to the user
+type PasswordReset struct {
+	ResetToken string    `json:"reset_token"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Kinds of LoginAttempts: failed logins are counted per username and per client IP
+const (
+	LoginAttemptUsername = "username"
+	LoginAttemptIP       = "ip"
+)
+
+// LoginAttempts represents the recent failed logins of one username or client IP. Usernames
+// are tracked whether or not a user by that name exists.
+type LoginAttempts struct {
+	Kind          string     `json:"kind"` // LoginAttemptUsername or LoginAttemptIP
+	Value         string     `json:"value"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
 }
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type memoryBookingRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryBookingRepository) Get(ctx context.Context, id string) (models.Booking, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok {
+		return models.Booking{}, ErrNotFound
+	}
+	return booking, nil
+}
+
+func (r *memoryBookingRepository) Create(ctx context.Context, booking models.Booking, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.insert(booking, now)
+}
+
+func (r *memoryBookingRepository) CreateFromHold(ctx context.Context, holdID string, booking models.Booking, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.holds[holdID]; !ok {
+		return ErrNotFound
+	}
+	// Check the booking can be stored before releasing the hold, so a failure changes nothing
+	if err := r.checkInsert(booking, now); err != nil {
+		return err
+	}
+	r.store.deleteHold(holdID)
+	return r.insert(booking, now)
+}
+
+// checkInsert returns the error inserting booking would fail with: a seat is already booked,
+// or held by another user with a hold active at now.
+func (r *memoryBookingRepository) checkInsert(booking models.Booking, now time.Time) error {
+	if _, ok := r.store.bookings[booking.ID]; ok {
+		return ErrDuplicateID
+	}
+	for _, seat := range booking.Seats {
+		if _, taken := r.store.bookedSeats[booking.ShowID][seat.SeatID]; taken {
+			return &ErrSeatTaken{SeatID: seat.SeatID}
+		}
+		if holdID, held := r.store.heldSeats[booking.ShowID][seat.SeatID]; held {
+			hold := r.store.holds[holdID]
+			if hold.UserID != booking.UserID && hold.ExpiresAt.After(now) {
+				return &ErrSeatTaken{SeatID: seat.SeatID}
+			}
+		}
+	}
+	return nil
+}
+
+// insert stores a booking and claims its seats, releasing them from its user's own holds.
+func (r *memoryBookingRepository) insert(booking models.Booking, now time.Time) error {
+	if err := r.checkInsert(booking, now); err != nil {
+		return err
+	}
+	r.consumeOwnHolds(booking)
+	if r.store.bookedSeats[booking.ShowID] == nil {
+		r.store.bookedSeats[booking.ShowID] = make(map[string]string)
+	}
+	for _, seat := range booking.Seats {
+		r.store.bookedSeats[booking.ShowID][seat.SeatID] = booking.ID
+	}
+	// Like the SQL repository, keep the seat prices only as booked seats, not on the booking
+	booking.Seats = nil
+	r.store.bookings[booking.ID] = booking
+	return nil
+}
+
+// consumeOwnHolds releases the seats of booking from the holds of its user, deleting holds
+// left with no seats, as consumeOwnHoldsTx does.
+func (r *memoryBookingRepository) consumeOwnHolds(booking models.Booking) {
+	if booking.UserID == "" {
+		return
+	}
+	booked := make(map[string]bool)
+	for _, seat := range booking.Seats {
+		booked[seat.SeatID] = true
+	}
+
+	for _, id := range sortedKeys(r.store.holds) {
+		hold := r.store.holds[id]
+		if hold.ShowID != booking.ShowID || hold.UserID != booking.UserID {
+			continue
+		}
+		var kept []string
+		for _, seatID := range hold.SeatIDs {
+			if booked[seatID] {
+				delete(r.store.heldSeats[hold.ShowID], seatID)
+			} else {
+				kept = append(kept, seatID)
+			}
+		}
+		if len(kept) == 0 {
+			delete(r.store.holds, id)
+			continue
+		}
+		hold.SeatIDs = kept
+		r.store.holds[id] = hold
+	}
+}
+
+func (r *memoryBookingRepository) ListByShow(ctx context.Context, showID string) ([]models.Booking, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var bookings []models.Booking
+	for _, id := range sortedKeys(r.store.bookings) {
+		if booking := r.store.bookings[id]; booking.ShowID == showID {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) ListDetailsByUser(ctx context.Context, userID string) ([]models.BookingDetails, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	bookings := []models.BookingDetails{}
+	for _, id := range sortedKeys(r.store.bookings) {
+		booking := r.store.bookings[id]
+		show, ok := r.store.shows[booking.ShowID]
+		if booking.UserID != userID || !ok {
+			continue
+		}
+		hall := r.store.halls[show.HallID]
+		bookings = append(bookings, models.BookingDetails{
+			Booking:     booking,
+			ShowTime:    show.Time,
+			MovieID:     show.MovieID,
+			MovieTitle:  r.store.movies[show.MovieID].Title,
+			HallID:      show.HallID,
+			HallName:    hall.Name,
+			TheatreID:   hall.TheatreID,
+			TheatreName: r.store.theatres[hall.TheatreID].Name,
+		})
+	}
+	sort.SliceStable(bookings, func(i, j int) bool { return bookings[i].ShowTime > bookings[j].ShowTime })
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) BookedSeatIDs(ctx context.Context, showID string) (map[string]bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	seatIDs := make(map[string]bool)
+	for seatID := range r.store.bookedSeats[showID] {
+		seatIDs[seatID] = true
+	}
+	return seatIDs, nil
+}
+
+func (r *memoryBookingRepository) CountByShow(ctx context.Context, showID string, status string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	count := 0
+	for _, booking := range r.store.bookings {
+		if booking.ShowID == showID && (status == "" || booking.Status == status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryBookingRepository) Cancel(ctx context.Context, id string, cancelledAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok || booking.Status != models.BookingStatusConfirmed {
+		return ErrNotFound
+	}
+	booking.Status = models.BookingStatusCancelled
+	booking.CancelledAt = timeRef(cancelledAt)
+	r.store.bookings[id] = booking
+
+	for seatID, bookingID := range r.store.bookedSeats[booking.ShowID] {
+		if bookingID == id {
+			delete(r.store.bookedSeats[booking.ShowID], seatID)
+		}
+	}
+	return nil
+}
+
+func (r *memoryBookingRepository) MovieRevenue(ctx context.Context, movieID string, theatreIDs []string) (float64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	inScope := func(show models.Show) bool {
+		if theatreIDs == nil {
+			return true
+		}
+		theatreID := r.store.halls[show.HallID].TheatreID
+		for _, id := range theatreIDs {
+			if id == theatreID {
+				return true
+			}
+		}
+		return false
+	}
+
+	var totalRevenue float64
+	for _, booking := range r.store.bookings {
+		show := r.store.shows[booking.ShowID]
+		if show.MovieID == movieID && booking.Status == models.BookingStatusConfirmed && inScope(show) {
+			totalRevenue += bookingRevenue(booking, show)
+		}
+	}
+	return totalRevenue, nil
+}
+
+// bookingRevenue returns what a booking of show was charged. A booking without a total stands
+// in for one made before per-seat pricing, which the SQL repository prices at its unit price,
+// or else the show price, per seat.
+func bookingRevenue(booking models.Booking, show models.Show) float64 {
+	if booking.TotalPrice != 0 || len(booking.SeatIDs) == 0 {
+		return booking.TotalPrice
+	}
+	unitPrice := booking.UnitPrice
+	if unitPrice == 0 {
+		unitPrice = show.Price
+	}
+	return unitPrice * float64(len(booking.SeatIDs))
+}
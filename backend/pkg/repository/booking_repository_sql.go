@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type sqlBookingRepository struct {
+	db *sql.DB
+}
+
+// bookingColumns lists the bookings columns read by scanBooking, in order.
+const bookingColumns = "id, show_id, user_id, seat_ids, status, cancelled_at, unit_price, total_price, show_changed_at, notice"
+
+// scanBooking reads a booking selected with bookingColumns, followed by any extra columns.
+func scanBooking(row rowScanner, extra ...interface{}) (models.Booking, error) {
+	var booking models.Booking
+	var userID, notice sql.NullString
+	var seatIDsStr string
+	var unitPrice, totalPrice sql.NullFloat64
+	var cancelledAt, showChangedAt sql.NullTime
+	dest := []interface{}{&booking.ID, &booking.ShowID, &userID, &seatIDsStr, &booking.Status, &cancelledAt, &unitPrice, &totalPrice, &showChangedAt, &notice}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Booking{}, err
+	}
+	booking.UserID = userID.String
+	booking.UnitPrice = unitPrice.Float64
+	booking.TotalPrice = totalPrice.Float64
+	booking.Notice = notice.String
+	booking.CancelledAt = timePtr(cancelledAt)
+	booking.ShowChangedAt = timePtr(showChangedAt)
+
+	if err := json.Unmarshal([]byte(seatIDsStr), &booking.SeatIDs); err != nil {
+		return models.Booking{}, err
+	}
+	// Bookings made before per-seat pricing only recorded the show price
+	if !totalPrice.Valid {
+		booking.TotalPrice = booking.UnitPrice * float64(len(booking.SeatIDs))
+	}
+	return booking, nil
+}
+
+func (r *sqlBookingRepository) Get(ctx context.Context, id string) (models.Booking, error) {
+	booking, err := scanBooking(r.db.QueryRowContext(ctx, "SELECT "+bookingColumns+" FROM bookings WHERE id = ?", id))
+	return booking, notFound(err)
+}
+
+func (r *sqlBookingRepository) Create(ctx context.Context, booking models.Booking, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertBookingTx(ctx, tx, booking, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlBookingRepository) CreateFromHold(ctx context.Context, holdID string, booking models.Booking, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Release the hold first so the seats move from held_seats to booked_seats in one transaction
+	if err := deleteHoldTx(ctx, tx, holdID); err != nil {
+		return err
+	}
+
+	if err := insertBookingTx(ctx, tx, booking, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertBookingTx writes a booking and claims its seats in booked_seats within tx, recording
+// each seat's category and price. The booked_seats primary key guarantees a seat cannot be
+// booked twice for the same show, and each seat is only inserted while no hold active at now
+// claims it. Holds of the booking's own user give up the seats it books first.
+func insertBookingTx(ctx context.Context, tx *sql.Tx, booking models.Booking, now time.Time) error {
+	seatIDsBytes, _ := json.Marshal(booking.SeatIDs)
+
+	if err := consumeOwnHoldsTx(ctx, tx, booking); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "INSERT INTO bookings(id, show_id, seat_ids, user_id, status, unit_price, total_price) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		booking.ID, booking.ShowID, string(seatIDsBytes), booking.UserID, booking.Status, booking.UnitPrice, booking.TotalPrice)
+	if err != nil {
+		return insertError(err)
+	}
+
+	// Checking held_seats in the insert itself makes the check and the claim one statement;
+	// a concurrent hold on the same seat either commits first and is seen here, or sees this
+	// seat when it checks booked_seats in turn
+	stmtSeat, err := tx.PrepareContext(ctx, `
+		INSERT INTO booked_seats(show_id, seat_id, booking_id, category, price)
+		SELECT ?, ?, ?, ?, ? FROM (SELECT 1) AS one
+		WHERE NOT EXISTS (
+			SELECT 1 FROM held_seats hs JOIN seat_holds h ON h.id = hs.hold_id
+			WHERE hs.show_id = ? AND hs.seat_id = ? AND h.expires_at > ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmtSeat.Close()
+
+	for _, seat := range booking.Seats {
+		err := requireAffected(stmtSeat.ExecContext(ctx, booking.ShowID, seat.SeatID, booking.ID, seat.Category, seat.Price,
+			booking.ShowID, seat.SeatID, now))
+		if err == ErrNotFound || (err != nil && isDuplicateKeyError(err)) {
+			return &ErrSeatTaken{SeatID: seat.SeatID}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// consumeOwnHoldsTx releases the seats of a booking from the holds of its user within tx,
+// deleting holds left with no seats, so a customer booking seats they hold uses the hold up
+// instead of leaving it to be confirmed again.
+func consumeOwnHoldsTx(ctx context.Context, tx *sql.Tx, booking models.Booking) error {
+	if booking.UserID == "" || len(booking.Seats) == 0 {
+		return nil
+	}
+
+	args := []interface{}{booking.ShowID, booking.UserID}
+	for _, seat := range booking.Seats {
+		args = append(args, seat.SeatID)
+	}
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM held_seats
+		WHERE hold_id IN (SELECT id FROM seat_holds WHERE show_id = ? AND user_id = ?)
+		AND seat_id IN (?`+strings.Repeat(", ?", len(booking.Seats)-1)+`)`, args...)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM seat_holds
+		WHERE show_id = ? AND user_id = ?
+		AND NOT EXISTS (SELECT 1 FROM held_seats hs WHERE hs.hold_id = seat_holds.id)`,
+		booking.ShowID, booking.UserID)
+	return err
+}
+
+func (r *sqlBookingRepository) ListByShow(ctx context.Context, showID string) ([]models.Booking, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+bookingColumns+" FROM bookings WHERE show_id = ?", showID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []models.Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, nil
+}
+
+func (r *sqlBookingRepository) ListDetailsByUser(ctx context.Context, userID string) ([]models.BookingDetails, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+prefixColumns("b", bookingColumns)+`,
+			s.time, s.movie_id, m.title, s.hall_id, h.name, h.theatre_id, t.name
+		FROM bookings b
+		JOIN shows s ON s.id = b.show_id
+		LEFT JOIN movies m ON m.id = s.movie_id
+		LEFT JOIN halls h ON h.id = s.hall_id
+		LEFT JOIN theatres t ON t.id = h.theatre_id
+		WHERE b.user_id = ?
+		ORDER BY s.time DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []models.BookingDetails{}
+	for rows.Next() {
+		var details models.BookingDetails
+		var movieTitle, hallName, theatreID, theatreName sql.NullString
+		booking, err := scanBooking(rows,
+			&details.ShowTime, &details.MovieID, &movieTitle, &details.HallID, &hallName, &theatreID, &theatreName)
+		if err != nil {
+			return nil, err
+		}
+		details.Booking = booking
+		details.MovieTitle = movieTitle.String
+		details.HallName = hallName.String
+		details.TheatreID = theatreID.String
+		details.TheatreName = theatreName.String
+		bookings = append(bookings, details)
+	}
+	return bookings, rows.Err()
+}
+
+func (r *sqlBookingRepository) BookedSeatIDs(ctx context.Context, showID string) (map[string]bool, error) {
+	return scanSeatIDs(r.db.QueryContext(ctx, "SELECT seat_id FROM booked_seats WHERE show_id = ?", showID))
+}
+
+// scanSeatIDs collects the seat IDs selected by a query as a set.
+func scanSeatIDs(rows *sql.Rows, err error) (map[string]bool, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seatIDs := make(map[string]bool)
+	for rows.Next() {
+		var seatID string
+		if err := rows.Scan(&seatID); err != nil {
+			return nil, err
+		}
+		seatIDs[seatID] = true
+	}
+	return seatIDs, rows.Err()
+}
+
+func (r *sqlBookingRepository) CountByShow(ctx context.Context, showID string, status string) (int, error) {
+	query := "SELECT COUNT(*) FROM bookings WHERE show_id = ?"
+	args := []interface{}{showID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (r *sqlBookingRepository) Cancel(ctx context.Context, id string, cancelledAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = requireAffected(tx.ExecContext(ctx, "UPDATE bookings SET status = ?, cancelled_at = ? WHERE id = ? AND status = ?",
+		models.BookingStatusCancelled, cancelledAt, id, models.BookingStatusConfirmed))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM booked_seats WHERE booking_id = ?", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlBookingRepository) MovieRevenue(ctx context.Context, movieID string, theatreIDs []string) (float64, error) {
+	// Bookings record the sum of their per-category seat prices. Older rows without one fall
+	// back to their seat count times the price they were made at, or else the show price; seat
+	// IDs never contain commas, so counting the commas of seat_ids counts its seats on every
+	// driver. Archived shows are deliberately not filtered out.
+	query := `
+		SELECT COALESCE(SUM(CASE
+			WHEN b.total_price IS NOT NULL THEN b.total_price
+			WHEN b.seat_ids IS NULL OR b.seat_ids IN ('', '[]') THEN 0
+			ELSE COALESCE(b.unit_price, s.price, 0) * (LENGTH(b.seat_ids) - LENGTH(REPLACE(b.seat_ids, ',', '')) + 1)
+		END), 0)
+		FROM bookings b
+		JOIN shows s ON s.id = b.show_id`
+	args := []interface{}{movieID, models.BookingStatusConfirmed}
+	where := " WHERE s.movie_id = ? AND b.status = ?"
+	if theatreIDs != nil {
+		if len(theatreIDs) == 0 {
+			return 0, nil
+		}
+		query += " JOIN halls h ON h.id = s.hall_id"
+		where += " AND h.theatre_id IN (?" + strings.Repeat(", ?", len(theatreIDs)-1) + ")"
+		for _, id := range theatreIDs {
+			args = append(args, id)
+		}
+	}
+
+	var totalRevenue float64
+	err := r.db.QueryRowContext(ctx, query+where, args...).Scan(&totalRevenue)
+	return totalRevenue, err
+}
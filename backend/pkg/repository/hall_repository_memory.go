@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"time"
+)
+
+type memoryHallRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryHallRepository) List(ctx context.Context, theatreID string, includeArchived bool) ([]models.Hall, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var halls []models.Hall
+	for _, id := range r.store.hallIDsWhere(func(hall models.Hall) bool {
+		return (theatreID == "" || hall.TheatreID == theatreID) && (includeArchived || hall.ArchivedAt == nil)
+	}) {
+		halls = append(halls, r.store.halls[id])
+	}
+	return halls, nil
+}
+
+func (r *memoryHallRepository) Get(ctx context.Context, id string) (models.Hall, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	hall, ok := r.store.halls[id]
+	if !ok {
+		return models.Hall{}, ErrNotFound
+	}
+	return hall, nil
+}
+
+func (r *memoryHallRepository) Create(ctx context.Context, hall models.Hall, seats []models.Seat) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.halls[hall.ID]; ok {
+		return ErrDuplicateID
+	}
+	hall.ArchivedAt = nil
+	r.store.halls[hall.ID] = hall
+	r.store.seats[hall.ID] = append([]models.Seat(nil), seats...)
+	return nil
+}
+
+func (r *memoryHallRepository) Update(ctx context.Context, hall models.Hall, seats []models.Seat) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.halls[hall.ID]
+	if !ok {
+		return nil
+	}
+	hall.ArchivedAt = existing.ArchivedAt
+	r.store.halls[hall.ID] = hall
+	r.store.seats[hall.ID] = append([]models.Seat(nil), seats...)
+	return nil
+}
+
+func (r *memoryHallRepository) Seats(ctx context.Context, hallID string) ([]models.Seat, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return append([]models.Seat(nil), r.store.seats[hallID]...), nil
+}
+
+func (r *memoryHallRepository) CountDeletion(ctx context.Context, id string) (models.DeletionSummary, error) {
+	return r.delete(id, true, nil)
+}
+
+func (r *memoryHallRepository) Delete(ctx context.Context, id string, guard Guard) (models.DeletionSummary, error) {
+	return r.delete(id, false, guard)
+}
+
+func (r *memoryHallRepository) delete(id string, dryRun bool, guard Guard) (models.DeletionSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.halls[id]; !ok {
+		return models.DeletionSummary{DryRun: dryRun}, ErrNotFound
+	}
+	return r.store.deleteHalls([]string{id}, dryRun, guard)
+}
+
+func (r *memoryHallRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	hall, ok := r.store.halls[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if hall.ArchivedAt != nil {
+		return nil
+	}
+
+	showIDs := r.store.showIDsWhere(func(show models.Show) bool { return show.HallID == id })
+	if err := r.store.archiveShows(showIDs, archivedAt, guard); err != nil {
+		return err
+	}
+	hall.ArchivedAt = timeRef(archivedAt)
+	r.store.halls[id] = hall
+	return nil
+}
+
+func (r *memoryHallRepository) Restore(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	hall, ok := r.store.halls[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if hall.ArchivedAt == nil {
+		return nil
+	}
+	if r.store.theatres[hall.TheatreID].ArchivedAt != nil {
+		return &ErrParentArchived{Entity: "theatre"}
+	}
+
+	showIDs := r.store.showIDsWhere(func(show models.Show) bool { return show.HallID == id })
+	r.store.restoreShows(showIDs, *hall.ArchivedAt)
+	hall.ArchivedAt = nil
+	r.store.halls[id] = hall
+	return nil
+}
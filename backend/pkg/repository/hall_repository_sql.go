@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type sqlHallRepository struct {
+	db *sql.DB
+}
+
+const hallColumns = "id, name, theatre_id, seat_map, missing_seats, seat_categories, archived_at"
+
+func (r *sqlHallRepository) List(ctx context.Context, theatreID string, includeArchived bool) ([]models.Hall, error) {
+	query := "SELECT " + hallColumns + " FROM halls"
+	var where []string
+	args := []interface{}{} // Use interface{} for dynamic arguments
+
+	if theatreID != "" {
+		where = append(where, "theatre_id = ?")
+		args = append(args, theatreID)
+	}
+	if !includeArchived {
+		where = append(where, "archived_at IS NULL")
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var halls []models.Hall
+	for rows.Next() {
+		hall, err := scanHall(rows)
+		if err != nil {
+			log.Printf("Error reading hall: %v", err)
+			continue
+		}
+		halls = append(halls, hall)
+	}
+
+	return halls, nil
+}
+
+func (r *sqlHallRepository) Get(ctx context.Context, id string) (models.Hall, error) {
+	hall, err := scanHall(r.db.QueryRowContext(ctx, "SELECT "+hallColumns+" FROM halls WHERE id = ?", id))
+	return hall, notFound(err)
+}
+
+// scanHall reads a hall selected with hallColumns.
+func scanHall(row rowScanner) (models.Hall, error) {
+	var hall models.Hall
+	var seatMapStr string
+	var missingSeatsStr, seatCategoriesStr sql.NullString
+	var archivedAt sql.NullTime
+	if err := row.Scan(&hall.ID, &hall.Name, &hall.TheatreID, &seatMapStr, &missingSeatsStr, &seatCategoriesStr, &archivedAt); err != nil {
+		return models.Hall{}, err
+	}
+	hall.ArchivedAt = timePtr(archivedAt)
+	if err := json.Unmarshal([]byte(seatMapStr), &hall.SeatMap); err != nil {
+		return models.Hall{}, fmt.Errorf("error unmarshaling seat map for hall %s: %w", hall.ID, err)
+	}
+	if missingSeatsStr.Valid && missingSeatsStr.String != "" {
+		if err := json.Unmarshal([]byte(missingSeatsStr.String), &hall.MissingSeats); err != nil {
+			return models.Hall{}, fmt.Errorf("error unmarshaling missing seats for hall %s: %w", hall.ID, err)
+		}
+	}
+	if seatCategoriesStr.Valid && seatCategoriesStr.String != "" {
+		if err := json.Unmarshal([]byte(seatCategoriesStr.String), &hall.SeatCategories); err != nil {
+			return models.Hall{}, fmt.Errorf("error unmarshaling seat categories for hall %s: %w", hall.ID, err)
+		}
+	}
+
+	return hall, nil
+}
+
+// marshalHallLayout encodes a hall's seat map, missing seats and seat categories for their columns.
+func marshalHallLayout(hall models.Hall) (seatMap, missingSeats, seatCategories string) {
+	seatMapBytes, _ := json.Marshal(hall.SeatMap)
+	missingSeatsBytes, _ := json.Marshal(hall.MissingSeats)
+	seatCategoriesBytes, _ := json.Marshal(hall.SeatCategories)
+	return string(seatMapBytes), string(missingSeatsBytes), string(seatCategoriesBytes)
+}
+
+func (r *sqlHallRepository) Create(ctx context.Context, hall models.Hall, seats []models.Seat) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seatMap, missingSeats, seatCategories := marshalHallLayout(hall)
+	_, err = tx.ExecContext(ctx, "INSERT INTO halls(id, name, theatre_id, seat_map, missing_seats, seat_categories) VALUES(?, ?, ?, ?, ?, ?)",
+		hall.ID, hall.Name, hall.TheatreID, seatMap, missingSeats, seatCategories)
+	if err != nil {
+		return insertError(err)
+	}
+
+	if err := insertSeatsTx(ctx, tx, seats); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlHallRepository) Update(ctx context.Context, hall models.Hall, seats []models.Seat) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seatMap, missingSeats, seatCategories := marshalHallLayout(hall)
+	_, err = tx.ExecContext(ctx, "UPDATE halls SET name = ?, theatre_id = ?, seat_map = ?, missing_seats = ?, seat_categories = ? WHERE id = ?",
+		hall.Name, hall.TheatreID, seatMap, missingSeats, seatCategories, hall.ID)
+	if err != nil {
+		return err
+	}
+
+	// Delete existing seats and create new ones
+	if _, err := tx.ExecContext(ctx, "DELETE FROM seats WHERE hall_id = ?", hall.ID); err != nil {
+		return err
+	}
+	if err := insertSeatsTx(ctx, tx, seats); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertSeatsTx creates a seats row for every seat.
+func insertSeatsTx(ctx context.Context, tx *sql.Tx, seats []models.Seat) error {
+	seatStmt, err := tx.PrepareContext(ctx, "INSERT INTO seats(id, `row`, `number`, hall_id, `column`) VALUES(?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer seatStmt.Close()
+
+	for _, seat := range seats {
+		if _, err := seatStmt.ExecContext(ctx, seat.ID, seat.Row, seat.Number, seat.HallID, seat.Column); err != nil {
+			log.Printf("Error inserting seat %s: %v", seat.ID, err)
+			return insertError(err)
+		}
+	}
+
+	return nil
+}
+
+func (r *sqlHallRepository) Seats(ctx context.Context, hallID string) ([]models.Seat, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, `row`, `number`, hall_id, `column` FROM seats WHERE hall_id = ?", hallID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []models.Seat
+	for rows.Next() {
+		var seat models.Seat
+		if err := rows.Scan(&seat.ID, &seat.Row, &seat.Number, &seat.HallID, &seat.Column); err != nil {
+			continue
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, nil
+}
+
+func (r *sqlHallRepository) CountDeletion(ctx context.Context, id string) (models.DeletionSummary, error) {
+	return deleteHallsCascade(ctx, r.db, "halls", "id = ?", id, true, nil)
+}
+
+func (r *sqlHallRepository) Delete(ctx context.Context, id string, guard Guard) (models.DeletionSummary, error) {
+	return deleteHallsCascade(ctx, r.db, "halls", "id = ?", id, false, guard)
+}
+
+func (r *sqlHallRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	return archiveRecord(ctx, r.db, archivableHalls, id, archivedAt, guard)
+}
+
+func (r *sqlHallRepository) Restore(ctx context.Context, id string) error {
+	return restoreRecord(ctx, r.db, archivableHalls, id)
+}
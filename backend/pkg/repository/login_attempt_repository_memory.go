@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type memoryLoginAttemptRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryLoginAttemptRepository) Get(ctx context.Context, kind string, value string) (models.LoginAttempts, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	attempts, ok := r.store.loginAttempts[[2]string{kind, value}]
+	if !ok {
+		return models.LoginAttempts{}, ErrNotFound
+	}
+	return attempts, nil
+}
+
+func (r *memoryLoginAttemptRepository) RecordFailure(ctx context.Context, kind string, value string, now time.Time, resetBefore time.Time) (models.LoginAttempts, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := [2]string{kind, value}
+	attempts, ok := r.store.loginAttempts[key]
+	if !ok {
+		attempts = models.LoginAttempts{Kind: kind, Value: value}
+	}
+	if attempts.LastFailureAt.Before(resetBefore) {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	r.store.loginAttempts[key] = attempts
+	return attempts, nil
+}
+
+func (r *memoryLoginAttemptRepository) Lock(ctx context.Context, kind string, value string, until time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := [2]string{kind, value}
+	attempts, ok := r.store.loginAttempts[key]
+	if !ok {
+		return nil
+	}
+	attempts.LockedUntil = &until
+	r.store.loginAttempts[key] = attempts
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) Clear(ctx context.Context, kind string, value string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := [2]string{kind, value}
+	if _, ok := r.store.loginAttempts[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.store.loginAttempts, key)
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) ListLocked(ctx context.Context, now time.Time) ([]models.LoginAttempts, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var locked []models.LoginAttempts
+	for _, attempts := range r.store.loginAttempts {
+		if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+			locked = append(locked, attempts)
+		}
+	}
+	sort.Slice(locked, func(i, j int) bool { return locked[i].LockedUntil.Before(*locked[j].LockedUntil) })
+	return locked, nil
+}
+
+func (r *memoryLoginAttemptRepository) DeleteStale(ctx context.Context, before time.Time, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for key, attempts := range r.store.loginAttempts {
+		locked := attempts.LockedUntil != nil && attempts.LockedUntil.After(now)
+		if attempts.LastFailureAt.Before(before) && !locked {
+			delete(r.store.loginAttempts, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func (r *sqlLoginAttemptRepository) Get(ctx context.Context, kind string, value string) (models.LoginAttempts, error) {
+	return scanLoginAttempts(r.db.QueryRowContext(ctx, "SELECT kind, value, failures, last_failure_at, locked_until FROM login_attempts WHERE kind = ? AND value = ?", kind, value))
+}
+
+// scanLoginAttempts reads attempts selected with their kind, value, failures, last failure and lockout.
+func scanLoginAttempts(row rowScanner) (models.LoginAttempts, error) {
+	var attempts models.LoginAttempts
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&attempts.Kind, &attempts.Value, &attempts.Failures, &attempts.LastFailureAt, &lockedUntil); err != nil {
+		return models.LoginAttempts{}, notFound(err)
+	}
+	attempts.LastFailureAt = attempts.LastFailureAt.UTC()
+	attempts.LockedUntil = timePtr(lockedUntil)
+	return attempts, nil
+}
+
+func (r *sqlLoginAttemptRepository) RecordFailure(ctx context.Context, kind string, value string, now time.Time, resetBefore time.Time) (models.LoginAttempts, error) {
+	// The count is updated in place, so concurrent failures are all counted
+	update := func() error {
+		return requireAffected(r.db.ExecContext(ctx, `UPDATE login_attempts
+			SET failures = CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END, last_failure_at = ?
+			WHERE kind = ? AND value = ?`, resetBefore, now, kind, value))
+	}
+	err := update()
+	if err == ErrNotFound {
+		_, err = r.db.ExecContext(ctx, "INSERT INTO login_attempts(kind, value, failures, last_failure_at) VALUES(?, ?, 1, ?)", kind, value, now)
+		if isDuplicateKeyError(err) {
+			// A concurrent failure inserted the row first
+			err = update()
+		}
+	}
+	if err != nil {
+		return models.LoginAttempts{}, err
+	}
+	return r.Get(ctx, kind, value)
+}
+
+func (r *sqlLoginAttemptRepository) Lock(ctx context.Context, kind string, value string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE login_attempts SET locked_until = ? WHERE kind = ? AND value = ?", until, kind, value)
+	return err
+}
+
+func (r *sqlLoginAttemptRepository) Clear(ctx context.Context, kind string, value string) error {
+	return requireAffected(r.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE kind = ? AND value = ?", kind, value))
+}
+
+func (r *sqlLoginAttemptRepository) ListLocked(ctx context.Context, now time.Time) ([]models.LoginAttempts, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT kind, value, failures, last_failure_at, locked_until FROM login_attempts WHERE locked_until > ? ORDER BY locked_until", now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locked []models.LoginAttempts
+	for rows.Next() {
+		attempts, err := scanLoginAttempts(rows)
+		if err != nil {
+			return nil, err
+		}
+		locked = append(locked, attempts)
+	}
+	return locked, rows.Err()
+}
+
+func (r *sqlLoginAttemptRepository) DeleteStale(ctx context.Context, before time.Time, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until <= ?)", before, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewMemoryRepositories returns repositories that keep everything in memory, for tests. They
+// share one store guarded by one mutex, so every change is atomic just like a transaction.
+// Records are stored as given; callers must not modify maps or slices they passed in.
+func NewMemoryRepositories() Repositories {
+	store := &memoryStore{
+		movies:         make(map[string]models.Movie),
+		theatres:       make(map[string]models.Theatre),
+		halls:          make(map[string]models.Hall),
+		seats:          make(map[string][]models.Seat),
+		shows:          make(map[string]models.Show),
+		bookings:       make(map[string]models.Booking),
+		bookedSeats:    make(map[string]map[string]string),
+		holds:          make(map[string]models.SeatHold),
+		heldSeats:      make(map[string]map[string]string),
+		users:          make(map[string]models.User),
+		theatreMembers: make(map[string][]string),
+		refreshTokens:  make(map[string]models.RefreshToken),
+		revokedTokens:  make(map[string]time.Time),
+		passwordResets: make(map[string]models.PasswordResetToken),
+		loginAttempts:  make(map[[2]string]models.LoginAttempts),
+	}
+	return Repositories{
+		Movies:    &memoryMovieRepository{store},
+		Theatres:  &memoryTheatreRepository{store},
+		Halls:     &memoryHallRepository{store},
+		Shows:     &memoryShowRepository{store},
+		Bookings:  &memoryBookingRepository{store},
+		SeatHolds: &memorySeatHoldRepository{store},
+		Users:     &memoryUserRepository{store},
+		Tokens:    &memoryTokenRepository{store},
+		Logins:    &memoryLoginAttemptRepository{store},
+	}
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	movies   map[string]models.Movie
+	theatres map[string]models.Theatre
+	halls    map[string]models.Hall
+	seats    map[string][]models.Seat // Hall ID -> seats
+	shows    map[string]models.Show
+	bookings map[string]models.Booking
+	// bookedSeats and heldSeats map a show ID and seat ID to the booking or hold claiming the seat
+	bookedSeats map[string]map[string]string
+	holds       map[string]models.SeatHold
+	heldSeats   map[string]map[string]string
+	users       map[string]models.User
+	// theatreMembers maps a user ID to the sorted IDs of the theatres they are a member of
+	theatreMembers map[string][]string
+	// refreshTokens maps a token hash to the refresh token; revokedTokens maps a revoked
+	// access token ID to its expiry
+	refreshTokens map[string]models.RefreshToken
+	revokedTokens map[string]time.Time
+	// passwordResets maps a token hash to the password reset token
+	passwordResets map[string]models.PasswordResetToken
+	// loginAttempts maps a kind and value to the failed logins of that username or IP
+	loginAttempts map[[2]string]models.LoginAttempts
+}
+
+// sortedKeys returns the keys of a record map in ascending order. IDs are time-sortable, so
+// this lists records in the order they were created.
+func sortedKeys[V any](records map[string]V) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// showIDsWhere returns the IDs of the shows matching match.
+func (s *memoryStore) showIDsWhere(match func(show models.Show) bool) []string {
+	var showIDs []string
+	for _, id := range sortedKeys(s.shows) {
+		if match(s.shows[id]) {
+			showIDs = append(showIDs, id)
+		}
+	}
+	return showIDs
+}
+
+// hallIDsWhere returns the IDs of the halls matching match.
+func (s *memoryStore) hallIDsWhere(match func(hall models.Hall) bool) []string {
+	var hallIDs []string
+	for _, id := range sortedKeys(s.halls) {
+		if match(s.halls[id]) {
+			hallIDs = append(hallIDs, id)
+		}
+	}
+	return hallIDs
+}
+
+// activeBookings counts the scheduled shows among showIDs that have not started yet and have
+// confirmed bookings, and those bookings.
+func (s *memoryStore) activeBookings(showIDs []string) models.DeletionSummary {
+	var summary models.DeletionSummary
+	now := time.Now().UTC()
+	for _, showID := range showIDs {
+		show := s.shows[showID]
+		if !showInstant(show.Time).After(now) || show.Status != models.ShowStatusScheduled {
+			continue
+		}
+		confirmed := 0
+		for _, booking := range s.bookings {
+			if booking.ShowID == showID && booking.Status == models.BookingStatusConfirmed {
+				confirmed++
+			}
+		}
+		if confirmed > 0 {
+			summary.UpcomingShows++
+			summary.ActiveBookings += confirmed
+		}
+	}
+	return summary
+}
+
+// deleteHolds removes every hold on a show and frees its seats.
+func (s *memoryStore) deleteHolds(showID string) {
+	for id, hold := range s.holds {
+		if hold.ShowID == showID {
+			delete(s.holds, id)
+		}
+	}
+	delete(s.heldSeats, showID)
+}
+
+// deleteHold removes a hold and frees its seats.
+func (s *memoryStore) deleteHold(holdID string) {
+	hold := s.holds[holdID]
+	for _, seatID := range hold.SeatIDs {
+		if s.heldSeats[hold.ShowID][seatID] == holdID {
+			delete(s.heldSeats[hold.ShowID], seatID)
+		}
+	}
+	delete(s.holds, holdID)
+}
+
+// flagBookings marks the confirmed bookings of a show as affected by a show change.
+func (s *memoryStore) flagBookings(showID string, notice string) {
+	now := time.Now().UTC()
+	for id, booking := range s.bookings {
+		if booking.ShowID == showID && booking.Status == models.BookingStatusConfirmed {
+			booking.ShowChangedAt = &now
+			booking.Notice = notice
+			s.bookings[id] = booking
+		}
+	}
+}
+
+// archiveShows archives the shows among showIDs that are not archived yet and releases the
+// seat holds on all of them, after guard has seen their active bookings.
+func (s *memoryStore) archiveShows(showIDs []string, archivedAt time.Time, guard Guard) error {
+	var unarchived []string
+	for _, showID := range showIDs {
+		if s.shows[showID].ArchivedAt == nil {
+			unarchived = append(unarchived, showID)
+		}
+	}
+	if guard != nil {
+		if err := guard(s.activeBookings(unarchived)); err != nil {
+			return err
+		}
+	}
+
+	for _, showID := range showIDs {
+		s.deleteHolds(showID)
+	}
+	for _, showID := range unarchived {
+		show := s.shows[showID]
+		show.ArchivedAt = timeRef(archivedAt)
+		s.shows[showID] = show
+	}
+	return nil
+}
+
+// restoreShows restores the shows among showIDs that were archived at archivedAt.
+func (s *memoryStore) restoreShows(showIDs []string, archivedAt time.Time) {
+	for _, showID := range showIDs {
+		show := s.shows[showID]
+		if show.ArchivedAt != nil && show.ArchivedAt.Equal(archivedAt) {
+			show.ArchivedAt = nil
+			s.shows[showID] = show
+		}
+	}
+}
+
+// deleteHalls deletes halls with their seats, shows, seat holds, bookings and booked seats,
+// unless guard stops it. A dry run only counts.
+func (s *memoryStore) deleteHalls(hallIDs []string, dryRun bool, guard Guard) (models.DeletionSummary, error) {
+	inHalls := make(map[string]bool)
+	for _, hallID := range hallIDs {
+		inHalls[hallID] = true
+	}
+	showIDs := s.showIDsWhere(func(show models.Show) bool { return inHalls[show.HallID] })
+	inShows := make(map[string]bool)
+	for _, showID := range showIDs {
+		inShows[showID] = true
+	}
+
+	summary := s.activeBookings(showIDs)
+	summary.DryRun = dryRun
+	summary.Halls = len(hallIDs)
+	summary.Shows = len(showIDs)
+	for _, booking := range s.bookings {
+		if inShows[booking.ShowID] {
+			summary.Bookings++
+		}
+	}
+
+	if dryRun {
+		return summary, nil
+	}
+	if guard != nil {
+		if err := guard(summary); err != nil {
+			return summary, err
+		}
+	}
+
+	for id, booking := range s.bookings {
+		if inShows[booking.ShowID] {
+			delete(s.bookings, id)
+		}
+	}
+	for _, showID := range showIDs {
+		delete(s.bookedSeats, showID)
+		s.deleteHolds(showID)
+		delete(s.shows, showID)
+	}
+	for _, hallID := range hallIDs {
+		delete(s.seats, hallID)
+		delete(s.halls, hallID)
+	}
+	return summary, nil
+}
+
+// showInstant parses an RFC3339 show time, so that times written with different offsets
+// compare as the instants they name. Unparseable times sort first, as the zero time.
+func showInstant(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// timeRef returns a pointer to a copy of t in UTC.
+func timeRef(t time.Time) *time.Time {
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"time"
+)
+
+type memoryMovieRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryMovieRepository) List(ctx context.Context, includeArchived bool) ([]models.Movie, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var movies []models.Movie
+	for _, id := range sortedKeys(r.store.movies) {
+		if movie := r.store.movies[id]; includeArchived || movie.ArchivedAt == nil {
+			movies = append(movies, movie)
+		}
+	}
+	return movies, nil
+}
+
+func (r *memoryMovieRepository) Get(ctx context.Context, id string) (models.Movie, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	movie, ok := r.store.movies[id]
+	if !ok {
+		return models.Movie{}, ErrNotFound
+	}
+	return movie, nil
+}
+
+func (r *memoryMovieRepository) Create(ctx context.Context, movie models.Movie) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.movies[movie.ID]; ok {
+		return ErrDuplicateID
+	}
+	movie.ArchivedAt = nil
+	r.store.movies[movie.ID] = movie
+	return nil
+}
+
+func (r *memoryMovieRepository) Update(ctx context.Context, movie models.Movie) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.movies[movie.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Title = movie.Title
+	existing.DurationMinutes = movie.DurationMinutes
+	r.store.movies[movie.ID] = existing
+	return nil
+}
+
+func (r *memoryMovieRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.movies[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.store.movies, id)
+	return nil
+}
+
+func (r *memoryMovieRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	movie, ok := r.store.movies[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if movie.ArchivedAt != nil {
+		return nil
+	}
+
+	showIDs := r.store.showIDsWhere(func(show models.Show) bool { return show.MovieID == id })
+	if err := r.store.archiveShows(showIDs, archivedAt, guard); err != nil {
+		return err
+	}
+	movie.ArchivedAt = timeRef(archivedAt)
+	r.store.movies[id] = movie
+	return nil
+}
+
+func (r *memoryMovieRepository) Restore(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	movie, ok := r.store.movies[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if movie.ArchivedAt == nil {
+		return nil
+	}
+
+	showIDs := r.store.showIDsWhere(func(show models.Show) bool { return show.MovieID == id })
+	r.store.restoreShows(showIDs, *movie.ArchivedAt)
+	movie.ArchivedAt = nil
+	r.store.movies[id] = movie
+	return nil
+}
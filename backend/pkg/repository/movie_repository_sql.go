@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlMovieRepository struct {
+	db *sql.DB
+}
+
+const movieColumns = "id, title, duration_minutes, archived_at"
+
+func (r *sqlMovieRepository) List(ctx context.Context, includeArchived bool) ([]models.Movie, error) {
+	query := "SELECT " + movieColumns + " FROM movies"
+	if !includeArchived {
+		query += " WHERE archived_at IS NULL"
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movies []models.Movie
+	for rows.Next() {
+		movie, err := scanMovie(rows)
+		if err != nil {
+			continue
+		}
+		movies = append(movies, movie)
+	}
+
+	return movies, nil
+}
+
+func (r *sqlMovieRepository) Get(ctx context.Context, id string) (models.Movie, error) {
+	movie, err := scanMovie(r.db.QueryRowContext(ctx, "SELECT "+movieColumns+" FROM movies WHERE id = ?", id))
+	return movie, notFound(err)
+}
+
+// scanMovie reads a movie selected with movieColumns.
+func scanMovie(row rowScanner) (models.Movie, error) {
+	var movie models.Movie
+	var archivedAt sql.NullTime
+	if err := row.Scan(&movie.ID, &movie.Title, &movie.DurationMinutes, &archivedAt); err != nil {
+		return models.Movie{}, err
+	}
+	movie.ArchivedAt = timePtr(archivedAt)
+	return movie, nil
+}
+
+func (r *sqlMovieRepository) Create(ctx context.Context, movie models.Movie) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO movies(id, title, duration_minutes) VALUES(?, ?, ?)", movie.ID, movie.Title, movie.DurationMinutes)
+	return insertError(err)
+}
+
+func (r *sqlMovieRepository) Update(ctx context.Context, movie models.Movie) error {
+	return updateRow(ctx, r.db, "movies", movie.ID, "UPDATE movies SET title = ?, duration_minutes = ? WHERE id = ?",
+		movie.Title, movie.DurationMinutes, movie.ID)
+}
+
+func (r *sqlMovieRepository) Delete(ctx context.Context, id string) error {
+	return requireAffected(r.db.ExecContext(ctx, "DELETE FROM movies WHERE id = ?", id))
+}
+
+func (r *sqlMovieRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	return archiveRecord(ctx, r.db, archivableMovies, id, archivedAt, guard)
+}
+
+func (r *sqlMovieRepository) Restore(ctx context.Context, id string) error {
+	return restoreRecord(ctx, r.db, archivableMovies, id)
+}
@@ -0,0 +1,277 @@
+// Package repository stores the catalogue, bookings, seat holds, users and their tokens. Each
+// aggregate has a repository interface with a SQL implementation backed by the configured
+// database and an in-memory implementation for tests. Business rules stay in the services;
+// repositories only make each change atomic. Every method takes the caller's context, and the
+// SQL implementations run their statements with it, so a cancelled or timed-out request stops
+// its queries.
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned when the record asked for does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicateID is returned when a record is created with an ID that is already taken, so the
+// caller can retry with another one.
+var ErrDuplicateID = errors.New("id already taken")
+
+// ErrDuplicate is returned when a record would break a uniqueness rule other than its ID, such as
+// a username that is already taken.
+var ErrDuplicate = errors.New("record already exists")
+
+// ErrSeatTaken is returned when a booking or hold claims a seat that is already booked or held
+// for the same show.
+type ErrSeatTaken struct {
+	SeatID string
+}
+
+func (e *ErrSeatTaken) Error() string {
+	return fmt.Sprintf("seat %s is already booked or held", e.SeatID)
+}
+
+// ErrParentArchived is returned when restoring a record whose theatre, hall or movie is still
+// archived. Entity names the archived record.
+type ErrParentArchived struct {
+	Entity string
+}
+
+func (e *ErrParentArchived) Error() string {
+	return e.Entity + " is archived"
+}
+
+// Guard inspects what a cascading delete or archive is about to affect, from inside its
+// transaction, and stops it by returning an error.
+type Guard func(summary models.DeletionSummary) error
+
+// ShowFilter narrows down and paginates show listings. Empty fields are not filtered on.
+type ShowFilter struct {
+	MovieID   string
+	HallID    string
+	TheatreID string
+	// From and To bound the show time as RFC3339 UTC timestamps; From is inclusive, To is exclusive.
+	From string
+	To   string
+	// MinFreeSeats only returns shows with at least this many seats neither booked nor held.
+	MinFreeSeats int
+	// Limit caps the number of shows returned; zero returns every match.
+	Limit  int
+	Offset int
+	// IncludeArchived also returns archived shows.
+	IncludeArchived bool
+	// IncludeCancelled also returns cancelled shows.
+	IncludeCancelled bool
+}
+
+// MovieRepository stores movies.
+type MovieRepository interface {
+	// List returns movies; archived movies are only included when includeArchived is set.
+	List(ctx context.Context, includeArchived bool) ([]models.Movie, error)
+	Get(ctx context.Context, id string) (models.Movie, error)
+	Create(ctx context.Context, movie models.Movie) error
+	// Update stores a movie's title and duration, failing with ErrNotFound if there is no such movie.
+	Update(ctx context.Context, movie models.Movie) error
+	// Delete deletes a movie, failing with ErrNotFound if there is no such movie.
+	Delete(ctx context.Context, id string) error
+	// Archive archives a movie and its shows with the given timestamp. guard sees the upcoming
+	// shows with confirmed bookings among them.
+	Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error
+	// Restore restores an archived movie and the shows archived with it.
+	Restore(ctx context.Context, id string) error
+}
+
+// TheatreRepository stores theatres.
+type TheatreRepository interface {
+	// List returns theatres; archived theatres are only included when includeArchived is set.
+	List(ctx context.Context, includeArchived bool) ([]models.Theatre, error)
+	Get(ctx context.Context, id string) (models.Theatre, error)
+	Create(ctx context.Context, theatre models.Theatre) error
+	// Update stores a theatre's name, failing with ErrNotFound if there is no such theatre.
+	Update(ctx context.Context, theatre models.Theatre) error
+	// CountDeletion counts what deleting a theatre would remove, without deleting anything.
+	CountDeletion(ctx context.Context, id string) (models.DeletionSummary, error)
+	// Delete deletes a theatre with its halls, seats, shows, seat holds and bookings in one
+	// transaction, unless guard stops it.
+	Delete(ctx context.Context, id string, guard Guard) (models.DeletionSummary, error)
+	// Archive archives a theatre with its halls and shows. guard sees the upcoming shows with
+	// confirmed bookings among them.
+	Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error
+	// Restore restores an archived theatre and the halls and shows archived with it.
+	Restore(ctx context.Context, id string) error
+}
+
+// HallRepository stores halls and the seats generated from their layouts.
+type HallRepository interface {
+	// List returns the halls of a theatre, or of every theatre when theatreID is empty.
+	// Archived halls are only included when includeArchived is set.
+	List(ctx context.Context, theatreID string, includeArchived bool) ([]models.Hall, error)
+	Get(ctx context.Context, id string) (models.Hall, error)
+	// Create stores a hall together with its seats.
+	Create(ctx context.Context, hall models.Hall, seats []models.Seat) error
+	// Update stores a hall and replaces its seats.
+	Update(ctx context.Context, hall models.Hall, seats []models.Seat) error
+	Seats(ctx context.Context, hallID string) ([]models.Seat, error)
+	// CountDeletion counts what deleting a hall would remove, without deleting anything.
+	CountDeletion(ctx context.Context, id string) (models.DeletionSummary, error)
+	// Delete deletes a hall with its seats, shows, seat holds and bookings in one transaction,
+	// unless guard stops it.
+	Delete(ctx context.Context, id string, guard Guard) (models.DeletionSummary, error)
+	// Archive archives a hall and its shows. guard sees the upcoming shows with confirmed
+	// bookings among them.
+	Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error
+	// Restore restores an archived hall and the shows archived with it, unless its theatre is archived.
+	Restore(ctx context.Context, id string) error
+}
+
+// ShowRepository stores shows.
+type ShowRepository interface {
+	// List returns the shows matching filter ordered by time, then ID.
+	List(ctx context.Context, filter ShowFilter) ([]models.Show, error)
+	// Count returns how many shows match filter, ignoring its Limit and Offset.
+	Count(ctx context.Context, filter ShowFilter) (int, error)
+	Get(ctx context.Context, id string) (models.Show, error)
+	Create(ctx context.Context, show models.Show) error
+	// Update stores a show's movie, hall, time and prices. A non-empty notice flags the
+	// show's confirmed bookings as changed in the same transaction.
+	Update(ctx context.Context, show models.Show, notice string) error
+	// Cancel marks a show cancelled, flags its confirmed bookings with notice and marks them
+	// show_cancelled, and releases its booked and held seats.
+	Cancel(ctx context.Context, id string, notice string) error
+	// Delete deletes a show and its seat holds.
+	Delete(ctx context.Context, id string) error
+	// Archive archives a show and releases its seat holds. guard sees whether it is upcoming
+	// and has confirmed bookings.
+	Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error
+	// Restore restores an archived show, unless its movie or hall is archived.
+	Restore(ctx context.Context, id string) error
+}
+
+// BookingRepository stores bookings and the seats they claim.
+type BookingRepository interface {
+	Get(ctx context.Context, id string) (models.Booking, error)
+	// Create stores a booking and claims its seats, failing with ErrSeatTaken if one is
+	// already booked for the show or under a hold of another user still active at now. The
+	// booking's seats are released from its user's own holds in the same transaction.
+	Create(ctx context.Context, booking models.Booking, now time.Time) error
+	// CreateFromHold releases a seat hold and stores a booking in one transaction, checking
+	// the seats as Create does. It fails with ErrNotFound if the hold no longer exists.
+	CreateFromHold(ctx context.Context, holdID string, booking models.Booking, now time.Time) error
+	ListByShow(ctx context.Context, showID string) ([]models.Booking, error)
+	// ListDetailsByUser returns a user's bookings with their show, movie, hall and theatre,
+	// most recent show first.
+	ListDetailsByUser(ctx context.Context, userID string) ([]models.BookingDetails, error)
+	// BookedSeatIDs returns the seats booked for a show.
+	BookedSeatIDs(ctx context.Context, showID string) (map[string]bool, error)
+	// CountByShow counts a show's bookings with the given status, or with any status when it is empty.
+	CountByShow(ctx context.Context, showID string, status string) (int, error)
+	// Cancel marks a confirmed booking cancelled and releases its seats. It fails with
+	// ErrNotFound if there is no confirmed booking with that ID.
+	Cancel(ctx context.Context, id string, cancelledAt time.Time) error
+	// MovieRevenue sums the confirmed bookings of every show of a movie, archived ones included.
+	// A nil theatreIDs counts every theatre; otherwise only shows in halls of those theatres count.
+	MovieRevenue(ctx context.Context, movieID string, theatreIDs []string) (float64, error)
+}
+
+// SeatHoldRepository stores seat holds and the seats they claim.
+type SeatHoldRepository interface {
+	// Get returns a hold regardless of owner or expiry.
+	Get(ctx context.Context, id string) (models.SeatHold, error)
+	// Create stores a hold and claims its seats, failing with ErrSeatTaken if one is already
+	// held or booked for the show.
+	Create(ctx context.Context, hold models.SeatHold) error
+	Delete(ctx context.Context, id string) error
+	// DeleteExpired deletes the holds that expired by now, limited to one show when showID is
+	// not empty, and returns how many were deleted.
+	DeleteExpired(ctx context.Context, showID string, now time.Time) (int64, error)
+	// HeldSeatIDs returns the seats of a show under a hold still active at now, excluding
+	// holds owned by excludeUserID.
+	HeldSeatIDs(ctx context.Context, showID string, excludeUserID string, now time.Time) (map[string]bool, error)
+}
+
+// UserRepository stores users.
+type UserRepository interface {
+	// List returns every user with the theatres they are members of. Get and GetByUsername
+	// leave TheatreIDs empty; use TheatreIDs for those.
+	List(ctx context.Context) ([]models.User, error)
+	Get(ctx context.Context, id string) (models.User, error)
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	// Create stores a user, failing with ErrDuplicate if the username is taken.
+	Create(ctx context.Context, user models.User) error
+	// UpdateRole changes a user's role and bumps their token version.
+	UpdateRole(ctx context.Context, id string, role string) error
+	// UpdatePassword changes a user's password hash and whether they must change it again,
+	// and bumps their token version.
+	UpdatePassword(ctx context.Context, id string, passwordHash string, mustChange bool) error
+	// TheatreIDs returns the theatres a user is a member of, in ascending order.
+	TheatreIDs(ctx context.Context, id string) ([]string, error)
+	// SetTheatres replaces the theatres a user is a member of and bumps their token version,
+	// failing with ErrNotFound if there is no such user.
+	SetTheatres(ctx context.Context, id string, theatreIDs []string) error
+}
+
+// TokenRepository stores refresh tokens and the revocation list of access tokens.
+type TokenRepository interface {
+	// CreateRefreshToken stores a refresh token.
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) error
+	// GetRefreshToken returns a refresh token by the hash of its value, revoked or not.
+	GetRefreshToken(ctx context.Context, id string) (models.RefreshToken, error)
+	// RotateRefreshToken revokes the refresh token oldID and stores replacement in one
+	// transaction. It fails with ErrNotFound if oldID is already revoked, so a refresh token
+	// is only ever exchanged once, even by concurrent requests.
+	RotateRefreshToken(ctx context.Context, oldID string, replacement models.RefreshToken, now time.Time) error
+	// RevokeRefreshFamily revokes every refresh token of a family that is not revoked yet.
+	RevokeRefreshFamily(ctx context.Context, familyID string, now time.Time) error
+	// RevokeAccessToken adds an access token ID to the revocation list; the entry is kept
+	// until the token would have expired anyway.
+	RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error
+	// IsAccessTokenRevoked reports whether an access token ID is on the revocation list.
+	IsAccessTokenRevoked(ctx context.Context, id string) (bool, error)
+	// CreatePasswordReset stores a password reset token, deleting the user's earlier ones so
+	// only the latest works.
+	CreatePasswordReset(ctx context.Context, reset models.PasswordResetToken) error
+	// GetPasswordReset returns a password reset token by the hash of its value, expired or not.
+	GetPasswordReset(ctx context.Context, id string) (models.PasswordResetToken, error)
+	// DeletePasswordReset deletes a password reset token, failing with ErrNotFound if it is
+	// already gone, so a reset token is only ever used once, even by concurrent requests.
+	DeletePasswordReset(ctx context.Context, id string) error
+	// DeleteExpired deletes refresh tokens, revocation list entries and password reset tokens
+	// that expired by now and returns how many were deleted.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoginAttemptRepository tracks failed logins per username and per client IP.
+type LoginAttemptRepository interface {
+	// Get returns the failed logins of a username or IP, failing with ErrNotFound if there are none.
+	Get(ctx context.Context, kind string, value string) (models.LoginAttempts, error)
+	// RecordFailure counts a failed login at now and returns the updated attempts. Failures
+	// before resetBefore are forgotten, so the count starts again at one.
+	RecordFailure(ctx context.Context, kind string, value string, now time.Time, resetBefore time.Time) (models.LoginAttempts, error)
+	// Lock refuses logins for a username or IP until until. The attempts must have been recorded.
+	Lock(ctx context.Context, kind string, value string, until time.Time) error
+	// Clear forgets the failed logins and lockout of a username or IP, failing with ErrNotFound
+	// if there are none.
+	Clear(ctx context.Context, kind string, value string) error
+	// ListLocked returns the usernames and IPs locked at now, soonest unlocked first.
+	ListLocked(ctx context.Context, now time.Time) ([]models.LoginAttempts, error)
+	// DeleteStale deletes attempts whose last failure was before before and that are not
+	// locked at now, and returns how many were deleted.
+	DeleteStale(ctx context.Context, before time.Time, now time.Time) (int64, error)
+}
+
+// Repositories bundles one implementation of every repository.
+type Repositories struct {
+	Movies    MovieRepository
+	Theatres  TheatreRepository
+	Halls     HallRepository
+	Shows     ShowRepository
+	Bookings  BookingRepository
+	SeatHolds SeatHoldRepository
+	Users     UserRepository
+	Tokens    TokenRepository
+	Logins    LoginAttemptRepository
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"time"
+)
+
+type memorySeatHoldRepository struct {
+	store *memoryStore
+}
+
+func (r *memorySeatHoldRepository) Get(ctx context.Context, id string) (models.SeatHold, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	hold, ok := r.store.holds[id]
+	if !ok {
+		return models.SeatHold{}, ErrNotFound
+	}
+	return hold, nil
+}
+
+func (r *memorySeatHoldRepository) Create(ctx context.Context, hold models.SeatHold) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.holds[hold.ID]; ok {
+		return ErrDuplicateID
+	}
+	for _, seatID := range hold.SeatIDs {
+		if _, taken := r.store.heldSeats[hold.ShowID][seatID]; taken {
+			return &ErrSeatTaken{SeatID: seatID}
+		}
+		if _, booked := r.store.bookedSeats[hold.ShowID][seatID]; booked {
+			return &ErrSeatTaken{SeatID: seatID}
+		}
+	}
+
+	if r.store.heldSeats[hold.ShowID] == nil {
+		r.store.heldSeats[hold.ShowID] = make(map[string]string)
+	}
+	for _, seatID := range hold.SeatIDs {
+		r.store.heldSeats[hold.ShowID][seatID] = hold.ID
+	}
+	hold.ExpiresAt = hold.ExpiresAt.UTC()
+	r.store.holds[hold.ID] = hold
+	return nil
+}
+
+func (r *memorySeatHoldRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.holds[id]; !ok {
+		return ErrNotFound
+	}
+	r.store.deleteHold(id)
+	return nil
+}
+
+func (r *memorySeatHoldRepository) DeleteExpired(ctx context.Context, showID string, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var released int64
+	for id, hold := range r.store.holds {
+		if (showID == "" || hold.ShowID == showID) && !hold.ExpiresAt.After(now) {
+			r.store.deleteHold(id)
+			released++
+		}
+	}
+	return released, nil
+}
+
+func (r *memorySeatHoldRepository) HeldSeatIDs(ctx context.Context, showID string, excludeUserID string, now time.Time) (map[string]bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	seatIDs := make(map[string]bool)
+	for seatID, holdID := range r.store.heldSeats[showID] {
+		hold := r.store.holds[holdID]
+		if hold.ExpiresAt.After(now) && hold.UserID != excludeUserID {
+			seatIDs[seatID] = true
+		}
+	}
+	return seatIDs, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlSeatHoldRepository struct {
+	db *sql.DB
+}
+
+func (r *sqlSeatHoldRepository) Get(ctx context.Context, id string) (models.SeatHold, error) {
+	var hold models.SeatHold
+	row := r.db.QueryRowContext(ctx, "SELECT id, show_id, user_id, expires_at FROM seat_holds WHERE id = ?", id)
+	if err := row.Scan(&hold.ID, &hold.ShowID, &hold.UserID, &hold.ExpiresAt); err != nil {
+		return models.SeatHold{}, notFound(err)
+	}
+	hold.ExpiresAt = hold.ExpiresAt.UTC()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT seat_id FROM held_seats WHERE hold_id = ?", id)
+	if err != nil {
+		return models.SeatHold{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seatID string
+		if err := rows.Scan(&seatID); err != nil {
+			return models.SeatHold{}, err
+		}
+		hold.SeatIDs = append(hold.SeatIDs, seatID)
+	}
+
+	return hold, rows.Err()
+}
+
+func (r *sqlSeatHoldRepository) Create(ctx context.Context, hold models.SeatHold) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO seat_holds(id, show_id, user_id, expires_at) VALUES(?, ?, ?, ?)",
+		hold.ID, hold.ShowID, hold.UserID, hold.ExpiresAt)
+	if err != nil {
+		return insertError(err)
+	}
+
+	// The held_seats primary key stops two holds claiming the same seat, and each seat is only
+	// inserted while it is not booked, checked in the same statement as in insertBookingTx
+	stmtSeat, err := tx.PrepareContext(ctx, `
+		INSERT INTO held_seats(show_id, seat_id, hold_id)
+		SELECT ?, ?, ? FROM (SELECT 1) AS one
+		WHERE NOT EXISTS (SELECT 1 FROM booked_seats WHERE show_id = ? AND seat_id = ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmtSeat.Close()
+
+	for _, seatID := range hold.SeatIDs {
+		err := requireAffected(stmtSeat.ExecContext(ctx, hold.ShowID, seatID, hold.ID, hold.ShowID, seatID))
+		if err == ErrNotFound || (err != nil && isDuplicateKeyError(err)) {
+			return &ErrSeatTaken{SeatID: seatID}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlSeatHoldRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteHoldTx(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlSeatHoldRepository) DeleteExpired(ctx context.Context, showID string, now time.Time) (int64, error) {
+	query := "SELECT id FROM seat_holds WHERE expires_at <= ?"
+	args := []interface{}{now}
+	if showID != "" {
+		query += " AND show_id = ?"
+		args = append(args, showID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	var holdIDs []string
+	for rows.Next() {
+		var holdID string
+		if err := rows.Scan(&holdID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		holdIDs = append(holdIDs, holdID)
+	}
+	rows.Close()
+
+	var released int64
+	for _, holdID := range holdIDs {
+		err := r.Delete(ctx, holdID)
+		if err == ErrNotFound {
+			continue // Confirmed or released since it was listed
+		}
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// deleteHoldTx removes a hold and frees its seats, failing with ErrNotFound if it does not exist.
+func deleteHoldTx(ctx context.Context, tx *sql.Tx, holdID string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM held_seats WHERE hold_id = ?", holdID); err != nil {
+		return err
+	}
+	return requireAffected(tx.ExecContext(ctx, "DELETE FROM seat_holds WHERE id = ?", holdID))
+}
+
+func (r *sqlSeatHoldRepository) HeldSeatIDs(ctx context.Context, showID string, excludeUserID string, now time.Time) (map[string]bool, error) {
+	return scanSeatIDs(r.db.QueryContext(ctx,
+		"SELECT hs.seat_id FROM held_seats hs JOIN seat_holds h ON h.id = hs.hold_id WHERE hs.show_id = ? AND h.expires_at > ? AND h.user_id <> ?",
+		showID, now, excludeUserID,
+	))
+}
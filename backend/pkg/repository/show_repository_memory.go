@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type memoryShowRepository struct {
+	store *memoryStore
+}
+
+// matching returns the shows matching filter ordered by time, then ID, ignoring its Limit and Offset.
+func (r *memoryShowRepository) matching(filter ShowFilter) []models.Show {
+	now := time.Now().UTC()
+	shows := []models.Show{}
+	for _, show := range r.store.shows {
+		if !filter.IncludeCancelled && show.Status == models.ShowStatusCancelled {
+			continue
+		}
+		if !filter.IncludeArchived && show.ArchivedAt != nil {
+			continue
+		}
+		if filter.TheatreID != "" {
+			hall, ok := r.store.halls[show.HallID]
+			if !ok || hall.TheatreID != filter.TheatreID {
+				continue
+			}
+		}
+		if (filter.MovieID != "" && show.MovieID != filter.MovieID) ||
+			(filter.HallID != "" && show.HallID != filter.HallID) ||
+			(filter.From != "" && showInstant(show.Time).Before(showInstant(filter.From))) ||
+			(filter.To != "" && !showInstant(show.Time).Before(showInstant(filter.To))) {
+			continue
+		}
+		if filter.MinFreeSeats > 0 {
+			// Free seats = hall capacity - booked seats - seats under an active hold
+			free := len(r.store.seats[show.HallID]) - len(r.store.bookedSeats[show.ID])
+			for _, holdID := range r.store.heldSeats[show.ID] {
+				if r.store.holds[holdID].ExpiresAt.After(now) {
+					free--
+				}
+			}
+			if free < filter.MinFreeSeats {
+				continue
+			}
+		}
+		shows = append(shows, show)
+	}
+
+	sort.Slice(shows, func(i, j int) bool {
+		if start, other := showInstant(shows[i].Time), showInstant(shows[j].Time); !start.Equal(other) {
+			return start.Before(other)
+		}
+		return shows[i].ID < shows[j].ID
+	})
+	return shows
+}
+
+func (r *memoryShowRepository) List(ctx context.Context, filter ShowFilter) ([]models.Show, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	shows := r.matching(filter)
+	if filter.Limit > 0 {
+		start := min(filter.Offset, len(shows))
+		end := min(start+filter.Limit, len(shows))
+		shows = shows[start:end]
+	}
+	return shows, nil
+}
+
+func (r *memoryShowRepository) Count(ctx context.Context, filter ShowFilter) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return len(r.matching(filter)), nil
+}
+
+func (r *memoryShowRepository) Get(ctx context.Context, id string) (models.Show, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	show, ok := r.store.shows[id]
+	if !ok {
+		return models.Show{}, ErrNotFound
+	}
+	return show, nil
+}
+
+func (r *memoryShowRepository) Create(ctx context.Context, show models.Show) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.shows[show.ID]; ok {
+		return ErrDuplicateID
+	}
+	show.ArchivedAt = nil
+	r.store.shows[show.ID] = storedShow(show)
+	return nil
+}
+
+// storedShow returns show as the SQL repository reads it back, which drops empty category prices.
+func storedShow(show models.Show) models.Show {
+	if len(show.CategoryPrices) == 0 {
+		show.CategoryPrices = nil
+	}
+	return show
+}
+
+func (r *memoryShowRepository) Update(ctx context.Context, show models.Show, notice string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.shows[show.ID]
+	if !ok {
+		return nil
+	}
+	existing.MovieID = show.MovieID
+	existing.HallID = show.HallID
+	existing.Time = show.Time
+	existing.Price = show.Price
+	existing.CategoryPrices = show.CategoryPrices
+	r.store.shows[show.ID] = storedShow(existing)
+
+	if notice != "" {
+		r.store.flagBookings(show.ID, notice)
+	}
+	return nil
+}
+
+func (r *memoryShowRepository) Cancel(ctx context.Context, id string, notice string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	show, ok := r.store.shows[id]
+	if !ok {
+		return ErrNotFound
+	}
+	show.Status = models.ShowStatusCancelled
+	r.store.shows[id] = show
+
+	r.store.flagBookings(id, notice)
+	for bookingID, booking := range r.store.bookings {
+		if booking.ShowID == id && booking.Status == models.BookingStatusConfirmed {
+			booking.Status = models.BookingStatusShowCancelled
+			r.store.bookings[bookingID] = booking
+		}
+	}
+	delete(r.store.bookedSeats, id)
+	r.store.deleteHolds(id)
+	return nil
+}
+
+func (r *memoryShowRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteHolds(id)
+	delete(r.store.shows, id)
+	return nil
+}
+
+func (r *memoryShowRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	show, ok := r.store.shows[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if show.ArchivedAt != nil {
+		return nil
+	}
+	return r.store.archiveShows([]string{id}, archivedAt, guard)
+}
+
+func (r *memoryShowRepository) Restore(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	show, ok := r.store.shows[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if show.ArchivedAt == nil {
+		return nil
+	}
+	if r.store.movies[show.MovieID].ArchivedAt != nil {
+		return &ErrParentArchived{Entity: "movie"}
+	}
+	if r.store.halls[show.HallID].ArchivedAt != nil {
+		return &ErrParentArchived{Entity: "hall"}
+	}
+
+	r.store.restoreShows([]string{id}, *show.ArchivedAt)
+	return nil
+}
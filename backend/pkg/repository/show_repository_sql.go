@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type sqlShowRepository struct {
+	db *sql.DB
+}
+
+// showColumns lists the shows columns read by scanShow, in order.
+const showColumns = "id, movie_id, hall_id, time, price, status, category_prices, archived_at"
+
+// showConditions builds the FROM and WHERE clauses selecting the shows matching filter, aliased s.
+func showConditions(filter ShowFilter) (string, []interface{}) {
+	from := " FROM shows s"
+	var where []string
+	var args []interface{}
+
+	if !filter.IncludeCancelled {
+		where = append(where, "s.status <> ?")
+		args = append(args, models.ShowStatusCancelled)
+	}
+	if !filter.IncludeArchived {
+		where = append(where, "s.archived_at IS NULL")
+	}
+	if filter.TheatreID != "" {
+		from += " JOIN halls h ON h.id = s.hall_id"
+		where = append(where, "h.theatre_id = ?")
+		args = append(args, filter.TheatreID)
+	}
+	if filter.MovieID != "" {
+		where = append(where, "s.movie_id = ?")
+		args = append(args, filter.MovieID)
+	}
+	if filter.HallID != "" {
+		where = append(where, "s.hall_id = ?")
+		args = append(args, filter.HallID)
+	}
+	if filter.From != "" {
+		where = append(where, "s.time >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		where = append(where, "s.time < ?")
+		args = append(args, filter.To)
+	}
+	if filter.MinFreeSeats > 0 {
+		// Free seats = hall capacity - booked seats - seats under an active hold
+		where = append(where, `(SELECT COUNT(*) FROM seats st WHERE st.hall_id = s.hall_id)
+			- (SELECT COUNT(*) FROM booked_seats bs WHERE bs.show_id = s.id)
+			- (SELECT COUNT(*) FROM held_seats hs JOIN seat_holds sh ON sh.id = hs.hold_id WHERE hs.show_id = s.id AND sh.expires_at > ?)
+			>= ?`)
+		args = append(args, time.Now().UTC(), filter.MinFreeSeats)
+	}
+
+	if len(where) > 0 {
+		from += " WHERE " + strings.Join(where, " AND ")
+	}
+	return from, args
+}
+
+func (r *sqlShowRepository) List(ctx context.Context, filter ShowFilter) ([]models.Show, error) {
+	from, args := showConditions(filter)
+	query := "SELECT " + prefixColumns("s", showColumns) + from + " ORDER BY s.time, s.id"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shows := []models.Show{}
+	for rows.Next() {
+		show, err := scanShow(rows)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		shows = append(shows, show)
+	}
+
+	return shows, rows.Err()
+}
+
+func (r *sqlShowRepository) Count(ctx context.Context, filter ShowFilter) (int, error) {
+	from, args := showConditions(filter)
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*)"+from, args...).Scan(&count)
+	return count, err
+}
+
+func (r *sqlShowRepository) Get(ctx context.Context, id string) (models.Show, error) {
+	show, err := scanShow(r.db.QueryRowContext(ctx, "SELECT "+showColumns+" FROM shows WHERE id = ?", id))
+	return show, notFound(err)
+}
+
+// scanShow reads a show selected with showColumns.
+func scanShow(row rowScanner) (models.Show, error) {
+	var show models.Show
+	var categoryPricesStr sql.NullString
+	var archivedAt sql.NullTime
+	if err := row.Scan(&show.ID, &show.MovieID, &show.HallID, &show.Time, &show.Price, &show.Status, &categoryPricesStr, &archivedAt); err != nil {
+		return models.Show{}, err
+	}
+	show.ArchivedAt = timePtr(archivedAt)
+	if categoryPricesStr.Valid && categoryPricesStr.String != "" {
+		if err := json.Unmarshal([]byte(categoryPricesStr.String), &show.CategoryPrices); err != nil {
+			return models.Show{}, fmt.Errorf("error unmarshaling category prices for show %s: %w", show.ID, err)
+		}
+	}
+	return show, nil
+}
+
+// marshalCategoryPrices encodes category prices for the shows.category_prices column.
+func marshalCategoryPrices(prices map[string]float64) string {
+	if len(prices) == 0 {
+		return ""
+	}
+	pricesBytes, _ := json.Marshal(prices)
+	return string(pricesBytes)
+}
+
+func (r *sqlShowRepository) Create(ctx context.Context, show models.Show) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO shows(id, movie_id, hall_id, time, price, status, category_prices) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		show.ID, show.MovieID, show.HallID, show.Time, show.Price, show.Status, marshalCategoryPrices(show.CategoryPrices))
+	return insertError(err)
+}
+
+func (r *sqlShowRepository) Update(ctx context.Context, show models.Show, notice string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "UPDATE shows SET movie_id = ?, hall_id = ?, time = ?, price = ?, category_prices = ? WHERE id = ?",
+		show.MovieID, show.HallID, show.Time, show.Price, marshalCategoryPrices(show.CategoryPrices), show.ID)
+	if err != nil {
+		return err
+	}
+
+	if notice != "" {
+		if err := flagBookingsTx(ctx, tx, show.ID, notice); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlShowRepository) Cancel(ctx context.Context, id string, notice string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireAffected(tx.ExecContext(ctx, "UPDATE shows SET status = ? WHERE id = ?", models.ShowStatusCancelled, id)); err != nil {
+		return err
+	}
+
+	if err := flagBookingsTx(ctx, tx, id, notice); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE bookings SET status = ? WHERE show_id = ? AND status = ?",
+		models.BookingStatusShowCancelled, id, models.BookingStatusConfirmed)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM booked_seats WHERE show_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM held_seats WHERE show_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM seat_holds WHERE show_id = ?", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// flagBookingsTx marks the confirmed bookings of a show as affected by a show change so customers can be notified.
+func flagBookingsTx(ctx context.Context, tx *sql.Tx, showID string, notice string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE bookings SET show_changed_at = ?, notice = ? WHERE show_id = ? AND status = ?",
+		time.Now().UTC(), notice, showID, models.BookingStatusConfirmed)
+	return err
+}
+
+func (r *sqlShowRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM held_seats WHERE show_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM seat_holds WHERE show_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shows WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlShowRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	return archiveRecord(ctx, r.db, archivableShows, id, archivedAt, guard)
+}
+
+func (r *sqlShowRepository) Restore(ctx context.Context, id string) error {
+	return restoreRecord(ctx, r.db, archivableShows, id)
+}
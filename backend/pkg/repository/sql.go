@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// NewSQLRepositories returns repositories backed by db, which must have been migrated to the
+// current schema.
+func NewSQLRepositories(db *sql.DB) Repositories {
+	return Repositories{
+		Movies:    &sqlMovieRepository{db: db},
+		Theatres:  &sqlTheatreRepository{db: db},
+		Halls:     &sqlHallRepository{db: db},
+		Shows:     &sqlShowRepository{db: db},
+		Bookings:  &sqlBookingRepository{db: db},
+		SeatHolds: &sqlSeatHoldRepository{db: db},
+		Users:     &sqlUserRepository{db: db},
+		Tokens:    &sqlTokenRepository{db: db},
+		Logins:    &sqlLoginAttemptRepository{db: db},
+	}
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// prefixColumns qualifies a comma-separated column list with a table alias for use in joins.
+func prefixColumns(alias string, columnList string) string {
+	columns := strings.Split(columnList, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// notFound turns sql.ErrNoRows into ErrNotFound.
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// requireAffected returns ErrNotFound when a statement changed no rows.
+func requireAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// updateRow runs an UPDATE of the row of table with the given id, failing with ErrNotFound if
+// there is no such row. The row is looked up first rather than counting affected rows, since
+// MySQL reports none for an update that changes nothing.
+func updateRow(ctx context.Context, db *sql.DB, table string, id string, query string, args ...interface{}) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT 1 FROM %s WHERE id = ?", table), id).Scan(&exists); err != nil {
+		return notFound(err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertError turns a primary key violation into ErrDuplicateID and any other unique
+// constraint violation into ErrDuplicate.
+func insertError(err error) error {
+	if err != nil && isPrimaryKeyConflict(err) {
+		return ErrDuplicateID
+	}
+	if err != nil && isDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// isPrimaryKeyConflict reports whether err is a primary key violation, as opposed to a
+// violation of another unique constraint.
+func isPrimaryKeyConflict(err error) bool {
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+		// MySQL names the violated key: "... for key 'PRIMARY'" or "... for key 'table.PRIMARY'"
+		return strings.Contains(mysqlErr.Message, "PRIMARY'")
+	}
+	if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return true
+	}
+	return false
+}
+
+// isDuplicateKeyError reports whether err is a primary key or unique constraint violation.
+func isDuplicateKeyError(err error) bool {
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+		return true
+	}
+	if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+		return true
+	}
+	return false
+}
+
+// timePtr returns the UTC time of a nullable column, or nil when it is NULL.
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// countActiveBookingsTx counts the scheduled shows matching showCondition, a condition on the
+// shows table, that have not started yet and have confirmed bookings, and those bookings.
+func countActiveBookingsTx(ctx context.Context, q queryer, showCondition string, args ...interface{}) (models.DeletionSummary, error) {
+	var summary models.DeletionSummary
+	upcoming := "SELECT id FROM shows WHERE (" + showCondition + ") AND time > ? AND status = ?"
+	upcomingArgs := append(append([]interface{}{}, args...), time.Now().UTC().Format(time.RFC3339), models.ShowStatusScheduled)
+
+	err := q.QueryRowContext(ctx, "SELECT COUNT(DISTINCT show_id), COUNT(*) FROM bookings WHERE status = ? AND show_id IN ("+upcoming+")",
+		append([]interface{}{models.BookingStatusConfirmed}, upcomingArgs...)...).Scan(&summary.UpcomingShows, &summary.ActiveBookings)
+	if err != nil {
+		return summary, fmt.Errorf("error counting active bookings: %w", err)
+	}
+	return summary, nil
+}
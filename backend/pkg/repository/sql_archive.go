@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// archivable describes how a table's records are archived and restored. Archiving a record
+// also archives its dependents with the same timestamp, so restoring it brings back exactly
+// the dependents that were archived with it and not those archived on their own earlier.
+type archivable struct {
+	table string
+	// dependents are conditions on other tables selecting the rows archived with a record;
+	// each takes the record ID as its only argument.
+	dependents []archiveDependent
+	// shows selects the shows archived with a record, from the shows table, with the record
+	// ID as its only argument.
+	shows string
+	// parents are the records that must not be archived when this record is restored.
+	parents []archiveParent
+}
+
+type archiveDependent struct {
+	table     string
+	condition string
+}
+
+type archiveParent struct {
+	table  string
+	column string
+	entity string
+}
+
+var (
+	archivableMovies = archivable{
+		table:      "movies",
+		dependents: []archiveDependent{{"shows", "movie_id = ?"}},
+		shows:      "movie_id = ?",
+	}
+	archivableTheatres = archivable{
+		table: "theatres",
+		dependents: []archiveDependent{
+			{"shows", "hall_id IN (SELECT id FROM halls WHERE theatre_id = ?)"},
+			{"halls", "theatre_id = ?"},
+		},
+		shows: "hall_id IN (SELECT id FROM halls WHERE theatre_id = ?)",
+	}
+	archivableHalls = archivable{
+		table:      "halls",
+		dependents: []archiveDependent{{"shows", "hall_id = ?"}},
+		shows:      "hall_id = ?",
+		parents:    []archiveParent{{"theatres", "theatre_id", "theatre"}},
+	}
+	archivableShows = archivable{
+		table: "shows",
+		shows: "id = ?",
+		parents: []archiveParent{
+			{"movies", "movie_id", "movie"},
+			{"halls", "hall_id", "hall"},
+		},
+	}
+)
+
+// archiveRecord archives the record with the given ID and its dependents in one transaction,
+// after guard has seen the upcoming shows with confirmed bookings among the shows it archives.
+// Seat holds on the archived shows are released. Archiving an archived record does nothing.
+func archiveRecord(ctx context.Context, db *sql.DB, a archivable, id string, archivedAt time.Time, guard Guard) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := getArchivedAt(ctx, tx, a, id)
+	if err != nil {
+		return err
+	}
+	if current.Valid {
+		return nil
+	}
+
+	summary, err := countActiveBookingsTx(ctx, tx, a.shows+" AND archived_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	if guard != nil {
+		if err := guard(summary); err != nil {
+			return err
+		}
+	}
+
+	showIDs := "SELECT id FROM shows WHERE " + a.shows
+	if _, err := tx.ExecContext(ctx, "DELETE FROM held_seats WHERE hold_id IN (SELECT id FROM seat_holds WHERE show_id IN ("+showIDs+"))", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM seat_holds WHERE show_id IN ("+showIDs+")", id); err != nil {
+		return err
+	}
+
+	for _, dependent := range a.dependents {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET archived_at = ? WHERE archived_at IS NULL AND %s", dependent.table, dependent.condition), archivedAt, id)
+		if err != nil {
+			return fmt.Errorf("error archiving %s: %w", dependent.table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET archived_at = ? WHERE id = ?", a.table), archivedAt, id); err != nil {
+		return fmt.Errorf("error archiving %s: %w", a.table, err)
+	}
+
+	return tx.Commit()
+}
+
+// restoreRecord restores an archived record together with the dependents archived with it.
+// A record cannot be restored while a record it belongs to is still archived.
+func restoreRecord(ctx context.Context, db *sql.DB, a archivable, id string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	archivedAt, err := getArchivedAt(ctx, tx, a, id)
+	if err != nil {
+		return err
+	}
+	if !archivedAt.Valid {
+		return nil
+	}
+
+	for _, parent := range a.parents {
+		var archivedParents int
+		err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE archived_at IS NOT NULL AND id = (SELECT %s FROM %s WHERE id = ?)",
+			parent.table, parent.column, a.table), id).Scan(&archivedParents)
+		if err != nil {
+			return err
+		}
+		if archivedParents > 0 {
+			return &ErrParentArchived{Entity: parent.entity}
+		}
+	}
+
+	archivedWith := fmt.Sprintf("(SELECT archived_at FROM %s WHERE id = ?)", a.table)
+	for _, dependent := range a.dependents {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET archived_at = NULL WHERE %s AND archived_at = %s", dependent.table, dependent.condition, archivedWith), id, id)
+		if err != nil {
+			return fmt.Errorf("error restoring %s: %w", dependent.table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET archived_at = NULL WHERE id = ?", a.table), id); err != nil {
+		return fmt.Errorf("error restoring %s: %w", a.table, err)
+	}
+
+	return tx.Commit()
+}
+
+// getArchivedAt returns when the record was archived, if it was.
+func getArchivedAt(ctx context.Context, tx *sql.Tx, a archivable, id string) (sql.NullTime, error) {
+	var archivedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT archived_at FROM %s WHERE id = ?", a.table), id).Scan(&archivedAt)
+	return archivedAt, notFound(err)
+}
+
+// cascadeDelete is one step of a cascading delete.
+type cascadeDelete struct {
+	table string
+	query string
+}
+
+// deleteHallsCascade deletes the halls matching hallFilter, a condition on the halls table,
+// together with their seats, shows, seat holds, bookings and booked seats, in one
+// transaction. ownerTable names the table holding the record with the given ID that the
+// delete starts from; it is deleted last in the same transaction. A dry run only counts.
+func deleteHallsCascade(ctx context.Context, db *sql.DB, ownerTable string, hallFilter string, id string, dryRun bool, guard Guard) (models.DeletionSummary, error) {
+	summary := models.DeletionSummary{DryRun: dryRun}
+	args := []interface{}{id}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+ownerTable+" WHERE id = ?", id).Scan(&exists); err != nil {
+		return summary, err
+	}
+	if exists == 0 {
+		return summary, ErrNotFound
+	}
+
+	hallIDs := "SELECT id FROM halls WHERE " + hallFilter
+	showIDs := "SELECT id FROM shows WHERE hall_id IN (" + hallIDs + ")"
+	bookingIDs := "SELECT id FROM bookings WHERE show_id IN (" + showIDs + ")"
+	holdIDs := "SELECT id FROM seat_holds WHERE show_id IN (" + showIDs + ")"
+
+	counts := []struct {
+		query string
+		dest  *int
+	}{
+		{"SELECT COUNT(*) FROM halls WHERE " + hallFilter, &summary.Halls},
+		{"SELECT COUNT(*) FROM shows WHERE hall_id IN (" + hallIDs + ")", &summary.Shows},
+		{"SELECT COUNT(*) FROM bookings WHERE show_id IN (" + showIDs + ")", &summary.Bookings},
+	}
+	for _, count := range counts {
+		if err := tx.QueryRowContext(ctx, count.query, args...).Scan(count.dest); err != nil {
+			return summary, fmt.Errorf("error counting records to delete: %w", err)
+		}
+	}
+
+	active, err := countActiveBookingsTx(ctx, tx, "hall_id IN ("+hallIDs+")", args...)
+	if err != nil {
+		return summary, err
+	}
+	summary.UpcomingShows = active.UpcomingShows
+	summary.ActiveBookings = active.ActiveBookings
+
+	if dryRun {
+		return summary, nil
+	}
+	if guard != nil {
+		if err := guard(summary); err != nil {
+			return summary, err
+		}
+	}
+
+	// Children are deleted before their parents so the deletes also work without foreign key cascades
+	deletes := []cascadeDelete{
+		{"booked seats", "DELETE FROM booked_seats WHERE booking_id IN (" + bookingIDs + ")"},
+		{"bookings", "DELETE FROM bookings WHERE show_id IN (" + showIDs + ")"},
+		{"held seats", "DELETE FROM held_seats WHERE hold_id IN (" + holdIDs + ")"},
+		{"seat holds", "DELETE FROM seat_holds WHERE show_id IN (" + showIDs + ")"},
+		{"shows", "DELETE FROM shows WHERE hall_id IN (" + hallIDs + ")"},
+		{"seats", "DELETE FROM seats WHERE hall_id IN (" + hallIDs + ")"},
+		{"halls", "DELETE FROM halls WHERE " + hallFilter},
+	}
+	if ownerTable != "halls" {
+		deletes = append(deletes, cascadeDelete{ownerTable, "DELETE FROM " + ownerTable + " WHERE id = ?"})
+	}
+	for _, d := range deletes {
+		if _, err := tx.ExecContext(ctx, d.query, args...); err != nil {
+			return summary, fmt.Errorf("error deleting %s: %w", d.table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"time"
+)
+
+type memoryTheatreRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTheatreRepository) List(ctx context.Context, includeArchived bool) ([]models.Theatre, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var theatres []models.Theatre
+	for _, id := range sortedKeys(r.store.theatres) {
+		if theatre := r.store.theatres[id]; includeArchived || theatre.ArchivedAt == nil {
+			theatres = append(theatres, theatre)
+		}
+	}
+	return theatres, nil
+}
+
+func (r *memoryTheatreRepository) Get(ctx context.Context, id string) (models.Theatre, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	theatre, ok := r.store.theatres[id]
+	if !ok {
+		return models.Theatre{}, ErrNotFound
+	}
+	return theatre, nil
+}
+
+func (r *memoryTheatreRepository) Create(ctx context.Context, theatre models.Theatre) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.theatres[theatre.ID]; ok {
+		return ErrDuplicateID
+	}
+	theatre.ArchivedAt = nil
+	r.store.theatres[theatre.ID] = theatre
+	return nil
+}
+
+func (r *memoryTheatreRepository) Update(ctx context.Context, theatre models.Theatre) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.theatres[theatre.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Name = theatre.Name
+	r.store.theatres[theatre.ID] = existing
+	return nil
+}
+
+func (r *memoryTheatreRepository) CountDeletion(ctx context.Context, id string) (models.DeletionSummary, error) {
+	return r.delete(id, true, nil)
+}
+
+func (r *memoryTheatreRepository) Delete(ctx context.Context, id string, guard Guard) (models.DeletionSummary, error) {
+	return r.delete(id, false, guard)
+}
+
+func (r *memoryTheatreRepository) delete(id string, dryRun bool, guard Guard) (models.DeletionSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.theatres[id]; !ok {
+		return models.DeletionSummary{DryRun: dryRun}, ErrNotFound
+	}
+
+	hallIDs := r.store.hallIDsWhere(func(hall models.Hall) bool { return hall.TheatreID == id })
+	summary, err := r.store.deleteHalls(hallIDs, dryRun, guard)
+	if err != nil || dryRun {
+		return summary, err
+	}
+	delete(r.store.theatres, id)
+	// Memberships of the theatre go with it, like the ON DELETE CASCADE of the SQL schema
+	for userID, theatreIDs := range r.store.theatreMembers {
+		var kept []string
+		for _, theatreID := range theatreIDs {
+			if theatreID != id {
+				kept = append(kept, theatreID)
+			}
+		}
+		r.store.theatreMembers[userID] = kept
+	}
+	return summary, nil
+}
+
+func (r *memoryTheatreRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	theatre, ok := r.store.theatres[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if theatre.ArchivedAt != nil {
+		return nil
+	}
+
+	hallIDs, showIDs := r.contents(id)
+	if err := r.store.archiveShows(showIDs, archivedAt, guard); err != nil {
+		return err
+	}
+	for _, hallID := range hallIDs {
+		if hall := r.store.halls[hallID]; hall.ArchivedAt == nil {
+			hall.ArchivedAt = timeRef(archivedAt)
+			r.store.halls[hallID] = hall
+		}
+	}
+	theatre.ArchivedAt = timeRef(archivedAt)
+	r.store.theatres[id] = theatre
+	return nil
+}
+
+func (r *memoryTheatreRepository) Restore(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	theatre, ok := r.store.theatres[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if theatre.ArchivedAt == nil {
+		return nil
+	}
+
+	hallIDs, showIDs := r.contents(id)
+	r.store.restoreShows(showIDs, *theatre.ArchivedAt)
+	for _, hallID := range hallIDs {
+		if hall := r.store.halls[hallID]; hall.ArchivedAt != nil && hall.ArchivedAt.Equal(*theatre.ArchivedAt) {
+			hall.ArchivedAt = nil
+			r.store.halls[hallID] = hall
+		}
+	}
+	theatre.ArchivedAt = nil
+	r.store.theatres[id] = theatre
+	return nil
+}
+
+// contents returns the halls of a theatre and the shows in them.
+func (r *memoryTheatreRepository) contents(id string) (hallIDs []string, showIDs []string) {
+	hallIDs = r.store.hallIDsWhere(func(hall models.Hall) bool { return hall.TheatreID == id })
+	inHalls := make(map[string]bool)
+	for _, hallID := range hallIDs {
+		inHalls[hallID] = true
+	}
+	showIDs = r.store.showIDsWhere(func(show models.Show) bool { return inHalls[show.HallID] })
+	return hallIDs, showIDs
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlTheatreRepository struct {
+	db *sql.DB
+}
+
+const theatreColumns = "id, name, archived_at"
+
+func (r *sqlTheatreRepository) List(ctx context.Context, includeArchived bool) ([]models.Theatre, error) {
+	query := "SELECT " + theatreColumns + " FROM theatres"
+	if !includeArchived {
+		query += " WHERE archived_at IS NULL"
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var theatres []models.Theatre
+	for rows.Next() {
+		theatre, err := scanTheatre(rows)
+		if err != nil {
+			continue
+		}
+		theatres = append(theatres, theatre)
+	}
+
+	return theatres, nil
+}
+
+func (r *sqlTheatreRepository) Get(ctx context.Context, id string) (models.Theatre, error) {
+	theatre, err := scanTheatre(r.db.QueryRowContext(ctx, "SELECT "+theatreColumns+" FROM theatres WHERE id = ?", id))
+	return theatre, notFound(err)
+}
+
+// scanTheatre reads a theatre selected with theatreColumns.
+func scanTheatre(row rowScanner) (models.Theatre, error) {
+	var theatre models.Theatre
+	var archivedAt sql.NullTime
+	if err := row.Scan(&theatre.ID, &theatre.Name, &archivedAt); err != nil {
+		return models.Theatre{}, err
+	}
+	theatre.ArchivedAt = timePtr(archivedAt)
+	return theatre, nil
+}
+
+func (r *sqlTheatreRepository) Create(ctx context.Context, theatre models.Theatre) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO theatres(id, name) VALUES(?, ?)", theatre.ID, theatre.Name)
+	return insertError(err)
+}
+
+func (r *sqlTheatreRepository) Update(ctx context.Context, theatre models.Theatre) error {
+	return updateRow(ctx, r.db, "theatres", theatre.ID, "UPDATE theatres SET name = ? WHERE id = ?", theatre.Name, theatre.ID)
+}
+
+func (r *sqlTheatreRepository) CountDeletion(ctx context.Context, id string) (models.DeletionSummary, error) {
+	return deleteHallsCascade(ctx, r.db, "theatres", "theatre_id = ?", id, true, nil)
+}
+
+func (r *sqlTheatreRepository) Delete(ctx context.Context, id string, guard Guard) (models.DeletionSummary, error) {
+	return deleteHallsCascade(ctx, r.db, "theatres", "theatre_id = ?", id, false, guard)
+}
+
+func (r *sqlTheatreRepository) Archive(ctx context.Context, id string, archivedAt time.Time, guard Guard) error {
+	return archiveRecord(ctx, r.db, archivableTheatres, id, archivedAt, guard)
+}
+
+func (r *sqlTheatreRepository) Restore(ctx context.Context, id string) error {
+	return restoreRecord(ctx, r.db, archivableTheatres, id)
+}
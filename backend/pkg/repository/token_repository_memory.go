@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"time"
+)
+
+type memoryTokenRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTokenRepository) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.refreshTokens[token.ID]; ok {
+		return ErrDuplicateID
+	}
+	r.store.refreshTokens[token.ID] = token
+	return nil
+}
+
+func (r *memoryTokenRepository) GetRefreshToken(ctx context.Context, id string) (models.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok {
+		return models.RefreshToken{}, ErrNotFound
+	}
+	return token, nil
+}
+
+func (r *memoryTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, replacement models.RefreshToken, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	old, ok := r.store.refreshTokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return ErrNotFound
+	}
+	if _, ok := r.store.refreshTokens[replacement.ID]; ok {
+		return ErrDuplicateID
+	}
+	old.RevokedAt = &now
+	r.store.refreshTokens[oldID] = old
+	r.store.refreshTokens[replacement.ID] = replacement
+	return nil
+}
+
+func (r *memoryTokenRepository) RevokeRefreshFamily(ctx context.Context, familyID string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, token := range r.store.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.store.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.revokedTokens[id] = expiresAt
+	return nil
+}
+
+func (r *memoryTokenRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	_, revoked := r.store.revokedTokens[id]
+	return revoked, nil
+}
+
+func (r *memoryTokenRepository) CreatePasswordReset(ctx context.Context, reset models.PasswordResetToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.passwordResets[reset.ID]; ok {
+		return ErrDuplicateID
+	}
+	for id, existing := range r.store.passwordResets {
+		if existing.UserID == reset.UserID {
+			delete(r.store.passwordResets, id)
+		}
+	}
+	r.store.passwordResets[reset.ID] = reset
+	return nil
+}
+
+func (r *memoryTokenRepository) GetPasswordReset(ctx context.Context, id string) (models.PasswordResetToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reset, ok := r.store.passwordResets[id]
+	if !ok {
+		return models.PasswordResetToken{}, ErrNotFound
+	}
+	return reset, nil
+}
+
+func (r *memoryTokenRepository) DeletePasswordReset(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.passwordResets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.store.passwordResets, id)
+	return nil
+}
+
+func (r *memoryTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for id, token := range r.store.refreshTokens {
+		if !token.ExpiresAt.After(now) {
+			delete(r.store.refreshTokens, id)
+			deleted++
+		}
+	}
+	for id, expiresAt := range r.store.revokedTokens {
+		if !expiresAt.After(now) {
+			delete(r.store.revokedTokens, id)
+			deleted++
+		}
+	}
+	for id, reset := range r.store.passwordResets {
+		if !reset.ExpiresAt.After(now) {
+			delete(r.store.passwordResets, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
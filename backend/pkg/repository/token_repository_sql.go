@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type sqlTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *sqlTokenRepository) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, token)
+}
+
+// insertRefreshToken stores a refresh token using q, which may be a transaction.
+func insertRefreshToken(ctx context.Context, q queryer, token models.RefreshToken) error {
+	_, err := q.ExecContext(ctx, "INSERT INTO refresh_tokens(id, user_id, family_id, token_version, expires_at) VALUES(?, ?, ?, ?, ?)",
+		token.ID, token.UserID, token.FamilyID, token.TokenVersion, token.ExpiresAt)
+	return insertError(err)
+}
+
+func (r *sqlTokenRepository) GetRefreshToken(ctx context.Context, id string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	var revokedAt sql.NullTime
+	row := r.db.QueryRowContext(ctx, "SELECT id, user_id, family_id, token_version, expires_at, revoked_at FROM refresh_tokens WHERE id = ?", id)
+	if err := row.Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenVersion, &token.ExpiresAt, &revokedAt); err != nil {
+		return models.RefreshToken{}, notFound(err)
+	}
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.RevokedAt = timePtr(revokedAt)
+	return token, nil
+}
+
+func (r *sqlTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, replacement models.RefreshToken, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only one of two concurrent rotations can revoke the old token
+	err = requireAffected(tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", now, oldID))
+	if err != nil {
+		return err
+	}
+	if err := insertRefreshToken(ctx, tx, replacement); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlTokenRepository) RevokeRefreshFamily(ctx context.Context, familyID string, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", now, familyID)
+	return err
+}
+
+func (r *sqlTokenRepository) RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO revoked_tokens(id, expires_at) VALUES(?, ?)", id, expiresAt)
+	if isDuplicateKeyError(err) {
+		// Already revoked
+		return nil
+	}
+	return err
+}
+
+func (r *sqlTokenRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM revoked_tokens WHERE id = ?", id).Scan(&count)
+	return count > 0, err
+}
+
+func (r *sqlTokenRepository) CreatePasswordReset(ctx context.Context, reset models.PasswordResetToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM password_reset_tokens WHERE user_id = ?", reset.UserID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO password_reset_tokens(id, user_id, expires_at) VALUES(?, ?, ?)",
+		reset.ID, reset.UserID, reset.ExpiresAt)
+	if err != nil {
+		return insertError(err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlTokenRepository) GetPasswordReset(ctx context.Context, id string) (models.PasswordResetToken, error) {
+	var reset models.PasswordResetToken
+	row := r.db.QueryRowContext(ctx, "SELECT id, user_id, expires_at FROM password_reset_tokens WHERE id = ?", id)
+	if err := row.Scan(&reset.ID, &reset.UserID, &reset.ExpiresAt); err != nil {
+		return models.PasswordResetToken{}, notFound(err)
+	}
+	reset.ExpiresAt = reset.ExpiresAt.UTC()
+	return reset, nil
+}
+
+func (r *sqlTokenRepository) DeletePasswordReset(ctx context.Context, id string) error {
+	return requireAffected(r.db.ExecContext(ctx, "DELETE FROM password_reset_tokens WHERE id = ?", id))
+}
+
+func (r *sqlTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for _, table := range []string{"refresh_tokens", "revoked_tokens", "password_reset_tokens"} {
+		res, err := r.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE expires_at <= ?", now)
+		if err != nil {
+			return deleted, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += affected
+	}
+	return deleted, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"sort"
+)
+
+type memoryUserRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryUserRepository) List(ctx context.Context) ([]models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var users []models.User
+	for _, id := range sortedKeys(r.store.users) {
+		user := r.store.users[id]
+		user.TheatreIDs = r.store.theatreMembers[id]
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) Get(ctx context.Context, id string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[user.ID]; ok {
+		return ErrDuplicateID
+	}
+	for _, existing := range r.store.users {
+		if existing.Username == user.Username {
+			return ErrDuplicate
+		}
+	}
+	r.store.users[user.ID] = user
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateRole(ctx context.Context, id string, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Role = role
+	user.TokenVersion++
+	r.store.users[id] = user
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(ctx context.Context, id string, passwordHash string, mustChange bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.PasswordHash = passwordHash
+	user.MustChangePassword = mustChange
+	user.TokenVersion++
+	r.store.users[id] = user
+	return nil
+}
+
+func (r *memoryUserRepository) TheatreIDs(ctx context.Context, id string) ([]string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.theatreMembers[id], nil
+}
+
+func (r *memoryUserRepository) SetTheatres(ctx context.Context, id string, theatreIDs []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.TokenVersion++
+	r.store.users[id] = user
+
+	if len(theatreIDs) == 0 {
+		delete(r.store.theatreMembers, id)
+		return nil
+	}
+	sorted := append([]string{}, theatreIDs...)
+	sort.Strings(sorted)
+	r.store.theatreMembers[id] = sorted
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"database/sql"
+)
+
+type sqlUserRepository struct {
+	db *sql.DB
+}
+
+func (r *sqlUserRepository) List(ctx context.Context) ([]models.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, username, role, must_change_password FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.MustChangePassword); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	memberships, err := r.db.QueryContext(ctx, "SELECT user_id, theatre_id FROM theatre_members ORDER BY theatre_id")
+	if err != nil {
+		return nil, err
+	}
+	defer memberships.Close()
+
+	theatreIDs := make(map[string][]string)
+	for memberships.Next() {
+		var userID, theatreID string
+		if err := memberships.Scan(&userID, &theatreID); err != nil {
+			return nil, err
+		}
+		theatreIDs[userID] = append(theatreIDs[userID], theatreID)
+	}
+	for i := range users {
+		users[i].TheatreIDs = theatreIDs[users[i].ID]
+	}
+
+	return users, memberships.Err()
+}
+
+func (r *sqlUserRepository) Get(ctx context.Context, id string) (models.User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, "SELECT id, username, password_hash, role, token_version, must_change_password FROM users WHERE id = ?", id))
+}
+
+func (r *sqlUserRepository) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, "SELECT id, username, password_hash, role, token_version, must_change_password FROM users WHERE username = ?", username))
+}
+
+// scanUser reads a user selected with its ID, username, password hash, role, token version and
+// whether they must change their password.
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.TokenVersion, &user.MustChangePassword); err != nil {
+		return models.User{}, notFound(err)
+	}
+	return user, nil
+}
+
+func (r *sqlUserRepository) Create(ctx context.Context, user models.User) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO users(id, username, password_hash, role, must_change_password) VALUES(?, ?, ?, ?, ?)",
+		user.ID, user.Username, user.PasswordHash, user.Role, user.MustChangePassword)
+	return insertError(err)
+}
+
+func (r *sqlUserRepository) UpdateRole(ctx context.Context, id string, role string) error {
+	return requireAffected(r.db.ExecContext(ctx, "UPDATE users SET role = ?, token_version = token_version + 1 WHERE id = ?", role, id))
+}
+
+func (r *sqlUserRepository) UpdatePassword(ctx context.Context, id string, passwordHash string, mustChange bool) error {
+	return requireAffected(r.db.ExecContext(ctx, "UPDATE users SET password_hash = ?, must_change_password = ?, token_version = token_version + 1 WHERE id = ?",
+		passwordHash, mustChange, id))
+}
+
+func (r *sqlUserRepository) TheatreIDs(ctx context.Context, id string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT theatre_id FROM theatre_members WHERE user_id = ? ORDER BY theatre_id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var theatreIDs []string
+	for rows.Next() {
+		var theatreID string
+		if err := rows.Scan(&theatreID); err != nil {
+			return nil, err
+		}
+		theatreIDs = append(theatreIDs, theatreID)
+	}
+	return theatreIDs, rows.Err()
+}
+
+func (r *sqlUserRepository) SetTheatres(ctx context.Context, id string, theatreIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Bumping the token version first also tells whether the user exists
+	if err := requireAffected(tx.ExecContext(ctx, "UPDATE users SET token_version = token_version + 1 WHERE id = ?", id)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM theatre_members WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	for _, theatreID := range theatreIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO theatre_members(user_id, theatre_id) VALUES(?, ?)", id, theatreID); err != nil {
+			return insertError(err)
+		}
+	}
+
+	return tx.Commit()
+}
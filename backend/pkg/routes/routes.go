@@ -3,57 +3,106 @@ package routes
 import (
 	"algoBharat/backend/pkg/handlers"
 	"algoBharat/backend/pkg/middleware"
+	"algoBharat/backend/pkg/services"
 
 	"github.com/gorilla/mux"
 )
 
-func RegisterRoutes(r *mux.Router, movieHandler *handlers.MovieHandler, theatreHandler *handlers.TheatreHandler, hallHandler *handlers.HallHandler, showHandler *handlers.ShowHandler, bookingHandler *handlers.BookingHandler, analyticsHandler *handlers.AnalyticsHandler, userHandler *handlers.UserHandler) {
+func RegisterRoutes(r *mux.Router, authenticator middleware.Authenticator, movieHandler *handlers.MovieHandler, theatreHandler *handlers.TheatreHandler, hallHandler *handlers.HallHandler, showHandler *handlers.ShowHandler, bookingHandler *handlers.BookingHandler, seatHoldHandler *handlers.SeatHoldHandler, analyticsHandler *handlers.AnalyticsHandler, userHandler *handlers.UserHandler) {
 
 	// --- Public Routes --- (No authentication required)
 	// Anyone can register or log in.
 	r.HandleFunc("/register", userHandler.Register).Methods("POST")
 	r.HandleFunc("/login", userHandler.Login).Methods("POST")
+	r.HandleFunc("/token/refresh", userHandler.RefreshToken).Methods("POST") // Exchanges a refresh token for new tokens
+	r.HandleFunc("/password-policy", userHandler.GetPasswordPolicy).Methods("GET")
+	r.HandleFunc("/password/reset", userHandler.ResetPassword).Methods("POST") // Sets a new password with an admin-issued reset token
+	r.HandleFunc("/.well-known/jwks.json", userHandler.GetJWKS).Methods("GET") // Public keys other services verify access tokens with
 
-	// Anyone can view movies, theatres, halls, and shows.
-	r.HandleFunc("/movies", movieHandler.GetMovies).Methods("GET")
-	r.HandleFunc("/movies/{id}", movieHandler.GetMovie).Methods("GET")
-	r.HandleFunc("/theatres", theatreHandler.GetTheatres).Methods("GET")
-	r.HandleFunc("/theatres/{id}", theatreHandler.GetTheatre).Methods("GET")
-	r.HandleFunc("/halls", hallHandler.GetHalls).Methods("GET")
-	r.HandleFunc("/halls/{id}", hallHandler.GetHall).Methods("GET")
-	r.HandleFunc("/halls/{id}/seats", hallHandler.GetHallSeats).Methods("GET")
-	r.HandleFunc("/shows", showHandler.GetShows).Methods("GET")
+	// Anyone can view movies, theatres, halls, and shows. Archived ones are hidden, except
+	// from users with archive:view who ask for them with includeArchived=true.
+	catalogueRouter := r.PathPrefix("/").Subrouter()
+	catalogueRouter.Use(middleware.OptionalAuthMiddleware(authenticator))
+	catalogueRouter.HandleFunc("/movies", movieHandler.GetMovies).Methods("GET")
+	catalogueRouter.HandleFunc("/movies/{id}", movieHandler.GetMovie).Methods("GET")
+	catalogueRouter.HandleFunc("/theatres", theatreHandler.GetTheatres).Methods("GET")
+	catalogueRouter.HandleFunc("/theatres/{id}", theatreHandler.GetTheatre).Methods("GET")
+	catalogueRouter.HandleFunc("/halls", hallHandler.GetHalls).Methods("GET")
+	catalogueRouter.HandleFunc("/halls/layout-limits", hallHandler.GetLayoutLimits).Methods("GET") // Must be registered before /halls/{id}
+	catalogueRouter.HandleFunc("/halls/{id}", hallHandler.GetHall).Methods("GET")
+	catalogueRouter.HandleFunc("/halls/{id}/seats", hallHandler.GetHallSeats).Methods("GET")
+	catalogueRouter.HandleFunc("/shows", showHandler.GetShows).Methods("GET")
+	catalogueRouter.HandleFunc("/shows/{id}", showHandler.GetShow).Methods("GET")
+	catalogueRouter.HandleFunc("/shows/{id}/seats", showHandler.GetShowSeats).Methods("GET")
 
 	// --- Authenticated Routes --- (Requires a valid token, any role)
+	// Users who must change their password can do that and log out, and nothing else.
+	// Logging out revokes the caller's tokens.
+	passwordRouter := r.PathPrefix("/").Subrouter()
+	passwordRouter.Use(middleware.PasswordChangeAuthMiddleware(authenticator))
+	passwordRouter.HandleFunc("/logout", userHandler.Logout).Methods("POST")
+	passwordRouter.HandleFunc("/me/password", userHandler.ChangePassword).Methods("PUT")
+
 	authRouter := r.PathPrefix("/").Subrouter()
-	authRouter.Use(middleware.AuthMiddleware)
+	authRouter.Use(middleware.AuthMiddleware(authenticator))
+	authRouter.HandleFunc("/me/bookings", bookingHandler.GetMyBookings).Methods("GET")
+	authRouter.HandleFunc("/bookings/{id}", bookingHandler.CancelBooking).Methods("DELETE") // Owner, or anyone with bookings:cancel
+
+	// --- Permission Routes --- (Requires a valid token whose role grants the permission)
+	// requires returns a subrouter for routes that need every one of permissions.
+	requires := func(permissions ...services.Permission) *mux.Router {
+		router := r.PathPrefix("/").Subrouter()
+		router.Use(middleware.AuthMiddleware(authenticator), middleware.RequirePermission(permissions...))
+		return router
+	}
+
+	// Customers and staff can hold seats before checkout, then confirm or release the hold, and book seats.
+	bookingRouter := requires(services.PermBookSeats)
+	bookingRouter.HandleFunc("/bookings", bookingHandler.CreateBooking).Methods("POST")
+	bookingRouter.HandleFunc("/holds", seatHoldHandler.CreateHold).Methods("POST")
+	bookingRouter.HandleFunc("/holds/{id}", seatHoldHandler.GetHold).Methods("GET")
+	bookingRouter.HandleFunc("/holds/{id}", seatHoldHandler.ReleaseHold).Methods("DELETE")
+	bookingRouter.HandleFunc("/holds/{id}/confirm", seatHoldHandler.ConfirmHold).Methods("POST")
 
-	// Only logged-in users can create a booking.
-	authRouter.HandleFunc("/bookings", bookingHandler.CreateBooking).Methods("POST")
-	authRouter.HandleFunc("/bookings", bookingHandler.GetBookings).Methods("GET") // Added GET /bookings
+	// Box office staff and up can list every booking for a show of their theatres.
+	requires(services.PermViewBookings).HandleFunc("/bookings", bookingHandler.GetBookings).Methods("GET")
 
-	// --- Admin Routes --- (Requires a valid token with 'admin' role)
-	adminRouter := r.PathPrefix("/").Subrouter()
-	adminRouter.Use(middleware.AuthMiddleware, middleware.AdminOnlyMiddleware)
+	// Admins maintain movies and theatres.
+	movieRouter := requires(services.PermManageMovies)
+	movieRouter.HandleFunc("/movies", movieHandler.CreateMovie).Methods("POST")
+	movieRouter.HandleFunc("/movies/{id}", movieHandler.UpdateMovie).Methods("PUT")
+	movieRouter.HandleFunc("/movies/{id}", movieHandler.DeleteMovie).Methods("DELETE") // Archives unless permanent=true
+	movieRouter.HandleFunc("/movies/{id}/restore", movieHandler.RestoreMovie).Methods("POST")
 
-	// Only admins can create, update, or delete movies, theatres, halls, and shows.
-	adminRouter.HandleFunc("/movies", movieHandler.CreateMovie).Methods("POST")
-	adminRouter.HandleFunc("/movies/{id}", movieHandler.UpdateMovie).Methods("PUT")
-	adminRouter.HandleFunc("/movies/{id}", movieHandler.DeleteMovie).Methods("DELETE")
+	theatreRouter := requires(services.PermManageTheatres)
+	theatreRouter.HandleFunc("/theatres", theatreHandler.CreateTheatre).Methods("POST")
+	theatreRouter.HandleFunc("/theatres/{id}", theatreHandler.UpdateTheatre).Methods("PUT")
+	theatreRouter.HandleFunc("/theatres/{id}", theatreHandler.DeleteTheatre).Methods("DELETE") // Archives unless permanent=true
+	theatreRouter.HandleFunc("/theatres/{id}/restore", theatreHandler.RestoreTheatre).Methods("POST")
 
-	adminRouter.HandleFunc("/theatres", theatreHandler.CreateTheatre).Methods("POST")
-	adminRouter.HandleFunc("/theatres/{id}", theatreHandler.UpdateTheatre).Methods("PUT")
-	adminRouter.HandleFunc("/theatres/{id}", theatreHandler.DeleteTheatre).Methods("DELETE")
+	// Theatre managers maintain the halls and shows of their theatres; admins those of every theatre.
+	hallRouter := requires(services.PermManageHalls)
+	hallRouter.HandleFunc("/halls", hallHandler.CreateHall).Methods("POST")
+	hallRouter.HandleFunc("/halls/{id}", hallHandler.UpdateHall).Methods("PUT")
+	hallRouter.HandleFunc("/halls/{id}", hallHandler.DeleteHall).Methods("DELETE") // Archives unless permanent=true
+	hallRouter.HandleFunc("/halls/{id}/restore", hallHandler.RestoreHall).Methods("POST")
 
-	adminRouter.HandleFunc("/halls", hallHandler.CreateHall).Methods("POST")
-	adminRouter.HandleFunc("/halls/{id}", hallHandler.UpdateHall).Methods("PUT")
-	adminRouter.HandleFunc("/halls/{id}", hallHandler.DeleteHall).Methods("DELETE")
-	adminRouter.HandleFunc("/shows", showHandler.CreateShow).Methods("POST")
+	showRouter := requires(services.PermManageShows)
+	showRouter.HandleFunc("/shows", showHandler.CreateShow).Methods("POST")
+	showRouter.HandleFunc("/shows/{id}", showHandler.UpdateShow).Methods("PUT")
+	showRouter.HandleFunc("/shows/{id}", showHandler.DeleteShow).Methods("DELETE") // Archives unless permanent=true
+	showRouter.HandleFunc("/shows/{id}/restore", showHandler.RestoreShow).Methods("POST")
+	showRouter.HandleFunc("/shows/{id}/cancel", showHandler.CancelShow).Methods("POST")
 
-	// Only admins can view revenue analytics.
-	adminRouter.HandleFunc("/analytics/movies/{id}/revenue", analyticsHandler.GetMovieRevenue).Methods("GET")
+	// Theatre managers and admins can view revenue analytics, scoped to their theatres.
+	requires(services.PermViewAnalytics).HandleFunc("/analytics/movies/{id}/revenue", analyticsHandler.GetMovieRevenue).Methods("GET")
 
 	// Admin User Management Routes
-	adminRouter.HandleFunc("/users", userHandler.GetUsers).Methods("GET")
-	adminRouter.HandleFunc("/users/{id}/role", userHandler.UpdateUserRole).Methods("PUT")
+	userRouter := requires(services.PermManageUsers)
+	userRouter.HandleFunc("/users", userHandler.GetUsers).Methods("GET")
+	userRouter.HandleFunc("/users/{id}/role", userHandler.UpdateUserRole).Methods("PUT")
+	userRouter.HandleFunc("/users/{id}/theatres", userHandler.SetUserTheatres).Methods("PUT")
+	userRouter.HandleFunc("/users/{id}/password-reset", userHandler.IssuePasswordReset).Methods("POST")
+	userRouter.HandleFunc("/login-lockouts", userHandler.GetLoginLockouts).Methods("GET")
+	userRouter.HandleFunc("/login-lockouts", userHandler.ClearLoginLockout).Methods("DELETE")
 }
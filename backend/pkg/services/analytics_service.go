@@ -1,6 +1,11 @@
 package services
 
+import "context"
+
 // AnalyticsService defines the interface for analytics-related business logic.
 type AnalyticsService interface {
-	GetMovieRevenue(movieID string) (float64, error)
+	// GetMovieRevenue sums the confirmed bookings of every show of a movie. Archived movies
+	// and shows still count, so revenue history survives archiving. Staff only see the revenue
+	// of the theatres they are members of.
+	GetMovieRevenue(ctx context.Context, movieID string) (float64, error)
 }
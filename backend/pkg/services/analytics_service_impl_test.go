@@ -0,0 +1,87 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetMovieRevenue(t *testing.T) {
+	tests := []struct {
+		name     string
+		bookings []models.Booking
+		scope    func(f *testFixture) TheatreScope
+		want     float64
+	}{
+		{
+			name:     "per-seat totals",
+			bookings: []models.Booking{{TotalPrice: 250, SeatIDs: []string{"1-1-1", "1-1-2"}}},
+			want:     250,
+		},
+		{
+			name:     "legacy booking priced at its unit price",
+			bookings: []models.Booking{{UnitPrice: 80, SeatIDs: []string{"1-1-1", "1-1-2"}}},
+			want:     160,
+		},
+		{
+			name:     "legacy booking priced at the show price",
+			bookings: []models.Booking{{SeatIDs: []string{"1-1-1", "1-1-2", "1-2-1"}}},
+			want:     300,
+		},
+		{
+			name:     "legacy booking without seats",
+			bookings: []models.Booking{{UnitPrice: 80}},
+			want:     0,
+		},
+		{
+			name: "cancelled bookings left out",
+			bookings: []models.Booking{
+				{TotalPrice: 100, SeatIDs: []string{"1-1-1"}},
+				{TotalPrice: 100, SeatIDs: []string{"1-1-2"}, Status: models.BookingStatusCancelled},
+			},
+			want: 100,
+		},
+		{
+			name:     "theatre in scope",
+			bookings: []models.Booking{{TotalPrice: 100, SeatIDs: []string{"1-1-1"}}},
+			scope:    func(f *testFixture) TheatreScope { return TheatreScope{TheatreIDs: []string{f.hall.TheatreID}} },
+			want:     100,
+		},
+		{
+			name:     "theatre out of scope",
+			bookings: []models.Booking{{TotalPrice: 100, SeatIDs: []string{"1-1-1"}}},
+			scope:    func(f *testFixture) TheatreScope { return TheatreScope{TheatreIDs: []string{"another-theatre"}} },
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, "2030-01-01T10:00:00Z")
+			for i, booking := range tt.bookings {
+				booking.ID = show.ID + "-" + string(rune('a'+i))
+				booking.ShowID = show.ID
+				if booking.Status == "" {
+					booking.Status = models.BookingStatusConfirmed
+				}
+				if err := f.repos.Bookings.Create(f.ctx, booking, time.Now().UTC()); err != nil {
+					t.Fatalf("storing booking: %v", err)
+				}
+			}
+
+			ctx := f.ctx
+			if tt.scope != nil {
+				ctx = WithTheatreScope(context.Background(), tt.scope(f))
+			}
+			revenue, err := NewAnalyticsService(f.repos.Bookings).GetMovieRevenue(ctx, f.movie.ID)
+			if err != nil {
+				t.Fatalf("GetMovieRevenue() error = %v", err)
+			}
+			if revenue != tt.want {
+				t.Errorf("GetMovieRevenue() = %v, want %v", revenue, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"fmt"
+	"time"
+)
+
+// ErrArchived is returned when acting on, or under, a record that has been archived.
+type ErrArchived struct {
+	Entity string
+}
+
+func (e *ErrArchived) Error() string {
+	return fmt.Sprintf("%s is archived; restore it first", e.Entity)
+}
+
+func (e *ErrArchived) Kind() ErrorKind { return KindConflict }
+func (e *ErrArchived) Code() string    { return "archived" }
+
+// archiveTime returns the timestamp to archive records with. It is truncated to the second
+// so it compares equal after a round trip through any database, which is how restoring finds
+// the records archived together.
+func archiveTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// refuseActiveBookings stops an archive or delete while upcoming shows it affects have
+// confirmed bookings; those shows must be cancelled first.
+func refuseActiveBookings(summary models.DeletionSummary) error {
+	if summary.ActiveBookings > 0 {
+		return &ErrHasActiveBookings{Summary: summary}
+	}
+	return nil
+}
+
+// checkNotArchived returns ErrArchived if a record was found and is archived. Missing records
+// are left for the caller, or a foreign key, to report.
+func checkNotArchived(entity string, archivedAt *time.Time, err error) error {
+	switch err.(type) {
+	case nil:
+	case *ErrMovieNotFound, *ErrTheatreNotFound, *ErrHallNotFound:
+		return nil
+	default:
+		return err
+	}
+	if archivedAt != nil {
+		return &ErrArchived{Entity: entity}
+	}
+	return nil
+}
@@ -1,21 +1,40 @@
 package services
 
-import "algoBharat/backend/pkg/models"
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+)
 
 // BookingRequest represents the user's request to book seats.
+// ShowID identifies the show directly; when it is empty the show is resolved
+// from the MovieID, HallID and Time tuple instead. When SeatIDs is set, exactly
+// those seats are booked and NumSeats is ignored. Otherwise Category, when set,
+// restricts the automatically chosen seats to that seat category.
 type BookingRequest struct {
-	MovieID  string `json:"movieId"`
-	HallID   string `json:"hallId"`
-	Time     string `json:"time"`
-	NumSeats int    `json:"numSeats"`
+	ShowID   string   `json:"showId"`
+	MovieID  string   `json:"movieId"`
+	HallID   string   `json:"hallId"`
+	Time     string   `json:"time"`
+	NumSeats int      `json:"numSeats"`
+	SeatIDs  []string `json:"seatIds"`
+	Category string   `json:"category"`
+	// UserID is the authenticated user making the request; it is set by the handler, never from the body.
+	UserID string `json:"-"`
 }
 
 // BookingService defines the interface for booking-related business logic.
 type BookingService interface {
-	// CreateBooking attempts to find and book a contiguous block of seats.
-	CreateBooking(request BookingRequest) (models.Booking, error)
-	// FindAlternativeShows finds other shows on the same day with enough consecutive seats.
-	FindAlternativeShows(originalTime string, numSeats int) ([]models.Show, error)
-	// GetBookingsByShowID retrieves all bookings for a specific show.
-	GetBookingsByShowID(showID string) ([]models.Booking, error)
+	// CreateBooking books the requested seats, or finds and books a contiguous block of seats.
+	CreateBooking(ctx context.Context, request BookingRequest) (models.Booking, error)
+	// FindAlternativeShows finds other shows on the same day with enough consecutive seats,
+	// of the given category when category is not empty.
+	FindAlternativeShows(ctx context.Context, originalTime string, numSeats int, category string) ([]models.Show, error)
+	// GetBookingsByShowID retrieves all bookings for a specific show of the caller's theatres.
+	GetBookingsByShowID(ctx context.Context, showID string) ([]models.Booking, error)
+	// GetBookingsByUserID retrieves a user's bookings with their show, movie, hall and theatre details.
+	GetBookingsByUserID(ctx context.Context, userID string) ([]models.BookingDetails, error)
+	// CancelBooking releases a booking's seats and marks it cancelled. Only the booking's
+	// owner may cancel, unless cancelAny is set, and not within the cancellation cutoff before
+	// the show.
+	CancelBooking(ctx context.Context, bookingID string, userID string, cancelAny bool) (models.Booking, error)
 }
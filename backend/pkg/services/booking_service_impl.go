@@ -1,223 +1,330 @@
 package services
 
 import (
-	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/models"
-	"database/sql"
-	"encoding/json"
+	"algoBharat/backend/pkg/repository"
+	"context"
 	"fmt"
-	"github.com/go-sql-driver/mysql"
 	"log"
-	"math/rand"
-	"sort"
-	"strconv"
+	"strings"
 	"time"
 )
 
 // ErrSeatsAlreadyBooked is a custom error type for when seats are already booked.
-type ErrSeatsAlreadyBooked struct{}
+// SeatIDs names the requested seats that were already taken or held by another customer.
+type ErrSeatsAlreadyBooked struct {
+	SeatIDs []string
+}
 
 func (e *ErrSeatsAlreadyBooked) Error() string {
-	return "one or more seats are already booked"
+	if len(e.SeatIDs) == 0 {
+		return "one or more seats are already booked"
+	}
+	return fmt.Sprintf("seats already booked or held: %s", strings.Join(e.SeatIDs, ", "))
+}
+
+func (e *ErrSeatsAlreadyBooked) Kind() ErrorKind { return KindConflict }
+func (e *ErrSeatsAlreadyBooked) Code() string    { return "seats_unavailable" }
+
+func (e *ErrSeatsAlreadyBooked) Details() map[string]interface{} {
+	return map[string]interface{}{"seat_ids": e.SeatIDs}
+}
+
+// ErrInvalidSeats is returned when requested seat IDs do not exist in the hall's seat map.
+type ErrInvalidSeats struct {
+	SeatIDs []string
+}
+
+func (e *ErrInvalidSeats) Error() string {
+	return fmt.Sprintf("seats do not exist in this hall: %s", strings.Join(e.SeatIDs, ", "))
+}
+
+func (e *ErrInvalidSeats) Kind() ErrorKind { return KindValidation }
+func (e *ErrInvalidSeats) Code() string    { return "invalid_seats" }
+
+func (e *ErrInvalidSeats) FieldErrors() []FieldError {
+	return []FieldError{{Field: "seatIds", Message: e.Error()}}
+}
+
+func (e *ErrInvalidSeats) Details() map[string]interface{} {
+	return map[string]interface{}{"seat_ids": e.SeatIDs}
+}
+
+// ErrUnknownSeatCategory is returned when a booking asks for a seat category the show's hall does not have.
+type ErrUnknownSeatCategory struct {
+	Category string
+}
+
+func (e *ErrUnknownSeatCategory) Error() string {
+	return fmt.Sprintf("this hall has no %q seats", e.Category)
+}
+
+func (e *ErrUnknownSeatCategory) Kind() ErrorKind { return KindValidation }
+func (e *ErrUnknownSeatCategory) Code() string    { return "unknown_seat_category" }
+
+func (e *ErrUnknownSeatCategory) FieldErrors() []FieldError {
+	return []FieldError{{Field: "category", Message: e.Error()}}
 }
 
 // ErrNoContiguousSeats is a custom error type for when no contiguous seats are available.
-type ErrNoContiguousSeats struct{}
+// ShowTime carries the time of the requested show so callers can search for alternatives.
+type ErrNoContiguousSeats struct {
+	ShowTime string
+}
 
 func (e *ErrNoContiguousSeats) Error() string {
 	return "no contiguous seats available for the requested show"
 }
 
-type BookingServiceImpl struct{}
+func (e *ErrNoContiguousSeats) Kind() ErrorKind { return KindConflict }
+func (e *ErrNoContiguousSeats) Code() string    { return "no_contiguous_seats" }
 
-func (s *BookingServiceImpl) CreateBooking(request BookingRequest) (models.Booking, error) {
-	// 1. Parse the request time
-	requestTime, err := time.Parse(time.RFC3339, request.Time)
-	if err != nil {
-		formats := []string{
-			"2006-01-02T15:04:05Z",
-			"2006-01-02T15:04:05.000Z",
-			"2006-01-02 15:04:05",
-			"2006-01-02T15:04:05+05:30",
-		}
-		for _, format := range formats {
-			if requestTime, err = time.Parse(format, request.Time); err == nil {
-				break
-			}
-		}
-		if err != nil {
-			return models.Booking{}, fmt.Errorf("invalid time format: %w", err)
-		}
-	}
-	requestTime = requestTime.UTC()
+// ErrShowNotFound is returned when a show does not exist, or no show matches a booking request.
+type ErrShowNotFound struct{}
 
-	// 2. Find target show
-	var targetShow models.Show
-	// we should also be sending the time to get that particular show
-	row := database.DB.QueryRow("SELECT id, movie_id, hall_id, time FROM shows WHERE movie_id = ? AND hall_id = ?", request.MovieID, request.HallID)
-	if err := row.Scan(&targetShow.ID, &targetShow.MovieID, &targetShow.HallID, &targetShow.Time); err != nil {
-		if err == sql.ErrNoRows {
-			return models.Booking{}, fmt.Errorf("no show found for the given movie and hall")
-		}
+func (e *ErrShowNotFound) Error() string {
+	return "show not found"
+}
+
+func (e *ErrShowNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrShowNotFound) Code() string    { return "show_not_found" }
+
+// ErrAmbiguousShow is returned when the movie, hall and time tuple matches more than one show.
+type ErrAmbiguousShow struct {
+	ShowIDs []string
+}
+
+func (e *ErrAmbiguousShow) Error() string {
+	return fmt.Sprintf("multiple shows match the given movie, hall, and time (%s); please specify a showId", strings.Join(e.ShowIDs, ", "))
+}
+
+func (e *ErrAmbiguousShow) Kind() ErrorKind { return KindConflict }
+func (e *ErrAmbiguousShow) Code() string    { return "ambiguous_show" }
+
+func (e *ErrAmbiguousShow) Details() map[string]interface{} {
+	return map[string]interface{}{"show_ids": e.ShowIDs}
+}
+
+// ErrBookingNotFound is returned when a booking does not exist.
+type ErrBookingNotFound struct{}
+
+func (e *ErrBookingNotFound) Error() string {
+	return "booking not found"
+}
+
+func (e *ErrBookingNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrBookingNotFound) Code() string    { return "booking_not_found" }
+
+// ErrNotBookingOwner is returned when a user tries to act on someone else's booking.
+type ErrNotBookingOwner struct{}
+
+func (e *ErrNotBookingOwner) Error() string {
+	return "only the booking's owner or box office staff can do this"
+}
+
+func (e *ErrNotBookingOwner) Kind() ErrorKind { return KindForbidden }
+func (e *ErrNotBookingOwner) Code() string    { return "not_booking_owner" }
+
+// ErrBookingAlreadyCancelled is returned when cancelling a booking twice.
+type ErrBookingAlreadyCancelled struct{}
+
+func (e *ErrBookingAlreadyCancelled) Error() string {
+	return "booking is already cancelled"
+}
+
+func (e *ErrBookingAlreadyCancelled) Kind() ErrorKind { return KindConflict }
+func (e *ErrBookingAlreadyCancelled) Code() string    { return "booking_already_cancelled" }
+
+// ErrCancellationClosed is returned when a booking is cancelled within the cutoff before show time.
+type ErrCancellationClosed struct {
+	Cutoff time.Duration
+}
+
+func (e *ErrCancellationClosed) Error() string {
+	return fmt.Sprintf("bookings cannot be cancelled less than %s before the show", e.Cutoff)
+}
+
+func (e *ErrCancellationClosed) Kind() ErrorKind { return KindConflict }
+func (e *ErrCancellationClosed) Code() string    { return "cancellation_closed" }
+
+const defaultCancellationCutoff = 2 * time.Hour
+
+// getCancellationCutoff returns how long before show time cancellations close, from the BOOKING_CANCELLATION_CUTOFF environment variable
+func getCancellationCutoff() time.Duration {
+	return getDurationEnv("BOOKING_CANCELLATION_CUTOFF", defaultCancellationCutoff)
+}
+
+type BookingServiceImpl struct {
+	bookings    repository.BookingRepository
+	seatHolds   repository.SeatHoldRepository
+	shows       repository.ShowRepository
+	hallService HallService
+}
+
+// NewBookingService creates a BookingServiceImpl storing bookings in bookings. Seats under
+// another customer's hold in seatHolds are unavailable; shows and hallService resolve the
+// show and hall layout a booking is for.
+func NewBookingService(bookings repository.BookingRepository, seatHolds repository.SeatHoldRepository, shows repository.ShowRepository, hallService HallService) *BookingServiceImpl {
+	return &BookingServiceImpl{bookings: bookings, seatHolds: seatHolds, shows: shows, hallService: hallService}
+}
+
+func (s *BookingServiceImpl) CreateBooking(ctx context.Context, request BookingRequest) (models.Booking, error) {
+	if err := validateBookingRequest(request); err != nil {
 		return models.Booking{}, err
 	}
 
-	showTime, err := time.Parse(time.RFC3339, targetShow.Time)
+	// 1. Resolve the target show
+	targetShow, err := resolveShow(ctx, s.shows, request)
 	if err != nil {
-		return models.Booking{}, fmt.Errorf("invalid show time format in database: %w", err)
-	}
-	showTime = showTime.UTC()
-
-	if !requestTime.Truncate(time.Minute).Equal(showTime.Truncate(time.Minute)) {
-		return models.Booking{}, fmt.Errorf("no show found for the given movie, hall, and time")
+		return models.Booking{}, err
 	}
 
-	// 3. Get Hall and prepare seats
-	hall, err := (&HallServiceImpl{}).GetHall(targetShow.HallID)
+	// 2. Get Hall and prepare seats
+	hall, err := s.hallService.GetHall(ctx, targetShow.HallID)
 	if err != nil {
 		return models.Booking{}, fmt.Errorf("could not get hall %s: %w", targetShow.HallID, err)
 	}
 
-	seatsByRow := make(map[int][]models.Seat)
-	for rowKeyStr, cols := range hall.SeatMap {
-		rowNum, _ := strconv.Atoi(rowKeyStr)
-		for colIndex, numSeats := range cols {
-			for i := 1; i <= numSeats; i++ {
-				seatID := fmt.Sprintf("%d-%d-%d", rowNum, colIndex+1, i)
-				seatsByRow[rowNum] = append(seatsByRow[rowNum], models.Seat{
-					ID:     seatID,
-					Row:    rowNum,
-					Column: colIndex + 1,
-					Number: i,
-					HallID: hall.ID,
-				})
-			}
-		}
-	}
-
-	for rowNum := range seatsByRow {
-		sort.Slice(seatsByRow[rowNum], func(i, j int) bool {
-			if seatsByRow[rowNum][i].Column != seatsByRow[rowNum][j].Column {
-				return seatsByRow[rowNum][i].Column < seatsByRow[rowNum][j].Column
-			}
-			return seatsByRow[rowNum][i].Number < seatsByRow[rowNum][j].Number
-		})
+	unavailableSeatIDs, err := getUnavailableSeatIDs(ctx, s.bookings, s.seatHolds, targetShow.ID, request.UserID)
+	if err != nil {
+		return models.Booking{}, fmt.Errorf("could not get booked seats for show %s: %w", targetShow.ID, err)
 	}
 
-	bookedSeatIDs, err := s.getBookedSeatIDsForShow(targetShow.ID)
+	seatsToBook, err := chooseSeats(hall, unavailableSeatIDs, request, targetShow.Time)
 	if err != nil {
-		return models.Booking{}, fmt.Errorf("could not get booked seats for show %s: %w", targetShow.ID, err)
+		return models.Booking{}, err
 	}
 
-	var seatsToBook []models.Seat
-	foundBlock := false
+	// 3. Store the booking; the repository claims its seats atomically, rechecking them against
+	// holds, and uses up the customer's own hold on any of them
+	newBooking := newPricedBooking(targetShow, request.UserID, seatsToBook)
 
-	var rowNums []int
-	for rNum := range seatsByRow {
-		rowNums = append(rowNums, rNum)
+	_, err = insertWithNewID(func(id string) error {
+		newBooking.ID = id
+		return s.bookings.Create(ctx, newBooking, time.Now().UTC())
+	})
+	if err != nil {
+		return models.Booking{}, fromRepository(err, nil)
 	}
-	sort.Ints(rowNums)
-
-	for _, rowNum := range rowNums {
-		seatsInRow := seatsByRow[rowNum]
 
-		currentContiguousCount := 0
-		potentialBlock := []models.Seat{}
+	return newBooking, nil
+}
 
-		for _, seat := range seatsInRow {
-			if bookedSeatIDs[seat.ID] {
-				currentContiguousCount = 0
-				potentialBlock = []models.Seat{}
-			} else {
-				if currentContiguousCount > 0 {
-					prevSeat := potentialBlock[len(potentialBlock)-1]
-					isContiguous := (seat.Column == prevSeat.Column && seat.Number == prevSeat.Number+1)
-					if !isContiguous {
-						currentContiguousCount = 0
-						potentialBlock = []models.Seat{}
-					}
-				}
+// newPricedBooking builds a confirmed booking of seats for show, pricing each seat by its category.
+// Its ID is assigned when it is stored.
+func newPricedBooking(show models.Show, userID string, seats []models.Seat) models.Booking {
+	booking := models.Booking{
+		ShowID:    show.ID,
+		UserID:    userID,
+		SeatIDs:   make([]string, len(seats)),
+		UnitPrice: show.Price,
+		Seats:     make([]models.BookedSeat, len(seats)),
+		Status:    models.BookingStatusConfirmed,
+	}
+	for i, seat := range seats {
+		price := seatPrice(show, seat.Category)
+		booking.SeatIDs[i] = seat.ID
+		booking.Seats[i] = models.BookedSeat{SeatID: seat.ID, Category: seat.Category, Price: price}
+		booking.TotalPrice += price
+	}
+	return booking
+}
 
-				currentContiguousCount++
-				potentialBlock = append(potentialBlock, seat)
+// chooseSeats picks the seats of hall a request asks for: exactly the requested SeatIDs, or
+// else the first contiguous block of NumSeats free seats, of the requested Category if any.
+// showTime is reported in ErrNoContiguousSeats so callers can search for alternatives.
+func chooseSeats(hall models.Hall, unavailableSeatIDs map[string]bool, request BookingRequest, showTime string) ([]models.Seat, error) {
+	seatsByRow := buildSeatsByRow(hall)
 
-				if currentContiguousCount >= request.NumSeats {
-					seatsToBook = potentialBlock[:request.NumSeats]
-					foundBlock = true
-					break
-				}
+	if len(request.SeatIDs) > 0 {
+		seatIDs, err := selectRequestedSeats(seatsByRow, unavailableSeatIDs, request.SeatIDs)
+		if err != nil {
+			return nil, err
+		}
+		seatsByID := make(map[string]models.Seat)
+		for _, seats := range seatsByRow {
+			for _, seat := range seats {
+				seatsByID[seat.ID] = seat
 			}
 		}
-		if foundBlock {
-			break
+		seats := make([]models.Seat, len(seatIDs))
+		for i, seatID := range seatIDs {
+			seats[i] = seatsByID[seatID]
 		}
+		return seats, nil
 	}
 
-	if !foundBlock {
-		return models.Booking{}, &ErrNoContiguousSeats{}
-	}
-
-	seatIDsToBook := make([]string, len(seatsToBook))
-	for i, seat := range seatsToBook {
-		seatIDsToBook[i] = seat.ID
-	}
-
-	// 4. Transactional booking with seat_ids and new booked_seats
-	tx, err := database.DB.Begin()
-	if err != nil {
-		return models.Booking{}, err
+	if request.Category != "" {
+		seatsByRow = filterSeatsByCategory(seatsByRow, request.Category)
+		if len(seatsByRow) == 0 {
+			return nil, &ErrUnknownSeatCategory{Category: request.Category}
+		}
 	}
-	defer tx.Rollback()
 
-	newBooking := models.Booking{
-		ID:      strconv.Itoa(rand.Intn(1000000)),
-		ShowID:  targetShow.ID,
-		SeatIDs: seatIDsToBook,
+	seats := findContiguousBlock(seatsByRow, unavailableSeatIDs, request.NumSeats)
+	if seats == nil {
+		return nil, &ErrNoContiguousSeats{ShowTime: showTime}
 	}
+	return seats, nil
+}
 
-	seatIDsBytes, _ := json.Marshal(newBooking.SeatIDs)
-	seatIDsStr := string(seatIDsBytes)
-
-	// Insert booking with seat_ids
-	stmtBooking, err := tx.Prepare("INSERT INTO bookings(id, show_id, seat_ids) VALUES(?, ?, ?)")
-	if err != nil {
-		return models.Booking{}, err
+// resolveShow finds the show a booking request refers to, either directly by ShowID
+// or by matching the movie, hall and time among all shows of that movie in that hall.
+func resolveShow(ctx context.Context, shows repository.ShowRepository, request BookingRequest) (models.Show, error) {
+	if request.ShowID != "" {
+		show, err := shows.Get(ctx, request.ShowID)
+		if err != nil {
+			return models.Show{}, fromRepository(err, &ErrShowNotFound{})
+		}
+		if show.ArchivedAt != nil {
+			return models.Show{}, &ErrShowNotFound{}
+		}
+		if show.Status == models.ShowStatusCancelled {
+			return models.Show{}, &ErrShowCancelled{}
+		}
+		return show, nil
 	}
-	defer stmtBooking.Close()
 
-	_, err = stmtBooking.Exec(newBooking.ID, newBooking.ShowID, seatIDsStr)
+	requestTime, err := parseBookingTime(request.Time)
 	if err != nil {
-		return models.Booking{}, err
+		return models.Show{}, err
 	}
 
-	// Insert booked_seats with atomic constraint
-	stmtSeat, err := tx.Prepare("INSERT INTO booked_seats(show_id, seat_id, booking_id) VALUES(?, ?, ?)")
+	candidates, err := shows.List(ctx, repository.ShowFilter{MovieID: request.MovieID, HallID: request.HallID})
 	if err != nil {
-		return models.Booking{}, err
+		return models.Show{}, err
 	}
-	defer stmtSeat.Close()
 
-	for _, seatID := range seatIDsToBook {
-		_, err := stmtSeat.Exec(newBooking.ShowID, seatID, newBooking.ID)
+	var matches []models.Show
+	for _, candidate := range candidates {
+		showTime, err := time.Parse(time.RFC3339, candidate.Time)
 		if err != nil {
-			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
-				return models.Booking{}, fmt.Errorf("seat %s is already booked", seatID)
-			}
-			return models.Booking{}, err
+			log.Printf("Invalid time format for show %s: %v", candidate.ID, err)
+			continue
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return models.Booking{}, err
+		if requestTime.Truncate(time.Minute).Equal(showTime.UTC().Truncate(time.Minute)) {
+			matches = append(matches, candidate)
+		}
 	}
 
-	return newBooking, nil
+	switch len(matches) {
+	case 0:
+		return models.Show{}, &ErrShowNotFound{}
+	case 1:
+		return matches[0], nil
+	default:
+		showIDs := make([]string, len(matches))
+		for i, match := range matches {
+			showIDs[i] = match.ID
+		}
+		return models.Show{}, &ErrAmbiguousShow{ShowIDs: showIDs}
+	}
 }
 
-// FindAlternativeShows performs a global search for shows on the same day that have enough consecutive seats.
-func (s *BookingServiceImpl) FindAlternativeShows(originalTime string, numSeats int) ([]models.Show, error) {
-	// 1. Determine the date range for the same day.
-	parsedTime, err := time.Parse(time.RFC3339, originalTime)
+// parseBookingTime parses a client-supplied time in any of the accepted formats and returns it in UTC.
+func parseBookingTime(value string) (time.Time, error) {
+	parsedTime, err := time.Parse(time.RFC3339, value)
 	if err != nil {
 		formats := []string{
 			"2006-01-02T15:04:05Z",
@@ -226,175 +333,218 @@ func (s *BookingServiceImpl) FindAlternativeShows(originalTime string, numSeats
 			"2006-01-02T15:04:05+05:30",
 		}
 		for _, format := range formats {
-			if parsedTime, err = time.Parse(format, originalTime); err == nil {
+			if parsedTime, err = time.Parse(format, value); err == nil {
 				break
 			}
 		}
 		if err != nil {
-			return nil, fmt.Errorf("invalid time format: %w", err)
+			return time.Time{}, fmt.Errorf("invalid time format: %w", err)
 		}
 	}
+	return parsedTime.UTC(), nil
+}
+
+// FindAlternativeShows performs a global search for shows on the same day that have enough consecutive seats.
+func (s *BookingServiceImpl) FindAlternativeShows(ctx context.Context, originalTime string, numSeats int, category string) ([]models.Show, error) {
+	// 1. Determine the date range for the same day.
+	parsedTime, err := parseBookingTime(originalTime)
+	if err != nil {
+		return nil, err
+	}
 
-	parsedTime = parsedTime.UTC()
 	year, month, day := parsedTime.Date()
 	startOfDay := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	// 2. Get all shows within that day.
-	rows, err := database.DB.Query(
-		"SELECT id, movie_id, hall_id, time FROM shows WHERE time >= ? AND time < ?",
-		startOfDay.Format(time.RFC3339),
-		endOfDay.Format(time.RFC3339),
-	)
+	sameDayShows, err := s.shows.List(ctx, repository.ShowFilter{
+		From: startOfDay.Format(time.RFC3339),
+		To:   endOfDay.Format(time.RFC3339),
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var sameDayShows []models.Show
-	for rows.Next() {
-		var show models.Show
-		if err := rows.Scan(&show.ID, &show.MovieID, &show.HallID, &show.Time); err != nil {
-			log.Println(err)
-			continue
-		}
-		sameDayShows = append(sameDayShows, show)
-	}
 
 	// 3. Check each same-day show for consecutive available seats.
 	var alternatives []models.Show
 	for _, show := range sameDayShows {
-		hall, err := (&HallServiceImpl{}).GetHall(show.HallID)
+		// Stop scanning once the client has gone away or the request timed out
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		hall, err := s.hallService.GetHall(ctx, show.HallID)
 		if err != nil {
 			log.Printf("Could not get hall %s for show %s: %v", show.HallID, show.ID, err)
 			continue
 		}
 
-		seatsByRow := make(map[int][]models.Seat)
-		for rowKeyStr, cols := range hall.SeatMap {
-			rowNum, _ := strconv.Atoi(rowKeyStr)
-			for colIndex, numSeats := range cols {
-				for i := 1; i <= numSeats; i++ {
-					seatID := fmt.Sprintf("%d-%d-%d", rowNum, colIndex+1, i)
-					seatsByRow[rowNum] = append(seatsByRow[rowNum], models.Seat{
-						ID:     seatID,
-						Row:    rowNum,
-						Column: colIndex + 1,
-						Number: i,
-						HallID: hall.ID,
-					})
-				}
-			}
-		}
-
-		for rowNum := range seatsByRow {
-			sort.Slice(seatsByRow[rowNum], func(i, j int) bool {
-				if seatsByRow[rowNum][i].Column != seatsByRow[rowNum][j].Column {
-					return seatsByRow[rowNum][i].Column < seatsByRow[rowNum][j].Column
-				}
-				return seatsByRow[rowNum][i].Number < seatsByRow[rowNum][j].Number
-			})
+		seatsByRow := buildSeatsByRow(hall)
+		if category != "" {
+			seatsByRow = filterSeatsByCategory(seatsByRow, category)
 		}
 
-		// NEW: Consistently use booked_seats table
-		bookedSeatIDs, err := s.getBookedSeatIDsForShow(show.ID)
+		// Seats held by other customers count as unavailable alongside booked_seats
+		unavailableSeatIDs, err := getUnavailableSeatIDs(ctx, s.bookings, s.seatHolds, show.ID, "")
 		if err != nil {
 			log.Printf("Could not get booked seats for show %s: %v", show.ID, err)
 			continue
 		}
 
-		foundAlternative := false
-		var rowNums []int
-		for rNum := range seatsByRow {
-			rowNums = append(rowNums, rNum)
+		if findContiguousBlock(seatsByRow, unavailableSeatIDs, numSeats) != nil {
+			alternatives = append(alternatives, show)
 		}
-		sort.Ints(rowNums)
+	}
+
+	return alternatives, nil
+}
 
-		for _, rowNum := range rowNums {
-			seatsInRow := seatsByRow[rowNum]
+// findContiguousBlock returns the first block of numSeats adjacent free seats,
+// scanning rows in ascending order, or nil if there is none.
+func findContiguousBlock(seatsByRow map[int][]models.Seat, bookedSeatIDs map[string]bool, numSeats int) []models.Seat {
+	for _, rowNum := range sortedRowNums(seatsByRow) {
+		potentialBlock := []models.Seat{}
 
-			currentContiguousCount := 0
-			potentialBlock := []models.Seat{}
+		for _, seat := range seatsByRow[rowNum] {
+			if bookedSeatIDs[seat.ID] {
+				potentialBlock = []models.Seat{}
+				continue
+			}
 
-			for _, seat := range seatsInRow {
-				if bookedSeatIDs[seat.ID] {
-					currentContiguousCount = 0
+			if len(potentialBlock) > 0 {
+				prevSeat := potentialBlock[len(potentialBlock)-1]
+				isContiguous := (seat.Column == prevSeat.Column && seat.Number == prevSeat.Number+1)
+				if !isContiguous {
 					potentialBlock = []models.Seat{}
-				} else {
-					if currentContiguousCount > 0 {
-						prevSeat := potentialBlock[len(potentialBlock)-1]
-						isContiguous := (seat.Column == prevSeat.Column && seat.Number == prevSeat.Number+1)
-
-						if !isContiguous {
-							currentContiguousCount = 0
-							potentialBlock = []models.Seat{}
-						}
-					}
-
-					currentContiguousCount++
-					potentialBlock = append(potentialBlock, seat)
-
-					if currentContiguousCount >= numSeats {
-						foundAlternative = true
-						break
-					}
 				}
 			}
 
-			if foundAlternative {
-				alternatives = append(alternatives, show)
-				break
+			potentialBlock = append(potentialBlock, seat)
+			if len(potentialBlock) >= numSeats {
+				return potentialBlock[:numSeats]
 			}
 		}
 	}
 
-	return alternatives, nil
+	return nil
 }
 
-// getBookedSeatIDsForShow is a helper to get all booked seat IDs for a given show.
-func (s *BookingServiceImpl) getBookedSeatIDsForShow(showID string) (map[string]bool, error) {
-	bookedMap := make(map[string]bool)
+// selectRequestedSeats validates explicitly requested seat IDs against the hall layout and
+// the seats already booked for the show. It returns the de-duplicated seat IDs to book.
+func selectRequestedSeats(seatsByRow map[int][]models.Seat, bookedSeatIDs map[string]bool, requested []string) ([]string, error) {
+	validSeatIDs := make(map[string]bool)
+	for _, seats := range seatsByRow {
+		for _, seat := range seats {
+			validSeatIDs[seat.ID] = true
+		}
+	}
+
+	var seatIDs, invalid, taken []string
+	seen := make(map[string]bool)
+	for _, seatID := range requested {
+		if seen[seatID] {
+			continue
+		}
+		seen[seatID] = true
+
+		switch {
+		case !validSeatIDs[seatID]:
+			invalid = append(invalid, seatID)
+		case bookedSeatIDs[seatID]:
+			taken = append(taken, seatID)
+		default:
+			seatIDs = append(seatIDs, seatID)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, &ErrInvalidSeats{SeatIDs: invalid}
+	}
+	if len(taken) > 0 {
+		return nil, &ErrSeatsAlreadyBooked{SeatIDs: taken}
+	}
+	return seatIDs, nil
+}
 
-	// Prefer querying booked_seats directly to get atomic data
-	rows, err := database.DB.Query("SELECT seat_id FROM booked_seats WHERE show_id = ?", showID)
+// getUnavailableSeatIDs returns the seats of a show that cannot be booked by userID:
+// every booked seat plus every seat under an active hold owned by someone else.
+func getUnavailableSeatIDs(ctx context.Context, bookings repository.BookingRepository, seatHolds repository.SeatHoldRepository, showID string, userID string) (map[string]bool, error) {
+	unavailable, err := bookings.BookedSeatIDs(ctx, showID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var seatID string
-		if err := rows.Scan(&seatID); err != nil {
-			return nil, err
-		}
-		bookedMap[seatID] = true
+	heldSeatIDs, err := seatHolds.HeldSeatIDs(ctx, showID, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for seatID := range heldSeatIDs {
+		unavailable[seatID] = true
 	}
 
-	return bookedMap, nil
+	return unavailable, nil
 }
 
-// GetBookingsByShowID retrieves all bookings for a specific show.
-func (s *BookingServiceImpl) GetBookingsByShowID(showID string) ([]models.Booking, error) {
-	rows, err := database.DB.Query("SELECT id, show_id, seat_ids FROM bookings WHERE show_id = ?", showID)
+// GetBookingsByShowID retrieves all bookings for a specific show of the caller's theatres.
+func (s *BookingServiceImpl) GetBookingsByShowID(ctx context.Context, showID string) ([]models.Booking, error) {
+	show, err := s.shows.Get(ctx, showID)
 	if err != nil {
+		return nil, fromRepository(err, &ErrShowNotFound{})
+	}
+	if err := checkHallAccess(ctx, s.hallService, show.HallID); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return s.bookings.ListByShow(ctx, showID)
+}
 
-	var bookings []models.Booking
-	for rows.Next() {
-		var booking models.Booking
-		var seatIDsStr string
-		if err := rows.Scan(&booking.ID, &booking.ShowID, &seatIDsStr); err != nil {
-			return nil, err
-		}
+// GetBookingsByUserID retrieves a user's bookings, most recent show first.
+func (s *BookingServiceImpl) GetBookingsByUserID(ctx context.Context, userID string) ([]models.BookingDetails, error) {
+	return s.bookings.ListDetailsByUser(ctx, userID)
+}
 
-		var seatIDs []string
-		if err := json.Unmarshal([]byte(seatIDsStr), &seatIDs); err != nil {
-			return nil, err
+// CancelBooking releases the booking's seats and records the cancellation.
+func (s *BookingServiceImpl) CancelBooking(ctx context.Context, bookingID string, userID string, cancelAny bool) (models.Booking, error) {
+	booking, err := s.bookings.Get(ctx, bookingID)
+	if err != nil {
+		return models.Booking{}, fromRepository(err, &ErrBookingNotFound{})
+	}
+
+	if !cancelAny && booking.UserID != userID {
+		return models.Booking{}, &ErrNotBookingOwner{}
+	}
+	if booking.Status != models.BookingStatusConfirmed {
+		return models.Booking{}, &ErrBookingAlreadyCancelled{}
+	}
+
+	// Refuse cancellations too close to the show
+	show, err := s.shows.Get(ctx, booking.ShowID)
+	if err != nil && err != repository.ErrNotFound {
+		return models.Booking{}, err
+	}
+	if err == nil && booking.UserID != userID {
+		// Staff cancelling a customer's booking must belong to the show's theatre
+		if err := checkHallAccess(ctx, s.hallService, show.HallID); err != nil {
+			return models.Booking{}, err
+		}
+	}
+	if err == nil {
+		showTime, err := time.Parse(time.RFC3339, show.Time)
+		if err != nil {
+			return models.Booking{}, fmt.Errorf("invalid show time format in database: %w", err)
+		}
+		cutoff := getCancellationCutoff()
+		if time.Now().UTC().Add(cutoff).After(showTime.UTC()) {
+			return models.Booking{}, &ErrCancellationClosed{Cutoff: cutoff}
 		}
-		booking.SeatIDs = seatIDs
-		bookings = append(bookings, booking)
 	}
-	return bookings, nil
+
+	// The repository only cancels confirmed bookings, so a concurrent cancellation is reported here
+	cancelledAt := time.Now().UTC()
+	if err := s.bookings.Cancel(ctx, booking.ID, cancelledAt); err != nil {
+		return models.Booking{}, fromRepository(err, &ErrBookingAlreadyCancelled{})
+	}
+
+	booking.Status = models.BookingStatusCancelled
+	booking.CancelledAt = &cancelledAt
+	return booking, nil
 }
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"slices"
+	"testing"
+)
+
+func TestCreateBookingResolvesShow(t *testing.T) {
+	const showTime = "2030-01-01T10:00:00Z"
+
+	tests := []struct {
+		name string
+		// legacy stores a second show of the movie at the same time straight in the repository,
+		// as older databases may hold; the service itself refuses overlapping shows
+		legacy  bool
+		request func(f *testFixture, show models.Show) BookingRequest
+		wantErr error
+	}{
+		{
+			name: "by show ID",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{ShowID: show.ID}
+			},
+		},
+		{
+			name: "by movie, hall and time",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{MovieID: f.movie.ID, HallID: f.hall.ID, Time: showTime}
+			},
+		},
+		{
+			name: "by time in another accepted format",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{MovieID: f.movie.ID, HallID: f.hall.ID, Time: "2030-01-01 10:00:00"}
+			},
+		},
+		{
+			name: "by time within the same minute",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{MovieID: f.movie.ID, HallID: f.hall.ID, Time: "2030-01-01T10:00:45Z"}
+			},
+		},
+		{
+			name: "unknown show ID",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{ShowID: "no-such-show"}
+			},
+			wantErr: &ErrShowNotFound{},
+		},
+		{
+			name: "no show at that time",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{MovieID: f.movie.ID, HallID: f.hall.ID, Time: "2030-01-01T11:00:00Z"}
+			},
+			wantErr: &ErrShowNotFound{},
+		},
+		{
+			name: "no show in that hall",
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{MovieID: f.movie.ID, HallID: "no-such-hall", Time: showTime}
+			},
+			wantErr: &ErrShowNotFound{},
+		},
+		{
+			name:   "several shows at that time",
+			legacy: true,
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{MovieID: f.movie.ID, HallID: f.hall.ID, Time: showTime}
+			},
+			wantErr: &ErrAmbiguousShow{},
+		},
+		{
+			name:   "by show ID among several at that time",
+			legacy: true,
+			request: func(f *testFixture, show models.Show) BookingRequest {
+				return BookingRequest{ShowID: show.ID}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, showTime)
+			if tt.legacy {
+				duplicate := show
+				duplicate.ID = show.ID + "-duplicate"
+				if err := f.repos.Shows.Create(f.ctx, duplicate); err != nil {
+					t.Fatalf("storing duplicate show: %v", err)
+				}
+			}
+
+			request := tt.request(f, show)
+			request.NumSeats = 1
+			request.UserID = "alice"
+			booking, err := f.bookings.CreateBooking(f.ctx, request)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("CreateBooking() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && booking.ShowID != show.ID {
+				t.Errorf("CreateBooking() booked show %s, want %s", booking.ShowID, show.ID)
+			}
+		})
+	}
+}
+
+func TestCreateBookingRefusesUnbookableShows(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(f *testFixture, show models.Show) error
+		wantErr error
+	}{
+		{
+			name: "cancelled",
+			change: func(f *testFixture, show models.Show) error {
+				_, err := f.shows.CancelShow(f.ctx, show.ID)
+				return err
+			},
+			wantErr: &ErrShowCancelled{},
+		},
+		{
+			name:    "archived",
+			change:  func(f *testFixture, show models.Show) error { return f.shows.ArchiveShow(f.ctx, show.ID) },
+			wantErr: &ErrShowNotFound{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, "2030-01-01T10:00:00Z")
+			if err := tt.change(f, show); err != nil {
+				t.Fatalf("changing show: %v", err)
+			}
+
+			_, err := f.bookings.CreateBooking(f.ctx, BookingRequest{ShowID: show.ID, NumSeats: 1, UserID: "alice"})
+			if !sameError(err, tt.wantErr) {
+				t.Errorf("CreateBooking() error = %v, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateBookingChoosesContiguousSeats(t *testing.T) {
+	tests := []struct {
+		name string
+		// booked seats are booked by carol and held seats held by bob before alice books
+		booked    []string
+		held      []string
+		ownHold   []string
+		numSeats  int
+		wantSeats []string
+		wantErr   error
+	}{
+		{
+			name:      "first seats of the first row",
+			numSeats:  2,
+			wantSeats: []string{"1-1-1", "1-1-2"},
+		},
+		{
+			name:      "block does not span the aisle",
+			numSeats:  3,
+			wantSeats: []string{"1-2-1", "1-2-2", "1-2-3"},
+		},
+		{
+			name:      "skips booked seats",
+			booked:    []string{"1-1-2"},
+			numSeats:  2,
+			wantSeats: []string{"1-2-1", "1-2-2"},
+		},
+		{
+			name:      "block does not span a missing seat",
+			booked:    []string{"1-1-1", "1-2-2"},
+			numSeats:  2,
+			wantSeats: []string{"2-1-3", "2-1-4"},
+		},
+		{
+			name:      "skips seats held by someone else",
+			held:      []string{"1-1-1"},
+			numSeats:  2,
+			wantSeats: []string{"1-2-1", "1-2-2"},
+		},
+		{
+			name:      "takes seats of the booker's own hold",
+			ownHold:   []string{"1-1-1"},
+			numSeats:  2,
+			wantSeats: []string{"1-1-1", "1-1-2"},
+		},
+		{
+			name:     "no block large enough",
+			numSeats: 4,
+			wantErr:  &ErrNoContiguousSeats{},
+		},
+		{
+			name:     "no block left free",
+			booked:   []string{"1-2-2", "2-1-3"},
+			numSeats: 3,
+			wantErr:  &ErrNoContiguousSeats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, "2030-01-01T10:00:00Z")
+			if len(tt.booked) > 0 {
+				f.book(t, show, "carol", tt.booked...)
+			}
+			if len(tt.held) > 0 {
+				f.hold(t, show, "bob", tt.held...)
+			}
+			if len(tt.ownHold) > 0 {
+				f.hold(t, show, "alice", tt.ownHold...)
+			}
+
+			booking, err := f.bookings.CreateBooking(f.ctx, BookingRequest{ShowID: show.ID, NumSeats: tt.numSeats, UserID: "alice"})
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("CreateBooking() error = %v, want %T", err, tt.wantErr)
+			}
+			if !slices.Equal(booking.SeatIDs, tt.wantSeats) {
+				t.Errorf("CreateBooking() booked %v, want %v", booking.SeatIDs, tt.wantSeats)
+			}
+		})
+	}
+}
+
+func TestCreateBookingOfRequestedSeats(t *testing.T) {
+	tests := []struct {
+		name      string
+		held      []string
+		seatIDs   []string
+		wantSeats []string
+		wantErr   error
+	}{
+		{
+			name:      "free seats",
+			seatIDs:   []string{"1-1-1", "2-1-4"},
+			wantSeats: []string{"1-1-1", "2-1-4"},
+		},
+		{
+			name:      "repeated seat booked once",
+			seatIDs:   []string{"1-1-1", "1-1-1"},
+			wantSeats: []string{"1-1-1"},
+		},
+		{
+			name:    "missing seat",
+			seatIDs: []string{"2-1-2"},
+			wantErr: &ErrInvalidSeats{},
+		},
+		{
+			name:    "booked seat",
+			seatIDs: []string{"1-2-1"},
+			wantErr: &ErrSeatsAlreadyBooked{},
+		},
+		{
+			name:    "seat held by someone else",
+			held:    []string{"1-1-2"},
+			seatIDs: []string{"1-1-1", "1-1-2"},
+			wantErr: &ErrSeatsAlreadyBooked{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, "2030-01-01T10:00:00Z")
+			f.book(t, show, "carol", "1-2-1")
+			if len(tt.held) > 0 {
+				f.hold(t, show, "bob", tt.held...)
+			}
+
+			booking, err := f.bookings.CreateBooking(f.ctx, BookingRequest{ShowID: show.ID, SeatIDs: tt.seatIDs, UserID: "alice"})
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("CreateBooking() error = %v, want %T", err, tt.wantErr)
+			}
+			if !slices.Equal(booking.SeatIDs, tt.wantSeats) {
+				t.Errorf("CreateBooking() booked %v, want %v", booking.SeatIDs, tt.wantSeats)
+			}
+			if tt.wantErr == nil && booking.TotalPrice != 100*float64(len(tt.wantSeats)) {
+				t.Errorf("CreateBooking() total price = %v, want %v", booking.TotalPrice, 100*len(tt.wantSeats))
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"fmt"
+)
+
+// DeleteOptions controls cascading deletes of theatres and halls.
+type DeleteOptions struct {
+	// DryRun counts what would be removed without deleting anything, including the active
+	// bookings that would block the delete.
+	DryRun bool
+	// Force deletes even when upcoming shows still have confirmed bookings.
+	Force bool
+}
+
+// ErrTheatreNotFound is returned when a theatre does not exist.
+type ErrTheatreNotFound struct{}
+
+func (e *ErrTheatreNotFound) Error() string {
+	return "theatre not found"
+}
+
+func (e *ErrTheatreNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrTheatreNotFound) Code() string    { return "theatre_not_found" }
+
+// ErrHallNotFound is returned when a hall does not exist.
+type ErrHallNotFound struct{}
+
+func (e *ErrHallNotFound) Error() string {
+	return "hall not found"
+}
+
+func (e *ErrHallNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrHallNotFound) Code() string    { return "hall_not_found" }
+
+// ErrHasActiveBookings is returned when a delete or archive would remove upcoming shows that
+// customers hold confirmed bookings for. Summary counts those shows and bookings, and for
+// deletes everything else the delete would have removed.
+type ErrHasActiveBookings struct {
+	Summary models.DeletionSummary
+}
+
+func (e *ErrHasActiveBookings) Error() string {
+	return fmt.Sprintf("%d upcoming shows still have %d confirmed bookings; cancel the shows first",
+		e.Summary.UpcomingShows, e.Summary.ActiveBookings)
+}
+
+func (e *ErrHasActiveBookings) Kind() ErrorKind { return KindConflict }
+func (e *ErrHasActiveBookings) Code() string    { return "active_bookings" }
+
+func (e *ErrHasActiveBookings) Details() map[string]interface{} {
+	return map[string]interface{}{"summary": e.Summary}
+}
+
+// deletionGuard returns the guard a cascading delete runs under: without Force, upcoming
+// shows with confirmed bookings block it.
+func deletionGuard(opts DeleteOptions) repository.Guard {
+	if opts.Force {
+		return nil
+	}
+	return refuseActiveBookings
+}
@@ -0,0 +1,88 @@
+package services
+
+import "strings"
+
+// ErrorKind classifies service errors by how a caller should react to them. The HTTP layer
+// picks the status code from the kind alone.
+type ErrorKind string
+
+const (
+	// KindNotFound means the record acted on does not exist.
+	KindNotFound ErrorKind = "not_found"
+	// KindConflict means the request is valid but clashes with the current state, such as a
+	// seat that is already booked.
+	KindConflict ErrorKind = "conflict"
+	// KindValidation means the request itself is invalid and retrying it unchanged will fail again.
+	KindValidation ErrorKind = "validation"
+	// KindUnauthorized means the caller could not be authenticated.
+	KindUnauthorized ErrorKind = "unauthorized"
+	// KindForbidden means the caller is authenticated but not allowed to do this.
+	KindForbidden ErrorKind = "forbidden"
+	// KindGone means the record existed but has expired.
+	KindGone ErrorKind = "gone"
+	// KindRateLimited means the caller has to wait before trying again.
+	KindRateLimited ErrorKind = "rate_limited"
+	// KindInternal means something went wrong on our side. Errors that do not implement Error
+	// are internal too.
+	KindInternal ErrorKind = "internal"
+)
+
+// Error is implemented by every error services return on purpose. Code is a stable,
+// machine-readable identifier such as "show_not_found" that clients can branch on; the
+// message from Error() is for people and may change.
+type Error interface {
+	error
+	Kind() ErrorKind
+	Code() string
+}
+
+// ErrorDetails is implemented by errors that carry data clients need to recover, such as the
+// seats that were already taken.
+type ErrorDetails interface {
+	Details() map[string]interface{}
+}
+
+// FieldErrors is implemented by validation errors that can name the invalid request fields.
+type FieldErrors interface {
+	FieldErrors() []FieldError
+}
+
+// FieldError describes what is wrong with one request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrValidation is returned when request fields are invalid.
+type ErrValidation struct {
+	Fields []FieldError
+}
+
+// NewValidationError returns an ErrValidation for a single invalid field.
+func NewValidationError(field string, message string) *ErrValidation {
+	return &ErrValidation{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+func (e *ErrValidation) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+func (e *ErrValidation) Kind() ErrorKind           { return KindValidation }
+func (e *ErrValidation) Code() string              { return "validation_failed" }
+func (e *ErrValidation) FieldErrors() []FieldError { return e.Fields }
+
+// ErrForbidden is returned when the caller may not perform an action. Reason says why.
+type ErrForbidden struct {
+	Reason string
+}
+
+func (e *ErrForbidden) Error() string {
+	return "forbidden: " + e.Reason
+}
+
+func (e *ErrForbidden) Kind() ErrorKind { return KindForbidden }
+func (e *ErrForbidden) Code() string    { return "forbidden" }
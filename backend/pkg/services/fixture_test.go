@@ -0,0 +1,150 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// testFixture wires the services to in-memory repositories holding one movie and one hall.
+type testFixture struct {
+	ctx      context.Context
+	repos    repository.Repositories
+	shows    *ShowServiceImpl
+	bookings *BookingServiceImpl
+	holds    *SeatHoldServiceImpl
+	movie    models.Movie
+	hall     models.Hall
+}
+
+// testSeatMap is the layout of the fixture hall: row 1 has blocks of 2 and 3 seats either side
+// of an aisle, and row 2 one block of 4 seats with seat 2-1-2 missing.
+var testSeatMap = map[string][]int{"1": {2, 3}, "2": {4}}
+
+// newTestFixture creates the fixture, acting with access to every theatre.
+func newTestFixture(t *testing.T) *testFixture {
+	t.Helper()
+	repos := repository.NewMemoryRepositories()
+	movieService := NewMovieService(repos.Movies, repos.Shows)
+	theatreService := NewTheatreService(repos.Theatres)
+	hallService := NewHallService(repos.Halls, theatreService)
+	f := &testFixture{
+		ctx:      WithTheatreScope(context.Background(), TheatreScope{All: true}),
+		repos:    repos,
+		shows:    NewShowService(repos.Shows, repos.Bookings, repos.SeatHolds, movieService, hallService),
+		bookings: NewBookingService(repos.Bookings, repos.SeatHolds, repos.Shows, hallService),
+		holds:    NewSeatHoldService(repos.SeatHolds, repos.Bookings, repos.Shows, hallService),
+	}
+
+	var err error
+	if f.movie, err = movieService.CreateMovie(f.ctx, models.Movie{Title: "Test Movie", DurationMinutes: 120}); err != nil {
+		t.Fatalf("creating movie: %v", err)
+	}
+	theatre, err := theatreService.CreateTheatre(f.ctx, models.Theatre{Name: "Test Theatre"})
+	if err != nil {
+		t.Fatalf("creating theatre: %v", err)
+	}
+	f.hall, err = hallService.CreateHall(f.ctx, models.Hall{
+		Name:         "Test Hall",
+		TheatreID:    theatre.ID,
+		SeatMap:      testSeatMap,
+		MissingSeats: []string{"2-1-2"},
+	})
+	if err != nil {
+		t.Fatalf("creating hall: %v", err)
+	}
+	return f
+}
+
+// addShow schedules the fixture movie in the fixture hall at showTime.
+func (f *testFixture) addShow(t *testing.T, showTime string) models.Show {
+	t.Helper()
+	show, err := f.shows.CreateShow(f.ctx, models.Show{MovieID: f.movie.ID, HallID: f.hall.ID, Time: showTime, Price: 100})
+	if err != nil {
+		t.Fatalf("creating show at %s: %v", showTime, err)
+	}
+	return show
+}
+
+// book books seatIDs of show for userID.
+func (f *testFixture) book(t *testing.T, show models.Show, userID string, seatIDs ...string) models.Booking {
+	t.Helper()
+	booking, err := f.bookings.CreateBooking(f.ctx, BookingRequest{ShowID: show.ID, SeatIDs: seatIDs, UserID: userID})
+	if err != nil {
+		t.Fatalf("booking %v: %v", seatIDs, err)
+	}
+	return booking
+}
+
+// hold holds seatIDs of show for userID.
+func (f *testFixture) hold(t *testing.T, show models.Show, userID string, seatIDs ...string) models.SeatHold {
+	t.Helper()
+	hold, err := f.holds.CreateHold(f.ctx, BookingRequest{ShowID: show.ID, SeatIDs: seatIDs, UserID: userID})
+	if err != nil {
+		t.Fatalf("holding %v: %v", seatIDs, err)
+	}
+	return hold
+}
+
+// sameError reports whether err has the type of want, so tables can name the service error
+// they expect. A nil want matches only a nil err.
+func sameError(err, want error) bool {
+	return reflect.TypeOf(err) == reflect.TypeOf(want)
+}
+
+// testSecret is the HS256 key the user fixture signs access tokens with.
+const testSecret = "test-secret-that-is-at-least-32-bytes"
+
+// userFixture wires the user service to in-memory repositories.
+type userFixture struct {
+	ctx   context.Context
+	repos repository.Repositories
+	users *UserServiceImpl
+}
+
+// newUserFixture creates the user fixture, signing access tokens with the HS256 key "test".
+func newUserFixture(t *testing.T) *userFixture {
+	t.Helper()
+	repos := repository.NewMemoryRepositories()
+	key := &signingKey{id: "test", method: jwt.SigningMethodHS256, signKey: []byte(testSecret), verifyKey: []byte(testSecret)}
+	keys := &SigningKeys{current: key, keys: map[string]*signingKey{key.id: key}, ids: []string{key.id}}
+	return &userFixture{
+		ctx:   context.Background(),
+		repos: repos,
+		users: NewUserService(repos.Users, repos.Tokens, repos.Logins, keys, NewTheatreService(repos.Theatres)),
+	}
+}
+
+// register registers a customer.
+func (f *userFixture) register(t *testing.T, username string, password string) models.User {
+	t.Helper()
+	user, err := f.users.Register(f.ctx, Credentials{Username: username, Password: password})
+	if err != nil {
+		t.Fatalf("registering %s: %v", username, err)
+	}
+	return user
+}
+
+// login logs a user in from no particular client IP.
+func (f *userFixture) login(t *testing.T, username string, password string) models.TokenPair {
+	t.Helper()
+	tokens, err := f.users.Login(f.ctx, Credentials{Username: username, Password: password}, "")
+	if err != nil {
+		t.Fatalf("logging in %s: %v", username, err)
+	}
+	return tokens
+}
+
+// authenticate returns the claims of a valid access token.
+func (f *userFixture) authenticate(t *testing.T, accessToken string) *Claims {
+	t.Helper()
+	claims, err := f.users.Authenticate(f.ctx, accessToken)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	return claims
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// HallLayoutLimits bounds the hall layouts accepted by CreateHall and UpdateHall.
+type HallLayoutLimits struct {
+	MaxRows          int `json:"max_rows"`
+	MaxBlocksPerRow  int `json:"max_blocks_per_row"`
+	MinSeatsPerBlock int `json:"min_seats_per_block"`
+	MaxSeatsPerBlock int `json:"max_seats_per_block"`
+}
+
+// getHallLayoutLimits reads the layout limits from the HALL_MAX_ROWS, HALL_MAX_BLOCKS_PER_ROW,
+// HALL_MIN_SEATS_PER_BLOCK and HALL_MAX_SEATS_PER_BLOCK environment variables
+func getHallLayoutLimits() HallLayoutLimits {
+	return HallLayoutLimits{
+		MaxRows:          getIntEnv("HALL_MAX_ROWS", 50),
+		MaxBlocksPerRow:  getIntEnv("HALL_MAX_BLOCKS_PER_ROW", 10),
+		MinSeatsPerBlock: getIntEnv("HALL_MIN_SEATS_PER_BLOCK", 1),
+		MaxSeatsPerBlock: getIntEnv("HALL_MAX_SEATS_PER_BLOCK", 50),
+	}
+}
+
+// getIntEnv parses a positive integer from the environment, falling back on missing or invalid values.
+func getIntEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Warning: invalid %s %q, using default %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// ErrInvalidLayout is returned when a hall layout breaks the layout rules or limits. Field
+// names the hall field at fault: seat_map, missing_seats or seat_categories.
+type ErrInvalidLayout struct {
+	Field  string
+	Reason string
+}
+
+func (e *ErrInvalidLayout) Error() string {
+	return "invalid hall layout: " + e.Reason
+}
+
+func (e *ErrInvalidLayout) Kind() ErrorKind { return KindValidation }
+func (e *ErrInvalidLayout) Code() string    { return "invalid_layout" }
+func (e *ErrInvalidLayout) FieldErrors() []FieldError {
+	return []FieldError{{Field: e.Field, Message: e.Reason}}
+}
+
+func invalidLayout(field string, format string, args ...interface{}) error {
+	return &ErrInvalidLayout{Field: field, Reason: fmt.Sprintf(format, args...)}
+}
+
+// validateHallLayout checks a hall's seat map and missing seats against the layout limits.
+// Each seat map row is keyed by a positive row number and lists the seat count of each
+// column block; blocks are separated by aisles. Missing seats are seat IDs inside those
+// blocks that do not physically exist, such as gaps for pillars or wheelchair spaces.
+func validateHallLayout(hall models.Hall, limits HallLayoutLimits) error {
+	if len(hall.SeatMap) == 0 {
+		return invalidLayout("seat_map", "seat map must have at least one row")
+	}
+	if len(hall.SeatMap) > limits.MaxRows {
+		return invalidLayout("seat_map", "seat map has %d rows, the maximum is %d", len(hall.SeatMap), limits.MaxRows)
+	}
+
+	for rowKey, columns := range hall.SeatMap {
+		rowNum, err := strconv.Atoi(rowKey)
+		if err != nil || rowNum <= 0 || strconv.Itoa(rowNum) != rowKey {
+			return invalidLayout("seat_map", "row %q must be a positive row number", rowKey)
+		}
+		if len(columns) == 0 {
+			return invalidLayout("seat_map", "row %s must have at least one column", rowKey)
+		}
+		if len(columns) > limits.MaxBlocksPerRow {
+			return invalidLayout("seat_map", "row %s has %d columns, the maximum is %d", rowKey, len(columns), limits.MaxBlocksPerRow)
+		}
+		for colIndex, numSeats := range columns {
+			if numSeats < limits.MinSeatsPerBlock {
+				return invalidLayout("seat_map", "row %s, column %d must have at least %d seats", rowKey, colIndex+1, limits.MinSeatsPerBlock)
+			}
+			if numSeats > limits.MaxSeatsPerBlock {
+				return invalidLayout("seat_map", "row %s, column %d has %d seats, the maximum is %d", rowKey, colIndex+1, numSeats, limits.MaxSeatsPerBlock)
+			}
+		}
+	}
+
+	gridSeatIDs := make(map[string]bool)
+	for rowKey, columns := range hall.SeatMap {
+		for colIndex, numSeats := range columns {
+			for i := 1; i <= numSeats; i++ {
+				gridSeatIDs[fmt.Sprintf("%s-%d-%d", rowKey, colIndex+1, i)] = true
+			}
+		}
+	}
+	missing := make(map[string]bool)
+	for _, seatID := range hall.MissingSeats {
+		if !gridSeatIDs[seatID] {
+			return invalidLayout("missing_seats", "missing seat %s is not part of the seat map", seatID)
+		}
+		missing[seatID] = true
+	}
+	if len(missing) == len(gridSeatIDs) {
+		return invalidLayout("seat_map", "seat map must have at least one seat")
+	}
+
+	for key, category := range hall.SeatCategories {
+		if _, isRow := hall.SeatMap[key]; !isRow && (!gridSeatIDs[key] || missing[key]) {
+			return invalidLayout("seat_categories", "seat category key %s is neither a row nor a seat of the seat map", key)
+		}
+		if category == "" || len(category) > maxSeatCategoryLength {
+			return invalidLayout("seat_categories", "seat category for %s must be 1 to %d characters", key, maxSeatCategoryLength)
+		}
+	}
+
+	return nil
+}
+
+// maxSeatCategoryLength matches the width of the booked_seats.category column.
+const maxSeatCategoryLength = 50
+
+// seatCategory returns the category of a seat: its own entry in the hall's seat categories,
+// else its row's entry, else SeatCategoryRegular.
+func seatCategory(hall models.Hall, rowKey string, seatID string) string {
+	if category, ok := hall.SeatCategories[seatID]; ok {
+		return category
+	}
+	if category, ok := hall.SeatCategories[rowKey]; ok {
+		return category
+	}
+	return models.SeatCategoryRegular
+}
+
+// hallSeatCategories returns the set of categories that at least one seat of the hall belongs to.
+func hallSeatCategories(hall models.Hall) map[string]bool {
+	categories := make(map[string]bool)
+	for _, seats := range buildSeatsByRow(hall) {
+		for _, seat := range seats {
+			categories[seat.Category] = true
+		}
+	}
+	return categories
+}
+
+// buildSeatsByRow expands a hall's layout into seats grouped by row number, ordered by
+// column and seat number within each row. Missing seats are left out, so the seats on
+// either side of a gap are not adjacent.
+func buildSeatsByRow(hall models.Hall) map[int][]models.Seat {
+	missing := make(map[string]bool)
+	for _, seatID := range hall.MissingSeats {
+		missing[seatID] = true
+	}
+
+	seatsByRow := make(map[int][]models.Seat)
+	for rowKeyStr, cols := range hall.SeatMap {
+		rowNum, _ := strconv.Atoi(rowKeyStr)
+		for colIndex, numSeats := range cols {
+			for i := 1; i <= numSeats; i++ {
+				seatID := fmt.Sprintf("%d-%d-%d", rowNum, colIndex+1, i)
+				if missing[seatID] {
+					continue
+				}
+				seatsByRow[rowNum] = append(seatsByRow[rowNum], models.Seat{
+					ID:       seatID,
+					Row:      rowNum,
+					Column:   colIndex + 1,
+					Number:   i,
+					HallID:   hall.ID,
+					Category: seatCategory(hall, rowKeyStr, seatID),
+				})
+			}
+		}
+	}
+
+	for rowNum := range seatsByRow {
+		sort.Slice(seatsByRow[rowNum], func(i, j int) bool {
+			if seatsByRow[rowNum][i].Column != seatsByRow[rowNum][j].Column {
+				return seatsByRow[rowNum][i].Column < seatsByRow[rowNum][j].Column
+			}
+			return seatsByRow[rowNum][i].Number < seatsByRow[rowNum][j].Number
+		})
+	}
+
+	return seatsByRow
+}
+
+// filterSeatsByCategory returns the seats of seatsByRow that belong to category.
+func filterSeatsByCategory(seatsByRow map[int][]models.Seat, category string) map[int][]models.Seat {
+	filtered := make(map[int][]models.Seat)
+	for rowNum, seats := range seatsByRow {
+		for _, seat := range seats {
+			if seat.Category == category {
+				filtered[rowNum] = append(filtered[rowNum], seat)
+			}
+		}
+	}
+	return filtered
+}
+
+// sortedRowNums returns the row numbers of seatsByRow in ascending order.
+func sortedRowNums(seatsByRow map[int][]models.Seat) []int {
+	var rowNums []int
+	for rowNum := range seatsByRow {
+		rowNums = append(rowNums, rowNum)
+	}
+	sort.Ints(rowNums)
+	return rowNums
+}
@@ -1,13 +1,26 @@
 package services
 
-import "algoBharat/backend/pkg/models"
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+)
 
 // HallService defines the interface for hall-related business logic.
 type HallService interface {
-	GetHalls(theatreID string) ([]models.Hall, error) // Added theatreID parameter
-	GetHall(id string) (models.Hall, error)
-	CreateHall(hall models.Hall) (models.Hall, error)
-	UpdateHall(hall models.Hall) (models.Hall, error)
-	DeleteHall(id string) error
-	GetHallSeats(hallID string) ([]models.Seat, error)
+	// GetHalls lists the halls of a theatre, or of every theatre when theatreID is empty.
+	// Archived halls are only included when includeArchived is set.
+	GetHalls(ctx context.Context, theatreID string, includeArchived bool) ([]models.Hall, error)
+	// GetHall returns a hall, including an archived one.
+	GetHall(ctx context.Context, id string) (models.Hall, error)
+	CreateHall(ctx context.Context, hall models.Hall) (models.Hall, error)
+	UpdateHall(ctx context.Context, hall models.Hall) (models.Hall, error)
+	// DeleteHall deletes a hall with its seats, shows and bookings, or only counts them in a dry run.
+	DeleteHall(ctx context.Context, id string, opts DeleteOptions) (models.DeletionSummary, error)
+	// ArchiveHall hides a hall and its shows from public listings, keeping their bookings.
+	ArchiveHall(ctx context.Context, id string) error
+	// RestoreHall restores an archived hall and the shows archived with it.
+	RestoreHall(ctx context.Context, id string) (models.Hall, error)
+	GetHallSeats(ctx context.Context, hallID string) ([]models.Seat, error)
+	// GetLayoutLimits returns the limits hall layouts are validated against.
+	GetLayoutLimits() HallLayoutLimits
 }
@@ -1,266 +1,154 @@
 package services
 
 import (
-	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/models"
-	"encoding/json"
-	"fmt"
+	"algoBharat/backend/pkg/repository"
+	"context"
 	"log"
-	"math/rand"
-	"strconv"
-	"time"
 )
 
-type HallServiceImpl struct{}
-
-func (s *HallServiceImpl) GetHalls(theatreID string) ([]models.Hall, error) {
-	query := "SELECT id, name, theatre_id, seat_map FROM halls"
-	args := []interface{}{} // Use interface{} for dynamic arguments
-
-	if theatreID != "" {
-		query += " WHERE theatre_id = ?"
-		args = append(args, theatreID)
-	}
-
-	rows, err := database.DB.Query(query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var halls []models.Hall
-	for rows.Next() {
-		var hall models.Hall
-		var seatMapStr string
-		if err := rows.Scan(&hall.ID, &hall.Name, &hall.TheatreID, &seatMapStr); err != nil {
-			continue
-		}
-		// Unmarshal into the new map[string][]int structure
-		if err := json.Unmarshal([]byte(seatMapStr), &hall.SeatMap); err != nil {
-			log.Printf("Error unmarshaling seat map for hall %s: %v", hall.ID, err)
-			continue
-		}
-		halls = append(halls, hall)
-	}
-
-	return halls, nil
+type HallServiceImpl struct {
+	halls          repository.HallRepository
+	theatreService TheatreService
 }
 
-func (s *HallServiceImpl) GetHall(id string) (models.Hall, error) {
-	row := database.DB.QueryRow("SELECT id, name, theatre_id, seat_map FROM halls WHERE id = ?", id)
-
-	var hall models.Hall
-	var seatMapStr string
-	if err := row.Scan(&hall.ID, &hall.Name, &hall.TheatreID, &seatMapStr); err != nil {
-		return models.Hall{}, err
-	}
-	// Unmarshal into the new map[string][]int structure
-	if err := json.Unmarshal([]byte(seatMapStr), &hall.SeatMap); err != nil {
-		return models.Hall{}, fmt.Errorf("error unmarshaling seat map for hall %s: %w", hall.ID, err)
-	}
+// NewHallService creates a HallServiceImpl storing halls in halls. theatreService is
+// consulted so halls are not added to archived theatres.
+func NewHallService(halls repository.HallRepository, theatreService TheatreService) *HallServiceImpl {
+	return &HallServiceImpl{halls: halls, theatreService: theatreService}
+}
 
-	return hall, nil
+// GetHalls lists the halls of a theatre, or of every theatre when theatreID is empty.
+// Archived halls are only included when includeArchived is set.
+func (s *HallServiceImpl) GetHalls(ctx context.Context, theatreID string, includeArchived bool) ([]models.Hall, error) {
+	return s.halls.List(ctx, theatreID, includeArchived)
 }
 
-func (s *HallServiceImpl) CreateHall(hall models.Hall) (models.Hall, error) {
-	// Validation for the new seat_map structure
-	for rowKey, columns := range hall.SeatMap {
-		if len(columns) != 3 {
-			return models.Hall{}, fmt.Errorf("row %s must have exactly 3 columns", rowKey)
-		}
-		for colIndex, numSeats := range columns {
-			if numSeats < 2 {
-				return models.Hall{}, fmt.Errorf("row %s, column %d must have at least 2 seats", rowKey, colIndex+1)
-			}
-		}
-	}
+// GetHall returns a hall, including an archived one.
+func (s *HallServiceImpl) GetHall(ctx context.Context, id string) (models.Hall, error) {
+	hall, err := s.halls.Get(ctx, id)
+	return hall, fromRepository(err, &ErrHallNotFound{})
+}
 
-	hall.ID = strconv.Itoa(rand.Intn(1000000))
-	seatMapBytes, _ := json.Marshal(hall.SeatMap)
-	seatMapStr := string(seatMapBytes)
+// GetLayoutLimits returns the limits hall layouts are validated against.
+func (s *HallServiceImpl) GetLayoutLimits() HallLayoutLimits {
+	return getHallLayoutLimits()
+}
 
-	stmt, err := database.DB.Prepare("INSERT INTO halls(id, name, theatre_id, seat_map) VALUES(?, ?, ?, ?)")
-	if err != nil {
+func (s *HallServiceImpl) CreateHall(ctx context.Context, hall models.Hall) (models.Hall, error) {
+	if err := s.validateHall(ctx, hall); err != nil {
 		return models.Hall{}, err
 	}
-	_, err = stmt.Exec(hall.ID, hall.Name, hall.TheatreID, seatMapStr)
+
+	// Create individual seats from the hall layout together with the hall
+	_, err := insertWithNewID(func(id string) error {
+		hall.ID = id
+		log.Println("Creating seats for hall:", hall.ID)
+		return s.halls.Create(ctx, hall, newHallSeats(hall))
+	})
 	if err != nil {
 		return models.Hall{}, err
 	}
 
-	// Create individual seats based on the new seat_map structure
-	log.Println("Creating seats for hall:", hall.ID)
-	for rowKey, columns := range hall.SeatMap {
-		rowNum, _ := strconv.Atoi(rowKey)
-		for colIndex, numSeats := range columns {
-			for i := 1; i <= numSeats; i++ {
-				seat := models.Seat{
-					ID:     fmt.Sprintf("%d-%d-%d-%d", rowNum, colIndex+1, i, time.Now().UnixNano()), // Generate highly unique ID
-					Row:    rowNum,
-					Column: colIndex + 1,
-					Number: i,
-					HallID: hall.ID,
-				}
-				seatStmt, err := database.DB.Prepare("INSERT INTO seats(id, `row`, `number`, hall_id, `column`) VALUES(?, ?, ?, ?, ?)")
-				if err != nil {
-					return models.Hall{}, err
-				}
-				_, err = seatStmt.Exec(seat.ID, seat.Row, seat.Number, seat.HallID, seat.Column)
-				if err != nil {
-					log.Printf("Error inserting seat %s: %v", seat.ID, err)
-					return models.Hall{}, err
-				}
-			}
-		}
-	}
-
 	return hall, nil
 }
 
-func (s *HallServiceImpl) UpdateHall(hall models.Hall) (models.Hall, error) {
-	// Validation for the new seat_map structure
-	for rowKey, columns := range hall.SeatMap {
-		if len(columns) != 3 {
-			return models.Hall{}, fmt.Errorf("row %s must have exactly 3 columns", rowKey)
-		}
-		for colIndex, numSeats := range columns {
-			if numSeats < 2 {
-				return models.Hall{}, fmt.Errorf("row %s, column %d must have at least 2 seats", rowKey, colIndex+1)
-			}
-		}
-	}
-
-	seatMapBytes, _ := json.Marshal(hall.SeatMap)
-	seatMapStr := string(seatMapBytes)
-
-	stmt, err := database.DB.Prepare("UPDATE halls SET name = ?, theatre_id = ?, seat_map = ? WHERE id = ?")
-	if err != nil {
+func (s *HallServiceImpl) UpdateHall(ctx context.Context, hall models.Hall) (models.Hall, error) {
+	if err := checkHallAccess(ctx, s, hall.ID); err != nil {
 		return models.Hall{}, err
 	}
-	_, err = stmt.Exec(hall.Name, hall.TheatreID, seatMapStr, hall.ID)
-	if err != nil {
+	if err := s.validateHall(ctx, hall); err != nil {
 		return models.Hall{}, err
 	}
 
-	// Delete existing seats and create new ones
-	_, err = database.DB.Exec("DELETE FROM seats WHERE hall_id = ?", hall.ID)
+	// Replace the existing seats with ones for the new layout
+	log.Println("Updating seats for hall:", hall.ID)
+	err := retryWithNewIDs(func() error {
+		return s.halls.Update(ctx, hall, newHallSeats(hall))
+	})
 	if err != nil {
 		return models.Hall{}, err
 	}
 
-	// Create individual seats based on the new seat_map structure
-	log.Println("Updating seats for hall:", hall.ID)
-	for rowKey, columns := range hall.SeatMap {
-		rowNum, _ := strconv.Atoi(rowKey)
-		for colIndex, numSeats := range columns {
-			for i := 1; i <= numSeats; i++ {
-				seat := models.Seat{
-					ID:     fmt.Sprintf("%d-%d-%d-%d", rowNum, colIndex+1, i, time.Now().UnixNano()),
-					Row:    rowNum,
-					Column: colIndex + 1,
-					Number: i,
-					HallID: hall.ID,
-				}
-				seatStmt, err := database.DB.Prepare("INSERT INTO seats(id, `row`, `number`, hall_id, `column`) VALUES(?, ?, ?, ?, ?)")
-				if err != nil {
-					return models.Hall{}, err
-				}
-				_, err = seatStmt.Exec(seat.ID, seat.Row, seat.Number, seat.HallID, seat.Column)
-				if err != nil {
-					log.Printf("Error inserting seat %s: %v", seat.ID, err)
-					return models.Hall{}, err
-				}
-			}
-		}
-	}
-
 	return hall, nil
 }
 
-func (s *HallServiceImpl) DeleteHall(id string) error {
-	// Check if hall exists
-	_, err := s.GetHall(id)
+// validateHall checks a hall's fields and layout, and that its theatre exists, is not archived
+// and is in the caller's theatre scope.
+func (s *HallServiceImpl) validateHall(ctx context.Context, hall models.Hall) error {
+	v := &validator{}
+	v.checkName(hall.Name, "name")
+	var theatre models.Theatre
+	err := v.checkReference(hall.TheatreID, "theatre_id", "theatre", func() (err error) {
+		theatre, err = s.theatreService.GetTheatre(ctx, hall.TheatreID)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("hall not found: %w", err)
+		return err
 	}
-
-	// Get all shows for this hall
-	showRows, err := database.DB.Query("SELECT id FROM shows WHERE hall_id = ?", id)
-	if err != nil {
-		return fmt.Errorf("error getting shows for hall: %w", err)
+	if err := v.err(); err != nil {
+		return err
 	}
-	defer showRows.Close()
-
-	var showIDs []string
-	for showRows.Next() {
-		var showID string
-		if err := showRows.Scan(&showID); err != nil {
-			continue
-		}
-		showIDs = append(showIDs, showID)
+	if err := checkTheatreAccess(ctx, hall.TheatreID); err != nil {
+		return err
 	}
+	if err := validateHallLayout(hall, getHallLayoutLimits()); err != nil {
+		return err
+	}
+	return checkNotArchived("theatre", theatre.ArchivedAt, nil)
+}
 
-	// Delete all bookings for shows in this hall
-	if len(showIDs) > 0 {
-		// Build the IN clause for show IDs
-		placeholders := make([]string, len(showIDs))
-		args := make([]interface{}, len(showIDs))
-		for i, showID := range showIDs {
-			placeholders[i] = "?"
-			args[i] = showID
-		}
-
-		placeholdersStr := ""
-		for i, placeholder := range placeholders {
-			if i > 0 {
-				placeholdersStr += ","
-			}
-			placeholdersStr += placeholder
-		}
-
-		_, err = database.DB.Exec(fmt.Sprintf("DELETE FROM bookings WHERE show_id IN (%s)", placeholdersStr), args...)
-		if err != nil {
-			log.Printf("Warning: error deleting bookings for hall %s: %v", id, err)
+// newHallSeats returns a seat with a new ID for every seat in the hall layout, skipping missing seats.
+func newHallSeats(hall models.Hall) []models.Seat {
+	var seats []models.Seat
+	seatsByRow := buildSeatsByRow(hall)
+	for _, rowNum := range sortedRowNums(seatsByRow) {
+		for _, seat := range seatsByRow[rowNum] {
+			seat.ID = newID()
+			seats = append(seats, seat)
 		}
 	}
+	return seats
+}
 
-	// Delete all shows for this hall
-	_, err = database.DB.Exec("DELETE FROM shows WHERE hall_id = ?", id)
-	if err != nil {
-		return fmt.Errorf("error deleting shows for hall: %w", err)
+// DeleteHall deletes a hall with its seats and shows, and their bookings, in one transaction.
+func (s *HallServiceImpl) DeleteHall(ctx context.Context, id string, opts DeleteOptions) (models.DeletionSummary, error) {
+	if err := checkHallAccess(ctx, s, id); err != nil {
+		return models.DeletionSummary{DryRun: opts.DryRun}, err
 	}
-
-	// Delete all seats for this hall
-	_, err = database.DB.Exec("DELETE FROM seats WHERE hall_id = ?", id)
-	if err != nil {
-		return fmt.Errorf("error deleting seats for hall: %w", err)
+	if opts.DryRun {
+		summary, err := s.halls.CountDeletion(ctx, id)
+		return summary, fromRepository(err, &ErrHallNotFound{})
 	}
 
-	// Delete the hall
-	_, err = database.DB.Exec("DELETE FROM halls WHERE id = ?", id)
+	summary, err := s.halls.Delete(ctx, id, deletionGuard(opts))
 	if err != nil {
-		return fmt.Errorf("error deleting hall: %w", err)
+		return summary, fromRepository(err, &ErrHallNotFound{})
 	}
 
-	log.Printf("Hall %s and its %d shows deleted successfully", id, len(showIDs))
-	return nil
+	log.Printf("Hall %s and its %d shows and %d bookings deleted successfully", id, summary.Shows, summary.Bookings)
+	return summary, nil
 }
 
-func (s *HallServiceImpl) GetHallSeats(hallID string) ([]models.Seat, error) {
-	rows, err := database.DB.Query("SELECT id, `row`, `number`, hall_id, `column` FROM seats WHERE hall_id = ?", hallID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func (s *HallServiceImpl) GetHallSeats(ctx context.Context, hallID string) ([]models.Seat, error) {
+	return s.halls.Seats(ctx, hallID)
+}
 
-	var seats []models.Seat
-	for rows.Next() {
-		var seat models.Seat
-		if err := rows.Scan(&seat.ID, &seat.Row, &seat.Number, &seat.HallID, &seat.Column); err != nil {
-			continue
-		}
-		seats = append(seats, seat)
+// ArchiveHall hides a hall and its shows from public listings, keeping their bookings.
+func (s *HallServiceImpl) ArchiveHall(ctx context.Context, id string) error {
+	if err := checkHallAccess(ctx, s, id); err != nil {
+		return err
 	}
+	return fromRepository(s.halls.Archive(ctx, id, archiveTime(), refuseActiveBookings), &ErrHallNotFound{})
+}
 
-	return seats, nil
+// RestoreHall restores an archived hall and the shows archived with it.
+func (s *HallServiceImpl) RestoreHall(ctx context.Context, id string) (models.Hall, error) {
+	if err := checkHallAccess(ctx, s, id); err != nil {
+		return models.Hall{}, err
+	}
+	if err := fromRepository(s.halls.Restore(ctx, id), &ErrHallNotFound{}); err != nil {
+		return models.Hall{}, err
+	}
+	return s.GetHall(ctx, id)
 }
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/repository"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IDGenerator creates the primary keys of new rows. IDs must be unique, fit in a VARCHAR(36)
+// column, and should sort by creation time so new rows stay close together in indexes.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDv7Generator generates RFC 9562 version 7 UUIDs: a 48-bit Unix millisecond timestamp
+// followed by 74 random bits.
+type UUIDv7Generator struct{}
+
+func (g UUIDv7Generator) NewID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(fmt.Sprintf("could not read random bytes for ID: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x70 // Version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// ULIDGenerator generates ULIDs: a 48-bit Unix millisecond timestamp followed by 80 random
+// bits, written as 26 characters of Crockford base32.
+type ULIDGenerator struct{}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func (g ULIDGenerator) NewID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(fmt.Sprintf("could not read random bytes for ID: %v", err))
+	}
+
+	// 128 bits are encoded as 26 five-bit characters, the first of which only holds 3 bits
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+var (
+	idGeneratorMu sync.RWMutex
+	idGenerator   IDGenerator
+)
+
+// SetIDGenerator replaces the generator used for new IDs.
+func SetIDGenerator(generator IDGenerator) {
+	idGeneratorMu.Lock()
+	defer idGeneratorMu.Unlock()
+	idGenerator = generator
+}
+
+// getIDGenerator returns the configured generator, choosing one from ID_GENERATOR
+// ("uuidv7", the default, or "ulid") on first use.
+func getIDGenerator() IDGenerator {
+	idGeneratorMu.RLock()
+	generator := idGenerator
+	idGeneratorMu.RUnlock()
+	if generator != nil {
+		return generator
+	}
+
+	idGeneratorMu.Lock()
+	defer idGeneratorMu.Unlock()
+	if idGenerator == nil {
+		switch value := strings.ToLower(os.Getenv("ID_GENERATOR")); value {
+		case "ulid":
+			idGenerator = ULIDGenerator{}
+		case "", "uuidv7", "uuid":
+			idGenerator = UUIDv7Generator{}
+		default:
+			log.Printf("Unknown ID_GENERATOR %q, using uuidv7", value)
+			idGenerator = UUIDv7Generator{}
+		}
+	}
+	return idGenerator
+}
+
+// newID returns a new primary key from the configured generator.
+func newID() string {
+	return getIDGenerator().NewID()
+}
+
+// maxIDAttempts bounds how many fresh IDs insertWithNewID tries before giving up.
+const maxIDAttempts = 5
+
+// insertWithNewID runs insert with a new ID, retrying with another ID whenever the insert
+// fails because the ID is already taken. It returns the ID that was stored. Other errors,
+// including conflicts on other unique columns, are returned unchanged.
+func insertWithNewID(insert func(id string) error) (string, error) {
+	var id string
+	err := retryWithNewIDs(func() error {
+		id = newID()
+		return insert(id)
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// retryWithNewIDs runs write, which stores records under IDs it generates itself, again
+// whenever it fails with repository.ErrDuplicateID.
+func retryWithNewIDs(write func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if !errors.Is(err, repository.ErrDuplicateID) || attempt == maxIDAttempts {
+			return err
+		}
+		log.Printf("Generated ID is already taken, retrying (attempt %d of %d)", attempt, maxIDAttempts)
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LoginLimits bounds failed logins. After each failure for a username the next attempt must
+// wait BaseDelay, doubled per further failure; MaxFailures within Window lock the username,
+// and MaxFailuresPerIP lock the client IP, for LockoutDuration.
+type LoginLimits struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	Window           time.Duration
+	LockoutDuration  time.Duration
+	BaseDelay        time.Duration
+}
+
+// getLoginLimits reads the login limits from the LOGIN_MAX_FAILURES, LOGIN_MAX_FAILURES_PER_IP,
+// LOGIN_FAILURE_WINDOW, LOGIN_LOCKOUT_DURATION and LOGIN_BASE_DELAY environment variables
+func getLoginLimits() LoginLimits {
+	return LoginLimits{
+		MaxFailures:      getIntEnv("LOGIN_MAX_FAILURES", 5),
+		MaxFailuresPerIP: getIntEnv("LOGIN_MAX_FAILURES_PER_IP", 20),
+		Window:           getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LockoutDuration:  getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		BaseDelay:        getDurationEnv("LOGIN_BASE_DELAY", time.Second),
+	}
+}
+
+// delay returns how long to wait after the last of failures before trying again, at most the
+// lockout duration.
+func (l LoginLimits) delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	factor := math.Pow(2, float64(failures-1))
+	if factor >= float64(l.LockoutDuration/l.BaseDelay) {
+		return l.LockoutDuration
+	}
+	return time.Duration(factor) * l.BaseDelay
+}
+
+// Retryable is implemented by errors that clear up by themselves, such as lockouts.
+type Retryable interface {
+	// RetryAfter returns how long to wait before trying again.
+	RetryAfter() time.Duration
+}
+
+// retryAfterDetails returns the error details telling clients when to try again.
+func retryAfterDetails(wait time.Duration) map[string]interface{} {
+	return map[string]interface{}{"retry_after_seconds": retryAfterSeconds(wait)}
+}
+
+// retryAfterSeconds rounds wait up to whole seconds, so clients never retry too early.
+func retryAfterSeconds(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
+
+// ErrLoginThrottled is returned when a username is tried again too soon after a failed login.
+type ErrLoginThrottled struct {
+	Wait time.Duration
+}
+
+func (e *ErrLoginThrottled) Error() string {
+	return fmt.Sprintf("too many failed logins; try again in %d seconds", retryAfterSeconds(e.Wait))
+}
+
+func (e *ErrLoginThrottled) Kind() ErrorKind                 { return KindRateLimited }
+func (e *ErrLoginThrottled) Code() string                    { return "login_throttled" }
+func (e *ErrLoginThrottled) RetryAfter() time.Duration       { return e.Wait }
+func (e *ErrLoginThrottled) Details() map[string]interface{} { return retryAfterDetails(e.Wait) }
+
+// ErrAccountLocked is returned when a username is locked after too many failed logins. Every
+// username is tracked, so it says nothing about whether a user by that name exists.
+type ErrAccountLocked struct {
+	Wait time.Duration
+}
+
+func (e *ErrAccountLocked) Error() string {
+	return fmt.Sprintf("too many failed logins for this username; try again in %d seconds", retryAfterSeconds(e.Wait))
+}
+
+func (e *ErrAccountLocked) Kind() ErrorKind                 { return KindRateLimited }
+func (e *ErrAccountLocked) Code() string                    { return "account_locked" }
+func (e *ErrAccountLocked) RetryAfter() time.Duration       { return e.Wait }
+func (e *ErrAccountLocked) Details() map[string]interface{} { return retryAfterDetails(e.Wait) }
+
+// ErrClientLocked is returned when a client IP is locked after too many failed logins.
+type ErrClientLocked struct {
+	Wait time.Duration
+}
+
+func (e *ErrClientLocked) Error() string {
+	return fmt.Sprintf("too many failed logins from your address; try again in %d seconds", retryAfterSeconds(e.Wait))
+}
+
+func (e *ErrClientLocked) Kind() ErrorKind                 { return KindRateLimited }
+func (e *ErrClientLocked) Code() string                    { return "client_locked" }
+func (e *ErrClientLocked) RetryAfter() time.Duration       { return e.Wait }
+func (e *ErrClientLocked) Details() map[string]interface{} { return retryAfterDetails(e.Wait) }
+
+// ErrLockoutNotFound is returned when clearing a username or IP that has no failed logins.
+type ErrLockoutNotFound struct{}
+
+func (e *ErrLockoutNotFound) Error() string {
+	return "no failed logins recorded for this username or address"
+}
+
+func (e *ErrLockoutNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrLockoutNotFound) Code() string    { return "lockout_not_found" }
+
+// loginLimiter throttles and locks usernames and client IPs with too many failed logins,
+// keeping count in attempts.
+type loginLimiter struct {
+	attempts repository.LoginAttemptRepository
+}
+
+// check refuses a login for username from clientIP if either is locked, or if the username
+// is tried again before the delay after its last failure is up. It runs before the password
+// is compared, so refused attempts cost no bcrypt work.
+func (l *loginLimiter) check(ctx context.Context, username string, clientIP string, now time.Time) error {
+	limits := getLoginLimits()
+
+	if clientIP != "" {
+		attempts, err := l.attempts.Get(ctx, models.LoginAttemptIP, clientIP)
+		if err != nil && err != repository.ErrNotFound {
+			return err
+		}
+		if err == nil && attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+			return &ErrClientLocked{Wait: attempts.LockedUntil.Sub(now)}
+		}
+	}
+
+	attempts, err := l.attempts.Get(ctx, models.LoginAttemptUsername, username)
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+		return &ErrAccountLocked{Wait: attempts.LockedUntil.Sub(now)}
+	}
+	if attempts.LastFailureAt.Before(now.Add(-limits.Window)) {
+		return nil
+	}
+	if retryAt := attempts.LastFailureAt.Add(limits.delay(attempts.Failures)); retryAt.After(now) {
+		return &ErrLoginThrottled{Wait: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+// recordFailure counts a failed login for username from clientIP and locks either once it
+// reaches its limit, returning the lockout error to report instead of the failure.
+func (l *loginLimiter) recordFailure(ctx context.Context, username string, clientIP string, now time.Time) error {
+	limits := getLoginLimits()
+	resetBefore := now.Add(-limits.Window)
+	lockedUntil := now.Add(limits.LockoutDuration)
+
+	var lockErr error
+	if clientIP != "" {
+		attempts, err := l.attempts.RecordFailure(ctx, models.LoginAttemptIP, clientIP, now, resetBefore)
+		if err != nil {
+			return err
+		}
+		if attempts.Failures >= limits.MaxFailuresPerIP {
+			if err := l.attempts.Lock(ctx, models.LoginAttemptIP, clientIP, lockedUntil); err != nil {
+				return err
+			}
+			lockErr = &ErrClientLocked{Wait: limits.LockoutDuration}
+		}
+	}
+
+	attempts, err := l.attempts.RecordFailure(ctx, models.LoginAttemptUsername, username, now, resetBefore)
+	if err != nil {
+		return err
+	}
+	if attempts.Failures >= limits.MaxFailures {
+		if err := l.attempts.Lock(ctx, models.LoginAttemptUsername, username, lockedUntil); err != nil {
+			return err
+		}
+		lockErr = &ErrAccountLocked{Wait: limits.LockoutDuration}
+	}
+	return lockErr
+}
+
+// recordSuccess forgets the failed logins of username. Those of the client IP are kept, so
+// logging into one account does not reset the count of guesses at others.
+func (l *loginLimiter) recordSuccess(ctx context.Context, username string) error {
+	err := l.attempts.Clear(ctx, models.LoginAttemptUsername, username)
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// deleteStale deletes attempts too old to count that are not locked.
+func (l *loginLimiter) deleteStale(ctx context.Context, now time.Time) (int64, error) {
+	return l.attempts.DeleteStale(ctx, now.Add(-getLoginLimits().Window), now)
+}
+
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// compareDummyPassword spends as long as checking a real password, so that logins for unknown
+// usernames cannot be told apart by how long they take.
+func compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
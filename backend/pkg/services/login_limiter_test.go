@@ -0,0 +1,197 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"context"
+	"testing"
+	"time"
+)
+
+// setLoginLimits sets the login limits of a test through the environment.
+func setLoginLimits(t *testing.T, maxFailures string, maxFailuresPerIP string, baseDelay string) {
+	t.Setenv("LOGIN_MAX_FAILURES", maxFailures)
+	t.Setenv("LOGIN_MAX_FAILURES_PER_IP", maxFailuresPerIP)
+	t.Setenv("LOGIN_FAILURE_WINDOW", "15m")
+	t.Setenv("LOGIN_LOCKOUT_DURATION", "15m")
+	t.Setenv("LOGIN_BASE_DELAY", baseDelay)
+}
+
+func TestLoginLimiter(t *testing.T) {
+	// failure is a failed login for username, or alice, at an offset from the start
+	type failure struct {
+		at       time.Duration
+		username string
+	}
+
+	tests := []struct {
+		name     string
+		failures []failure
+		// checkAt is when the next login for alice is checked
+		checkAt time.Duration
+		// wantLastFailureErr is what recording the last failure returns
+		wantLastFailureErr error
+		wantErr            error
+	}{
+		{
+			name: "no failures",
+		},
+		{
+			name:     "throttled right after a failure",
+			failures: []failure{{at: 0}},
+			checkAt:  500 * time.Millisecond,
+			wantErr:  &ErrLoginThrottled{},
+		},
+		{
+			name:     "delay up after a failure",
+			failures: []failure{{at: 0}},
+			checkAt:  time.Second,
+		},
+		{
+			name:     "delay doubles with each failure",
+			failures: []failure{{at: 0}, {at: time.Second}},
+			checkAt:  2500 * time.Millisecond,
+			wantErr:  &ErrLoginThrottled{},
+		},
+		{
+			name:               "locked at the failure limit",
+			failures:           []failure{{at: 0}, {at: 10 * time.Second}, {at: 20 * time.Second}},
+			checkAt:            10 * time.Minute,
+			wantLastFailureErr: &ErrAccountLocked{},
+			wantErr:            &ErrAccountLocked{},
+		},
+		{
+			name:               "unlocked once the lockout is over",
+			failures:           []failure{{at: 0}, {at: 10 * time.Second}, {at: 20 * time.Second}},
+			checkAt:            20*time.Second + 15*time.Minute,
+			wantLastFailureErr: &ErrAccountLocked{},
+		},
+		{
+			name:     "failures before the window do not count",
+			failures: []failure{{at: 0}, {at: 10 * time.Second}, {at: 20 * time.Minute}},
+			checkAt:  20*time.Minute + time.Second,
+		},
+		{
+			name:     "other usernames do not count",
+			failures: []failure{{at: 0, username: "bob"}, {at: 0, username: "carol"}, {at: 0, username: "dave"}},
+			checkAt:  time.Millisecond,
+		},
+		{
+			name: "client IP locked across usernames",
+			failures: []failure{
+				{at: 0, username: "bob"}, {at: 0, username: "carol"}, {at: 0, username: "dave"},
+				{at: 0, username: "erin"}, {at: 0, username: "frank"},
+			},
+			checkAt:            time.Minute,
+			wantLastFailureErr: &ErrClientLocked{},
+			wantErr:            &ErrClientLocked{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLoginLimits(t, "3", "5", "1s")
+			ctx := context.Background()
+			limiter := &loginLimiter{attempts: repository.NewMemoryRepositories().Logins}
+			start := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+
+			var err error
+			for _, f := range tt.failures {
+				username := f.username
+				if username == "" {
+					username = "alice"
+				}
+				err = limiter.recordFailure(ctx, username, "10.0.0.1", start.Add(f.at))
+			}
+			if !sameError(err, tt.wantLastFailureErr) {
+				t.Fatalf("recordFailure() error = %v, want %T", err, tt.wantLastFailureErr)
+			}
+
+			if err := limiter.check(ctx, "alice", "10.0.0.1", start.Add(tt.checkAt)); !sameError(err, tt.wantErr) {
+				t.Errorf("check() error = %v, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginDoesNotRevealUsernames(t *testing.T) {
+	setLoginLimits(t, "3", "100", "1ns")
+	f := newUserFixture(t)
+	f.register(t, "alice", "password1")
+
+	// Each attempt for an existing and an unknown username must fail the same way
+	for attempt := 1; attempt <= 4; attempt++ {
+		_, existingErr := f.users.Login(f.ctx, Credentials{Username: "alice", Password: "wrong-password"}, "")
+		_, unknownErr := f.users.Login(f.ctx, Credentials{Username: "nobody", Password: "wrong-password"}, "")
+		if existingErr == nil || unknownErr == nil {
+			t.Fatalf("attempt %d: Login() errors = %v and %v, want failures", attempt, existingErr, unknownErr)
+		}
+		if !sameError(unknownErr, existingErr) || unknownErr.Error() != existingErr.Error() {
+			t.Errorf("attempt %d: Login() of an unknown username error = %v, want %v as for an existing one", attempt, unknownErr, existingErr)
+		}
+	}
+}
+
+func TestClearLoginLockout(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		value   string
+		wantErr error
+		// wantLoginErr is what alice logging in with her password from the locked IP fails with
+		wantLoginErr error
+	}{
+		{
+			name:         "username",
+			kind:         models.LoginAttemptUsername,
+			value:        "alice",
+			wantLoginErr: &ErrClientLocked{},
+		},
+		{
+			name:         "client IP",
+			kind:         models.LoginAttemptIP,
+			value:        "10.0.0.1",
+			wantLoginErr: &ErrAccountLocked{},
+		},
+		{
+			name:         "nothing recorded",
+			kind:         models.LoginAttemptUsername,
+			value:        "bob",
+			wantErr:      &ErrLockoutNotFound{},
+			wantLoginErr: &ErrClientLocked{},
+		},
+		{
+			name:         "unknown kind",
+			kind:         "email",
+			value:        "alice",
+			wantErr:      &ErrValidation{},
+			wantLoginErr: &ErrClientLocked{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLoginLimits(t, "2", "2", "1ns")
+			f := newUserFixture(t)
+			f.register(t, "alice", "password1")
+			for i := 0; i < 2; i++ {
+				f.users.Login(f.ctx, Credentials{Username: "alice", Password: "wrong-password"}, "10.0.0.1")
+			}
+			lockouts, err := f.users.GetLoginLockouts(f.ctx)
+			if err != nil {
+				t.Fatalf("GetLoginLockouts() error = %v", err)
+			}
+			if len(lockouts) != 2 {
+				t.Fatalf("GetLoginLockouts() = %v, want the username and the client IP", lockouts)
+			}
+
+			if err := f.users.ClearLoginLockout(f.ctx, tt.kind, tt.value); !sameError(err, tt.wantErr) {
+				t.Fatalf("ClearLoginLockout() error = %v, want %T", err, tt.wantErr)
+			}
+			_, err = f.users.Login(f.ctx, Credentials{Username: "alice", Password: "password1"}, "10.0.0.1")
+			if !sameError(err, tt.wantLoginErr) {
+				t.Errorf("Login() error = %v, want %T", err, tt.wantLoginErr)
+			}
+		})
+	}
+}
@@ -1,12 +1,22 @@
 package services
 
-import "algoBharat/backend/pkg/models"
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+)
 
 // MovieService defines the interface for movie-related business logic.
 type MovieService interface {
-	GetMovies() ([]models.Movie, error)
-	GetMovie(id string) (models.Movie, error)
-	CreateMovie(movie models.Movie) (models.Movie, error)
-	UpdateMovie(id string, movie models.Movie) (models.Movie, error)
-	DeleteMovie(id string) error
+	// GetMovies lists movies; archived movies are only included when includeArchived is set.
+	GetMovies(ctx context.Context, includeArchived bool) ([]models.Movie, error)
+	// GetMovie returns a movie, including an archived one.
+	GetMovie(ctx context.Context, id string) (models.Movie, error)
+	CreateMovie(ctx context.Context, movie models.Movie) (models.Movie, error)
+	UpdateMovie(ctx context.Context, id string, movie models.Movie) (models.Movie, error)
+	// DeleteMovie permanently deletes a movie that no shows refer to.
+	DeleteMovie(ctx context.Context, id string) error
+	// ArchiveMovie hides a movie and its shows from public listings, keeping their bookings.
+	ArchiveMovie(ctx context.Context, id string) error
+	// RestoreMovie restores an archived movie and the shows archived with it.
+	RestoreMovie(ctx context.Context, id string) (models.Movie, error)
 }
@@ -1,52 +1,64 @@
 package services
 
 import (
-	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/models"
-	"math/rand"
-	"strconv"
+	"algoBharat/backend/pkg/repository"
+	"context"
+	"fmt"
 )
 
-type MovieServiceImpl struct{}
+// ErrMovieHasShows is returned when deleting a movie that shows still refer to.
+type ErrMovieHasShows struct {
+	Count int
+}
 
-func (s *MovieServiceImpl) GetMovies() ([]models.Movie, error) {
-	rows, err := database.DB.Query("SELECT id, title, duration_minutes FROM movies")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var movies []models.Movie
-	for rows.Next() {
-		var movie models.Movie
-		if err := rows.Scan(&movie.ID, &movie.Title, &movie.DurationMinutes); err != nil {
-			continue
-		}
-		movies = append(movies, movie)
-	}
+func (e *ErrMovieHasShows) Error() string {
+	return fmt.Sprintf("movie still has %d shows; delete them first", e.Count)
+}
+
+func (e *ErrMovieHasShows) Kind() ErrorKind { return KindConflict }
+func (e *ErrMovieHasShows) Code() string    { return "movie_has_shows" }
+
+// ErrMovieNotFound is returned when a movie does not exist.
+type ErrMovieNotFound struct{}
 
-	return movies, nil
+func (e *ErrMovieNotFound) Error() string {
+	return "movie not found"
 }
 
-func (s *MovieServiceImpl) GetMovie(id string) (models.Movie, error) {
-	row := database.DB.QueryRow("SELECT id, title, duration_minutes FROM movies WHERE id = ?", id)
+func (e *ErrMovieNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrMovieNotFound) Code() string    { return "movie_not_found" }
 
-	var movie models.Movie
-	if err := row.Scan(&movie.ID, &movie.Title, &movie.DurationMinutes); err != nil {
-		return models.Movie{}, err
-	}
+type MovieServiceImpl struct {
+	movies repository.MovieRepository
+	shows  repository.ShowRepository
+}
 
-	return movie, nil
+// NewMovieService creates a MovieServiceImpl storing movies in movies. shows is consulted
+// before deleting a movie.
+func NewMovieService(movies repository.MovieRepository, shows repository.ShowRepository) *MovieServiceImpl {
+	return &MovieServiceImpl{movies: movies, shows: shows}
 }
 
-func (s *MovieServiceImpl) CreateMovie(movie models.Movie) (models.Movie, error) {
-	movie.ID = strconv.Itoa(rand.Intn(1000000))
+// GetMovies lists movies; archived movies are only included when includeArchived is set.
+func (s *MovieServiceImpl) GetMovies(ctx context.Context, includeArchived bool) ([]models.Movie, error) {
+	return s.movies.List(ctx, includeArchived)
+}
 
-	stmt, err := database.DB.Prepare("INSERT INTO movies(id, title, duration_minutes) VALUES(?, ?, ?)")
-	if err != nil {
+// GetMovie returns a movie, including an archived one.
+func (s *MovieServiceImpl) GetMovie(ctx context.Context, id string) (models.Movie, error) {
+	movie, err := s.movies.Get(ctx, id)
+	return movie, fromRepository(err, &ErrMovieNotFound{})
+}
+
+func (s *MovieServiceImpl) CreateMovie(ctx context.Context, movie models.Movie) (models.Movie, error) {
+	if err := validateMovie(movie); err != nil {
 		return models.Movie{}, err
 	}
-	_, err = stmt.Exec(movie.ID, movie.Title, movie.DurationMinutes)
+	_, err := insertWithNewID(func(id string) error {
+		movie.ID = id
+		return s.movies.Create(ctx, movie)
+	})
 	if err != nil {
 		return models.Movie{}, err
 	}
@@ -54,29 +66,42 @@ func (s *MovieServiceImpl) CreateMovie(movie models.Movie) (models.Movie, error)
 	return movie, nil
 }
 
-func (s *MovieServiceImpl) UpdateMovie(id string, movie models.Movie) (models.Movie, error) {
-	stmt, err := database.DB.Prepare("UPDATE movies SET title = ?, duration_minutes = ? WHERE id = ?")
-	if err != nil {
+// UpdateMovie changes a movie, failing with ErrMovieNotFound if there is no such movie, and
+// returns it as stored.
+func (s *MovieServiceImpl) UpdateMovie(ctx context.Context, id string, movie models.Movie) (models.Movie, error) {
+	if err := validateMovie(movie); err != nil {
 		return models.Movie{}, err
 	}
-	_, err = stmt.Exec(movie.Title, movie.DurationMinutes, id)
-	if err != nil {
+	movie.ID = id
+	if err := fromRepository(s.movies.Update(ctx, movie), &ErrMovieNotFound{}); err != nil {
 		return models.Movie{}, err
 	}
 
-	movie.ID = id
-	return movie, nil
+	return s.GetMovie(ctx, id)
 }
 
-func (s *MovieServiceImpl) DeleteMovie(id string) error {
-	stmt, err := database.DB.Prepare("DELETE FROM movies WHERE id = ?")
+func (s *MovieServiceImpl) DeleteMovie(ctx context.Context, id string) error {
+	// Shows keep a foreign key to their movie, so they must go first
+	showCount, err := s.shows.Count(ctx, repository.ShowFilter{MovieID: id, IncludeArchived: true, IncludeCancelled: true})
 	if err != nil {
 		return err
 	}
-	_, err = stmt.Exec(id)
-	if err != nil {
-		return err
+	if showCount > 0 {
+		return &ErrMovieHasShows{Count: showCount}
 	}
 
-	return nil
+	return fromRepository(s.movies.Delete(ctx, id), &ErrMovieNotFound{})
+}
+
+// ArchiveMovie hides a movie and its shows from public listings, keeping their bookings.
+func (s *MovieServiceImpl) ArchiveMovie(ctx context.Context, id string) error {
+	return fromRepository(s.movies.Archive(ctx, id, archiveTime(), refuseActiveBookings), &ErrMovieNotFound{})
+}
+
+// RestoreMovie restores an archived movie and the shows archived with it.
+func (s *MovieServiceImpl) RestoreMovie(ctx context.Context, id string) (models.Movie, error) {
+	if err := fromRepository(s.movies.Restore(ctx, id), &ErrMovieNotFound{}); err != nil {
+		return models.Movie{}, err
+	}
+	return s.GetMovie(ctx, id)
 }
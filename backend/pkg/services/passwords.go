@@ -0,0 +1,147 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes is the most bcrypt hashes; longer passwords would be silently truncated.
+const maxPasswordBytes = 72
+
+const defaultPasswordResetTTL = 24 * time.Hour
+
+// PasswordPolicy is the strength rules new passwords must meet.
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+}
+
+// getPasswordPolicy reads the password policy from the PASSWORD_MIN_LENGTH, PASSWORD_REQUIRE_UPPERCASE,
+// PASSWORD_REQUIRE_LOWERCASE, PASSWORD_REQUIRE_DIGIT and PASSWORD_REQUIRE_SYMBOL environment variables
+func getPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        getIntEnv("PASSWORD_MIN_LENGTH", 8),
+		RequireUppercase: getBoolEnv("PASSWORD_REQUIRE_UPPERCASE", false),
+		RequireLowercase: getBoolEnv("PASSWORD_REQUIRE_LOWERCASE", false),
+		RequireDigit:     getBoolEnv("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol:    getBoolEnv("PASSWORD_REQUIRE_SYMBOL", false),
+	}
+}
+
+// getPasswordResetTTL returns how long a password reset token is valid, from the PASSWORD_RESET_TTL environment variable
+func getPasswordResetTTL() time.Duration {
+	return getDurationEnv("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+}
+
+// getBoolEnv parses a boolean (e.g. "true" or "0") from the environment, falling back on missing or invalid values.
+func getBoolEnv(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using default %t", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// problems returns what password lacks to meet the policy, in words that complete "must ...".
+// A password may not be the username either.
+func (p PasswordPolicy) problems(password string, username string) []string {
+	var problems []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("be at least %d characters", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("be at most %d bytes", maxPasswordBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		problems = append(problems, "contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		problems = append(problems, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "contain a symbol")
+	}
+	if username != "" && strings.EqualFold(password, username) {
+		problems = append(problems, "differ from the username")
+	}
+	return problems
+}
+
+// checkPassword checks a new password against the policy, reporting every rule it breaks at once.
+func (v *validator) checkPassword(password string, field string, username string, policy PasswordPolicy) {
+	if password == "" {
+		v.check(false, field, "is required")
+		return
+	}
+	if problems := policy.problems(password, username); len(problems) > 0 {
+		v.check(false, field, "must "+strings.Join(problems, ", "))
+	}
+}
+
+// ErrPasswordChangeRequired is returned when a user who must change their password, such as
+// the bootstrap admin on first login, does anything else.
+type ErrPasswordChangeRequired struct{}
+
+func (e *ErrPasswordChangeRequired) Error() string {
+	return "you must change your password before continuing"
+}
+
+func (e *ErrPasswordChangeRequired) Kind() ErrorKind { return KindForbidden }
+func (e *ErrPasswordChangeRequired) Code() string    { return "password_change_required" }
+
+// ErrInvalidResetToken is returned when a password reset token is unknown, expired or already used.
+type ErrInvalidResetToken struct{}
+
+func (e *ErrInvalidResetToken) Error() string {
+	return "invalid password reset token; ask an administrator for a new one"
+}
+
+func (e *ErrInvalidResetToken) Kind() ErrorKind { return KindValidation }
+func (e *ErrInvalidResetToken) Code() string    { return "invalid_reset_token" }
+func (e *ErrInvalidResetToken) FieldErrors() []FieldError {
+	return []FieldError{{Field: "reset_token", Message: "is invalid, expired or already used"}}
+}
+
+// newPasswordResetToken returns a one-time reset token for userID, and the record it is stored as.
+func newPasswordResetToken(userID string, now time.Time) (models.PasswordReset, models.PasswordResetToken, error) {
+	token, hash, err := newSecretToken()
+	if err != nil {
+		return models.PasswordReset{}, models.PasswordResetToken{}, err
+	}
+	expiresAt := now.Add(getPasswordResetTTL())
+	return models.PasswordReset{ResetToken: token, ExpiresAt: expiresAt},
+		models.PasswordResetToken{ID: hash, UserID: userID, ExpiresAt: expiresAt},
+		nil
+}
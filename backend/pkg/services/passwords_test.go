@@ -0,0 +1,305 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicyProblems(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 8, RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name string
+		// policy is the strict policy unless set
+		policy       *PasswordPolicy
+		password     string
+		username     string
+		wantProblems []string
+	}{
+		{
+			name:     "meets every rule",
+			password: "Passw0rd!",
+		},
+		{
+			name:         "too short",
+			password:     "Pa0!",
+			wantProblems: []string{"be at least 8 characters"},
+		},
+		{
+			name:     "length counts characters, not bytes",
+			password: "Ää0!Ää0!",
+		},
+		{
+			name:         "longer than bcrypt hashes",
+			password:     strings.Repeat("a", 70) + "A0!",
+			wantProblems: []string{"be at most 72 bytes"},
+		},
+		{
+			name:         "no uppercase letter",
+			password:     "passw0rd!",
+			wantProblems: []string{"contain an uppercase letter"},
+		},
+		{
+			name:         "no lowercase letter",
+			password:     "PASSW0RD!",
+			wantProblems: []string{"contain a lowercase letter"},
+		},
+		{
+			name:         "no digit",
+			password:     "Password!",
+			wantProblems: []string{"contain a digit"},
+		},
+		{
+			name:         "no symbol",
+			password:     "Passw0rd",
+			wantProblems: []string{"contain a symbol"},
+		},
+		{
+			name:         "username in another case",
+			password:     "Passw0rd!",
+			username:     "PASSW0RD!",
+			wantProblems: []string{"differ from the username"},
+		},
+		{
+			name:         "every broken rule at once",
+			password:     "abc",
+			wantProblems: []string{"be at least 8 characters", "contain an uppercase letter", "contain a digit", "contain a symbol"},
+		},
+		{
+			name:         "only the rules the policy requires",
+			policy:       &PasswordPolicy{MinLength: 8, RequireDigit: true},
+			password:     "password",
+			wantProblems: []string{"contain a digit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := strict
+			if tt.policy != nil {
+				policy = *tt.policy
+			}
+			if problems := policy.problems(tt.password, tt.username); !slices.Equal(problems, tt.wantProblems) {
+				t.Errorf("problems() = %q, want %q", problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, RequireDigit: true}
+
+	tests := []struct {
+		name        string
+		password    string
+		wantMessage string
+	}{
+		{
+			name:     "valid",
+			password: "password1",
+		},
+		{
+			name:        "missing",
+			wantMessage: "is required",
+		},
+		{
+			name:        "every problem in one message",
+			password:    "pass",
+			wantMessage: "must be at least 8 characters, contain a digit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &validator{}
+			v.checkPassword(tt.password, "password", "alice", policy)
+			var want []FieldError
+			if tt.wantMessage != "" {
+				want = []FieldError{{Field: "password", Message: tt.wantMessage}}
+			}
+			if !slices.Equal(v.fields, want) {
+				t.Errorf("checkPassword() field errors = %v, want %v", v.fields, want)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name string
+		// token returns the reset token presented for userID
+		token    func(t *testing.T, f *userFixture, userID string) string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "issued token",
+			token:    issueReset,
+			password: "password2",
+		},
+		{
+			name: "expired token",
+			token: func(t *testing.T, f *userFixture, userID string) string {
+				reset, stored, err := newPasswordResetToken(userID, time.Now().UTC().Add(-getPasswordResetTTL()-time.Minute))
+				if err != nil {
+					t.Fatalf("newPasswordResetToken() error = %v", err)
+				}
+				if err := f.repos.Tokens.CreatePasswordReset(f.ctx, stored); err != nil {
+					t.Fatalf("storing reset token: %v", err)
+				}
+				return reset.ResetToken
+			},
+			password: "password2",
+			wantErr:  &ErrInvalidResetToken{},
+		},
+		{
+			name: "already used token",
+			token: func(t *testing.T, f *userFixture, userID string) string {
+				token := issueReset(t, f, userID)
+				if err := f.users.ResetPassword(f.ctx, token, "password3"); err != nil {
+					t.Fatalf("first ResetPassword() error = %v", err)
+				}
+				return token
+			},
+			password: "password2",
+			wantErr:  &ErrInvalidResetToken{},
+		},
+		{
+			name: "token superseded by a later one",
+			token: func(t *testing.T, f *userFixture, userID string) string {
+				token := issueReset(t, f, userID)
+				issueReset(t, f, userID)
+				return token
+			},
+			password: "password2",
+			wantErr:  &ErrInvalidResetToken{},
+		},
+		{
+			name: "unknown token",
+			token: func(t *testing.T, f *userFixture, userID string) string {
+				return "no-such-token"
+			},
+			password: "password2",
+			wantErr:  &ErrInvalidResetToken{},
+		},
+		{
+			name:     "password breaking the policy",
+			token:    issueReset,
+			password: "short",
+			wantErr:  &ErrValidation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			user := f.register(t, "alice", "password1")
+			before := f.login(t, "alice", "password1")
+			token := tt.token(t, f, user.ID)
+
+			err := f.users.ResetPassword(f.ctx, token, tt.password)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("ResetPassword() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, ok := tt.wantErr.(*ErrValidation); ok {
+					// A rejected password does not use the token up
+					if err := f.users.ResetPassword(f.ctx, token, "password2"); err != nil {
+						t.Errorf("ResetPassword() retried with a valid password error = %v", err)
+					}
+				}
+				return
+			}
+
+			f.login(t, "alice", tt.password)
+			if _, err := f.users.Authenticate(f.ctx, before.AccessToken); !sameError(err, &ErrInvalidToken{}) {
+				t.Errorf("Authenticate() with a token from before the reset error = %v, want invalid_token", err)
+			}
+		})
+	}
+}
+
+// issueReset issues a password reset token for userID.
+func issueReset(t *testing.T, f *userFixture, userID string) string {
+	t.Helper()
+	reset, err := f.users.IssuePasswordReset(f.ctx, userID)
+	if err != nil {
+		t.Fatalf("IssuePasswordReset() error = %v", err)
+	}
+	return reset.ResetToken
+}
+
+func TestPasswordChangeRequired(t *testing.T) {
+	tests := []struct {
+		name string
+		// next returns the tokens the bootstrap admin continues with after logging in
+		next         func(t *testing.T, f *userFixture, tokens models.TokenPair) models.TokenPair
+		wantRequired bool
+	}{
+		{
+			name: "first login",
+			next: func(t *testing.T, f *userFixture, tokens models.TokenPair) models.TokenPair {
+				return tokens
+			},
+			wantRequired: true,
+		},
+		{
+			name: "refreshed tokens",
+			next: func(t *testing.T, f *userFixture, tokens models.TokenPair) models.TokenPair {
+				refreshed, err := f.users.Refresh(f.ctx, tokens.RefreshToken)
+				if err != nil {
+					t.Fatalf("Refresh() error = %v", err)
+				}
+				return refreshed
+			},
+			wantRequired: true,
+		},
+		{
+			name: "after changing the password",
+			next: func(t *testing.T, f *userFixture, tokens models.TokenPair) models.TokenPair {
+				changed, err := f.users.ChangePassword(f.ctx, "1", "Bootstrap1", "password2")
+				if err != nil {
+					t.Fatalf("ChangePassword() error = %v", err)
+				}
+				return changed
+			},
+		},
+		{
+			name: "after a password reset",
+			next: func(t *testing.T, f *userFixture, tokens models.TokenPair) models.TokenPair {
+				if err := f.users.ResetPassword(f.ctx, issueReset(t, f, "1"), "password2"); err != nil {
+					t.Fatalf("ResetPassword() error = %v", err)
+				}
+				return f.login(t, "admin", "password2")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			// Stored the way the database bootstraps the default admin
+			hash, err := bcrypt.GenerateFromPassword([]byte("Bootstrap1"), bcrypt.MinCost)
+			if err != nil {
+				t.Fatalf("hashing password: %v", err)
+			}
+			admin := models.User{ID: "1", Username: "admin", PasswordHash: string(hash), Role: models.RoleAdmin, MustChangePassword: true}
+			if err := f.repos.Users.Create(f.ctx, admin); err != nil {
+				t.Fatalf("storing admin: %v", err)
+			}
+
+			tokens := tt.next(t, f, f.login(t, "admin", "Bootstrap1"))
+			if tokens.PasswordChangeRequired != tt.wantRequired {
+				t.Errorf("token pair password change required = %t, want %t", tokens.PasswordChangeRequired, tt.wantRequired)
+			}
+			if claims := f.authenticate(t, tokens.AccessToken); claims.PasswordChangeRequired != tt.wantRequired {
+				t.Errorf("claims password change required = %t, want %t", claims.PasswordChangeRequired, tt.wantRequired)
+			}
+		})
+	}
+}
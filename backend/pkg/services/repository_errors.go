@@ -0,0 +1,27 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/repository"
+	"errors"
+)
+
+// fromRepository translates the errors repositories report into this package's errors:
+// repository.ErrNotFound becomes notFound, and claimed seats and archived parents become
+// ErrSeatsAlreadyBooked and ErrArchived. Other errors are returned unchanged.
+func fromRepository(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, repository.ErrNotFound) && notFound != nil {
+		return notFound
+	}
+	var seatTaken *repository.ErrSeatTaken
+	if errors.As(err, &seatTaken) {
+		return &ErrSeatsAlreadyBooked{SeatIDs: []string{seatTaken.SeatID}}
+	}
+	var parentArchived *repository.ErrParentArchived
+	if errors.As(err, &parentArchived) {
+		return &ErrArchived{Entity: parentArchived.Entity}
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"strings"
+)
+
+// Permission allows a user to perform one kind of action. Users get permissions through their
+// role, and access tokens carry them so routes can be guarded without a lookup.
+type Permission string
+
+const (
+	// PermBookSeats allows holding and booking seats and cancelling one's own bookings.
+	PermBookSeats Permission = "bookings:create"
+	// PermViewBookings allows listing every booking of a show.
+	PermViewBookings Permission = "bookings:view"
+	// PermCancelBookings allows cancelling other users' bookings.
+	PermCancelBookings Permission = "bookings:cancel"
+	// PermManageMovies allows creating, updating, archiving and deleting movies.
+	PermManageMovies Permission = "movies:manage"
+	// PermManageTheatres allows creating, updating, archiving and deleting theatres.
+	PermManageTheatres Permission = "theatres:manage"
+	// PermManageHalls allows creating, updating, archiving and deleting halls.
+	PermManageHalls Permission = "halls:manage"
+	// PermManageShows allows scheduling, rescheduling, cancelling and deleting shows.
+	PermManageShows Permission = "shows:manage"
+	// PermViewArchived allows listing archived records.
+	PermViewArchived Permission = "archive:view"
+	// PermViewAnalytics allows viewing revenue analytics.
+	PermViewAnalytics Permission = "analytics:view"
+	// PermManageUsers allows listing users and changing their roles and theatres.
+	PermManageUsers Permission = "users:manage"
+	// PermAllTheatres lifts the theatre scope: without it, staff permissions only apply to the
+	// theatres the user is a member of.
+	PermAllTheatres Permission = "theatres:all"
+)
+
+// rolePermissions maps every role to the permissions it grants. Each role has the
+// permissions of the role before it and more.
+var rolePermissions = func() map[string][]Permission {
+	customer := []Permission{PermBookSeats}
+	boxOffice := withPermissions(customer, PermViewBookings, PermCancelBookings)
+	theatreManager := withPermissions(boxOffice, PermManageHalls, PermManageShows, PermViewArchived, PermViewAnalytics)
+	admin := withPermissions(theatreManager, PermManageMovies, PermManageTheatres, PermManageUsers, PermAllTheatres)
+	return map[string][]Permission{
+		models.RoleCustomer:       customer,
+		models.RoleBoxOffice:      boxOffice,
+		models.RoleTheatreManager: theatreManager,
+		models.RoleAdmin:          admin,
+	}
+}()
+
+// withPermissions returns a new slice of the permissions of base followed by extra.
+func withPermissions(base []Permission, extra ...Permission) []Permission {
+	return append(append([]Permission{}, base...), extra...)
+}
+
+// roles lists the roles from least to most privileged.
+var roles = []string{models.RoleCustomer, models.RoleBoxOffice, models.RoleTheatreManager, models.RoleAdmin}
+
+// IsValidRole reports whether role is one of the defined roles.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolePermissions returns the permissions a role grants; unknown roles grant none.
+func RolePermissions(role string) []Permission {
+	return rolePermissions[role]
+}
+
+// validateRole checks that role is one of the defined roles.
+func validateRole(role string) error {
+	if role == "" {
+		return NewValidationError("role", "is required")
+	}
+	if !IsValidRole(role) {
+		return NewValidationError("role", "must be one of "+strings.Join(roles, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+)
+
+// SeatHoldService defines the interface for temporarily reserving seats before checkout.
+type SeatHoldService interface {
+	// CreateHold reserves seats of a show for the requesting user until the hold expires.
+	// Seats are chosen the same way as in CreateBooking: explicit SeatIDs or a contiguous block of NumSeats.
+	CreateHold(ctx context.Context, request BookingRequest) (models.SeatHold, error)
+	// GetHold retrieves an active hold owned by userID.
+	GetHold(ctx context.Context, holdID string, userID string) (models.SeatHold, error)
+	// ConfirmHold converts an active hold into a booking and releases the hold.
+	ConfirmHold(ctx context.Context, holdID string, userID string) (models.Booking, error)
+	// ReleaseHold gives the held seats back before the hold expires.
+	ReleaseHold(ctx context.Context, holdID string, userID string) error
+	// ReleaseExpiredHolds removes every hold whose expiry has passed and returns how many were removed.
+	ReleaseExpiredHolds(ctx context.Context) (int64, error)
+}
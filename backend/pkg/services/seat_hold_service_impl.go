@@ -0,0 +1,238 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	defaultSeatHoldTTL           = 10 * time.Minute
+	defaultSeatHoldSweepInterval = time.Minute
+)
+
+// getSeatHoldTTL returns how long a seat hold stays active, from the SEAT_HOLD_TTL environment variable
+func getSeatHoldTTL() time.Duration {
+	return getDurationEnv("SEAT_HOLD_TTL", defaultSeatHoldTTL)
+}
+
+// getSeatHoldSweepInterval returns how often expired holds are swept, from the SEAT_HOLD_SWEEP_INTERVAL environment variable
+func getSeatHoldSweepInterval() time.Duration {
+	return getDurationEnv("SEAT_HOLD_SWEEP_INTERVAL", defaultSeatHoldSweepInterval)
+}
+
+// getDurationEnv parses a Go duration (e.g. "10m") from the environment, falling back on missing or invalid values.
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Warning: invalid %s %q, using default %s", key, value, fallback)
+		return fallback
+	}
+	return duration
+}
+
+// ErrHoldNotFound is returned when a hold does not exist or belongs to another user.
+type ErrHoldNotFound struct{}
+
+func (e *ErrHoldNotFound) Error() string {
+	return "seat hold not found"
+}
+
+func (e *ErrHoldNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrHoldNotFound) Code() string    { return "hold_not_found" }
+
+// ErrHoldExpired is returned when a hold is confirmed after its expiry.
+type ErrHoldExpired struct{}
+
+func (e *ErrHoldExpired) Error() string {
+	return "seat hold has expired"
+}
+
+func (e *ErrHoldExpired) Kind() ErrorKind { return KindGone }
+func (e *ErrHoldExpired) Code() string    { return "hold_expired" }
+
+type SeatHoldServiceImpl struct {
+	seatHolds   repository.SeatHoldRepository
+	bookings    repository.BookingRepository
+	shows       repository.ShowRepository
+	hallService HallService
+}
+
+// NewSeatHoldService creates a SeatHoldServiceImpl storing holds in seatHolds. Confirmed
+// holds become bookings in bookings; shows and hallService resolve the show and hall layout
+// a hold is for.
+func NewSeatHoldService(seatHolds repository.SeatHoldRepository, bookings repository.BookingRepository, shows repository.ShowRepository, hallService HallService) *SeatHoldServiceImpl {
+	return &SeatHoldServiceImpl{seatHolds: seatHolds, bookings: bookings, shows: shows, hallService: hallService}
+}
+
+func (s *SeatHoldServiceImpl) CreateHold(ctx context.Context, request BookingRequest) (models.SeatHold, error) {
+	if err := validateBookingRequest(request); err != nil {
+		return models.SeatHold{}, err
+	}
+
+	// 1. Resolve the target show
+	targetShow, err := resolveShow(ctx, s.shows, request)
+	if err != nil {
+		return models.SeatHold{}, err
+	}
+
+	// 2. Drop expired holds for this show so their seats can be claimed again
+	if _, err := s.seatHolds.DeleteExpired(ctx, targetShow.ID, time.Now().UTC()); err != nil {
+		return models.SeatHold{}, fmt.Errorf("could not release expired holds: %w", err)
+	}
+
+	// 3. Choose seats exactly as a booking would
+	hall, err := s.hallService.GetHall(ctx, targetShow.HallID)
+	if err != nil {
+		return models.SeatHold{}, fmt.Errorf("could not get hall %s: %w", targetShow.HallID, err)
+	}
+
+	unavailableSeatIDs, err := getUnavailableSeatIDs(ctx, s.bookings, s.seatHolds, targetShow.ID, "")
+	if err != nil {
+		return models.SeatHold{}, fmt.Errorf("could not get booked seats for show %s: %w", targetShow.ID, err)
+	}
+
+	seatsToHold, err := chooseSeats(hall, unavailableSeatIDs, request, targetShow.Time)
+	if err != nil {
+		return models.SeatHold{}, err
+	}
+
+	seatIDsToHold := make([]string, len(seatsToHold))
+	for i, seat := range seatsToHold {
+		seatIDsToHold[i] = seat.ID
+	}
+
+	// 4. Store the hold; the repository stops two holds, or a hold and a booking, claiming the same seat
+	hold := models.SeatHold{
+		ShowID:    targetShow.ID,
+		UserID:    request.UserID,
+		SeatIDs:   seatIDsToHold,
+		ExpiresAt: time.Now().UTC().Add(getSeatHoldTTL()),
+	}
+
+	_, err = insertWithNewID(func(id string) error {
+		hold.ID = id
+		return s.seatHolds.Create(ctx, hold)
+	})
+	if err != nil {
+		return models.SeatHold{}, fromRepository(err, nil)
+	}
+
+	return hold, nil
+}
+
+func (s *SeatHoldServiceImpl) GetHold(ctx context.Context, holdID string, userID string) (models.SeatHold, error) {
+	hold, err := s.seatHolds.Get(ctx, holdID)
+	if err != nil {
+		return models.SeatHold{}, fromRepository(err, &ErrHoldNotFound{})
+	}
+	if hold.UserID != userID || !hold.ExpiresAt.After(time.Now().UTC()) {
+		return models.SeatHold{}, &ErrHoldNotFound{}
+	}
+	return hold, nil
+}
+
+func (s *SeatHoldServiceImpl) ConfirmHold(ctx context.Context, holdID string, userID string) (models.Booking, error) {
+	hold, err := s.seatHolds.Get(ctx, holdID)
+	if err != nil {
+		return models.Booking{}, fromRepository(err, &ErrHoldNotFound{})
+	}
+	if hold.UserID != userID {
+		return models.Booking{}, &ErrHoldNotFound{}
+	}
+	if !hold.ExpiresAt.After(time.Now().UTC()) {
+		return models.Booking{}, &ErrHoldExpired{}
+	}
+
+	show, err := s.shows.Get(ctx, hold.ShowID)
+	if err != nil {
+		return models.Booking{}, fromRepository(err, &ErrShowNotFound{})
+	}
+	if show.ArchivedAt != nil {
+		return models.Booking{}, &ErrShowNotFound{}
+	}
+	if show.Status == models.ShowStatusCancelled {
+		return models.Booking{}, &ErrShowCancelled{}
+	}
+
+	// Seats are priced by their category at confirmation time
+	hall, err := s.hallService.GetHall(ctx, show.HallID)
+	if err != nil {
+		return models.Booking{}, fmt.Errorf("could not get hall %s: %w", show.HallID, err)
+	}
+	seatsByID := make(map[string]models.Seat)
+	for _, seats := range buildSeatsByRow(hall) {
+		for _, seat := range seats {
+			seatsByID[seat.ID] = seat
+		}
+	}
+	heldSeats := make([]models.Seat, len(hold.SeatIDs))
+	for i, seatID := range hold.SeatIDs {
+		seat, ok := seatsByID[seatID]
+		if !ok {
+			seat = models.Seat{ID: seatID, Category: models.SeatCategoryRegular}
+		}
+		heldSeats[i] = seat
+	}
+
+	// The hold is released and the booking stored together, so the seats move from the
+	// hold to the booking atomically; a hold released or swept meanwhile is not found
+	newBooking := newPricedBooking(show, hold.UserID, heldSeats)
+
+	_, err = insertWithNewID(func(id string) error {
+		newBooking.ID = id
+		return s.bookings.CreateFromHold(ctx, hold.ID, newBooking, time.Now().UTC())
+	})
+	if err != nil {
+		return models.Booking{}, fromRepository(err, &ErrHoldNotFound{})
+	}
+
+	return newBooking, nil
+}
+
+func (s *SeatHoldServiceImpl) ReleaseHold(ctx context.Context, holdID string, userID string) error {
+	hold, err := s.seatHolds.Get(ctx, holdID)
+	if err != nil {
+		return fromRepository(err, &ErrHoldNotFound{})
+	}
+	if hold.UserID != userID {
+		return &ErrHoldNotFound{}
+	}
+
+	return fromRepository(s.seatHolds.Delete(ctx, hold.ID), &ErrHoldNotFound{})
+}
+
+func (s *SeatHoldServiceImpl) ReleaseExpiredHolds(ctx context.Context) (int64, error) {
+	return s.seatHolds.DeleteExpired(ctx, "", time.Now().UTC())
+}
+
+// StartExpirySweeper releases expired holds in the background at the configured interval.
+func (s *SeatHoldServiceImpl) StartExpirySweeper() {
+	interval := getSeatHoldSweepInterval()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			// A sweep that takes longer than the interval is abandoned; the next one picks up where it stopped
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			released, err := s.ReleaseExpiredHolds(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("Error releasing expired seat holds: %v", err)
+				continue
+			}
+			if released > 0 {
+				log.Printf("Released %d expired seat holds", released)
+			}
+		}
+	}()
+	log.Printf("Seat hold sweeper started (ttl %s, interval %s)", getSeatHoldTTL(), interval)
+}
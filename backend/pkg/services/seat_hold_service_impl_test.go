@@ -0,0 +1,168 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCreateHold(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   BookingRequest
+		wantSeats []string
+		wantErr   error
+	}{
+		{
+			name:      "requested seats",
+			request:   BookingRequest{SeatIDs: []string{"2-1-4", "2-1-3"}},
+			wantSeats: []string{"2-1-4", "2-1-3"},
+		},
+		{
+			name:      "contiguous seats around booked and held ones",
+			request:   BookingRequest{NumSeats: 2},
+			wantSeats: []string{"2-1-3", "2-1-4"},
+		},
+		{
+			name:    "booked seat",
+			request: BookingRequest{SeatIDs: []string{"1-2-1"}},
+			wantErr: &ErrSeatsAlreadyBooked{},
+		},
+		{
+			name:    "seat held by someone else",
+			request: BookingRequest{SeatIDs: []string{"1-2-3"}},
+			wantErr: &ErrSeatsAlreadyBooked{},
+		},
+		{
+			name:      "seat of an expired hold",
+			request:   BookingRequest{SeatIDs: []string{"2-1-1"}},
+			wantSeats: []string{"2-1-1"},
+		},
+		{
+			name:    "missing seat",
+			request: BookingRequest{SeatIDs: []string{"2-1-2"}},
+			wantErr: &ErrInvalidSeats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, "2030-01-01T10:00:00Z")
+			f.book(t, show, "carol", "1-1-1", "1-1-2", "1-2-1")
+			f.hold(t, show, "bob", "1-2-2", "1-2-3")
+			expired := models.SeatHold{ID: "expired", ShowID: show.ID, UserID: "bob", SeatIDs: []string{"2-1-1"}, ExpiresAt: time.Now().UTC().Add(-time.Minute)}
+			if err := f.repos.SeatHolds.Create(f.ctx, expired); err != nil {
+				t.Fatalf("storing expired hold: %v", err)
+			}
+
+			request := tt.request
+			request.ShowID = show.ID
+			request.UserID = "alice"
+			hold, err := f.holds.CreateHold(f.ctx, request)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("CreateHold() error = %v, want %T", err, tt.wantErr)
+			}
+			if !slices.Equal(hold.SeatIDs, tt.wantSeats) {
+				t.Errorf("CreateHold() held %v, want %v", hold.SeatIDs, tt.wantSeats)
+			}
+			if tt.wantErr == nil && !hold.ExpiresAt.After(time.Now().UTC()) {
+				t.Errorf("CreateHold() hold expires at %v, want a future time", hold.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestConfirmHold(t *testing.T) {
+	tests := []struct {
+		name string
+		// expired stores the hold with an expiry in the past
+		expired  bool
+		released bool
+		// archived archives the show before the hold is stored, as a hold racing the archive may be
+		archived bool
+		userID   string
+		wantErr  error
+	}{
+		{
+			name:   "own hold",
+			userID: "alice",
+		},
+		{
+			name:    "someone else's hold",
+			userID:  "bob",
+			wantErr: &ErrHoldNotFound{},
+		},
+		{
+			name:    "expired hold",
+			expired: true,
+			userID:  "alice",
+			wantErr: &ErrHoldExpired{},
+		},
+		{
+			name:     "released hold",
+			released: true,
+			userID:   "alice",
+			wantErr:  &ErrHoldNotFound{},
+		},
+		{
+			name:     "archived show",
+			archived: true,
+			userID:   "alice",
+			wantErr:  &ErrShowNotFound{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, "2030-01-01T10:00:00Z")
+			seatIDs := []string{"1-2-1", "1-2-2"}
+			hold := models.SeatHold{ID: "hold", ShowID: show.ID, UserID: "alice", SeatIDs: seatIDs, ExpiresAt: time.Now().UTC().Add(time.Minute)}
+			if tt.expired {
+				hold.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+			}
+			if tt.archived {
+				if err := f.shows.ArchiveShow(f.ctx, show.ID); err != nil {
+					t.Fatalf("ArchiveShow() error = %v", err)
+				}
+			}
+			if err := f.repos.SeatHolds.Create(f.ctx, hold); err != nil {
+				t.Fatalf("storing hold: %v", err)
+			}
+			if tt.released {
+				if err := f.holds.ReleaseHold(f.ctx, hold.ID, "alice"); err != nil {
+					t.Fatalf("ReleaseHold() error = %v", err)
+				}
+			}
+
+			booking, err := f.holds.ConfirmHold(f.ctx, hold.ID, tt.userID)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("ConfirmHold() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !slices.Equal(booking.SeatIDs, seatIDs) || booking.UserID != "alice" || booking.TotalPrice != 200 {
+				t.Errorf("ConfirmHold() = %v for %s at %v, want %v for alice at 200", booking.SeatIDs, booking.UserID, booking.TotalPrice, seatIDs)
+			}
+			if _, err := f.holds.GetHold(f.ctx, hold.ID, "alice"); !sameError(err, &ErrHoldNotFound{}) {
+				t.Errorf("GetHold() after confirming error = %v, want hold_not_found", err)
+			}
+			booked, err := f.repos.Bookings.BookedSeatIDs(f.ctx, show.ID)
+			if err != nil {
+				t.Fatalf("BookedSeatIDs() error = %v", err)
+			}
+			for _, seatID := range seatIDs {
+				if !booked[seatID] {
+					t.Errorf("seat %s not booked after confirming", seatID)
+				}
+			}
+			if _, err := f.holds.ConfirmHold(f.ctx, hold.ID, "alice"); !sameError(err, &ErrHoldNotFound{}) {
+				t.Errorf("ConfirmHold() again error = %v, want hold_not_found", err)
+			}
+		})
+	}
+}
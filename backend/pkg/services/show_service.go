@@ -1,9 +1,32 @@
 package services
 
-import "algoBharat/backend/pkg/models"
+import (
+	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"context"
+)
+
+// ShowFilter narrows down and paginates the show listing. Empty fields are not filtered on.
+type ShowFilter = repository.ShowFilter
 
 // ShowService defines the interface for show-related business logic.
 type ShowService interface {
-	GetShows() ([]models.Show, error)
-	CreateShow(show models.Show) (models.Show, error)
+	// GetShows returns scheduled shows matching the filter, ordered by time.
+	GetShows(ctx context.Context, filter ShowFilter) (models.ShowPage, error)
+	GetShow(ctx context.Context, id string) (models.Show, error)
+	CreateShow(ctx context.Context, show models.Show) (models.Show, error)
+	// UpdateShow reschedules or reprices a show, re-running the overlap check against other shows.
+	UpdateShow(ctx context.Context, id string, show models.Show) (models.Show, error)
+	// CancelShow cancels a show, releasing its seats and flagging its bookings.
+	CancelShow(ctx context.Context, id string) (models.Show, error)
+	// DeleteShow removes a show that has never been booked.
+	DeleteShow(ctx context.Context, id string) error
+	// ArchiveShow hides a show from public listings, keeping its bookings.
+	ArchiveShow(ctx context.Context, id string) error
+	// RestoreShow restores an archived show.
+	RestoreShow(ctx context.Context, id string) (models.Show, error)
+	// GetShowSeats returns every seat of the show's hall with its current availability. Seats of
+	// cancelled shows cannot be listed; archived shows are only found when includeArchived is
+	// set, and none of their seats are available.
+	GetShowSeats(ctx context.Context, showID string, includeArchived bool) (models.ShowSeatAvailability, error)
 }
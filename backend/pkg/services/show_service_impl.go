@@ -1,68 +1,319 @@
 package services
 
 import (
-	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/models"
+	"algoBharat/backend/pkg/repository"
+	"context"
 	"fmt"
 	"log"
-	"math/rand"
-	"strconv"
 	"time"
 )
 
-type ShowServiceImpl struct{}
+// ErrShowOverlap is returned when a show would overlap another show in the same hall.
+type ErrShowOverlap struct {
+	ShowID string
+}
+
+func (e *ErrShowOverlap) Error() string {
+	return fmt.Sprintf("show overlaps with existing show %s in the same hall", e.ShowID)
+}
+
+func (e *ErrShowOverlap) Kind() ErrorKind { return KindConflict }
+func (e *ErrShowOverlap) Code() string    { return "show_overlap" }
+
+func (e *ErrShowOverlap) Details() map[string]interface{} {
+	return map[string]interface{}{"show_id": e.ShowID}
+}
+
+// ErrShowCancelled is returned when acting on a show that has been cancelled.
+type ErrShowCancelled struct{}
+
+func (e *ErrShowCancelled) Error() string {
+	return "show has been cancelled"
+}
+
+func (e *ErrShowCancelled) Kind() ErrorKind { return KindConflict }
+func (e *ErrShowCancelled) Code() string    { return "show_cancelled" }
+
+// ErrShowHasBookings is returned when a change is not allowed because the show already has bookings.
+type ErrShowHasBookings struct {
+	Reason string
+}
+
+func (e *ErrShowHasBookings) Error() string {
+	return fmt.Sprintf("show already has bookings: %s", e.Reason)
+}
+
+func (e *ErrShowHasBookings) Kind() ErrorKind { return KindConflict }
+func (e *ErrShowHasBookings) Code() string    { return "show_has_bookings" }
+
+// ErrInvalidCategoryPrices is returned when a show's category prices do not fit its hall.
+type ErrInvalidCategoryPrices struct {
+	Reason string
+}
+
+func (e *ErrInvalidCategoryPrices) Error() string {
+	return "invalid category prices: " + e.Reason
+}
+
+func (e *ErrInvalidCategoryPrices) Kind() ErrorKind { return KindValidation }
+func (e *ErrInvalidCategoryPrices) Code() string    { return "invalid_category_prices" }
+
+func (e *ErrInvalidCategoryPrices) FieldErrors() []FieldError {
+	return []FieldError{{Field: "category_prices", Message: e.Reason}}
+}
+
+type ShowServiceImpl struct {
+	shows        repository.ShowRepository
+	bookings     repository.BookingRepository
+	seatHolds    repository.SeatHoldRepository
+	movieService MovieService
+	hallService  HallService
+}
+
+// NewShowService creates a ShowServiceImpl storing shows in shows. Bookings and seat holds are
+// read for seat availability; movieService and hallService supply movie durations for the
+// overlap check and hall layouts for pricing and seat maps.
+func NewShowService(shows repository.ShowRepository, bookings repository.BookingRepository, seatHolds repository.SeatHoldRepository, movieService MovieService, hallService HallService) *ShowServiceImpl {
+	return &ShowServiceImpl{
+		shows:        shows,
+		bookings:     bookings,
+		seatHolds:    seatHolds,
+		movieService: movieService,
+		hallService:  hallService,
+	}
+}
+
+const (
+	defaultShowPageLimit = 100
+	maxShowPageLimit     = 500
+)
+
+func (s *ShowServiceImpl) GetShows(ctx context.Context, filter ShowFilter) (models.ShowPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultShowPageLimit
+	}
+	if filter.Limit > maxShowPageLimit {
+		filter.Limit = maxShowPageLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	page := models.ShowPage{
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	var err error
+	if page.Total, err = s.shows.Count(ctx, filter); err != nil {
+		return models.ShowPage{}, err
+	}
+	if page.Shows, err = s.shows.List(ctx, filter); err != nil {
+		return models.ShowPage{}, err
+	}
+
+	return page, nil
+}
+
+func (s *ShowServiceImpl) GetShow(ctx context.Context, id string) (models.Show, error) {
+	show, err := s.shows.Get(ctx, id)
+	return show, fromRepository(err, &ErrShowNotFound{})
+}
 
-func (s *ShowServiceImpl) GetShows() ([]models.Show, error) {
-	rows, err := database.DB.Query("SELECT id, movie_id, hall_id, time, price FROM shows")
+func (s *ShowServiceImpl) CreateShow(ctx context.Context, show models.Show) (models.Show, error) {
+	movie, hall, err := s.validateShow(ctx, show)
 	if err != nil {
-		return nil, err
+		return models.Show{}, err
+	}
+	if err := checkNotArchived("movie", movie.ArchivedAt, nil); err != nil {
+		return models.Show{}, err
+	}
+	if err := checkNotArchived("hall", hall.ArchivedAt, nil); err != nil {
+		return models.Show{}, err
 	}
-	defer rows.Close()
+	show.Time = normalizeShowTime(show.Time)
 
-	var shows []models.Show
-	for rows.Next() {
-		var show models.Show
-		if err := rows.Scan(&show.ID, &show.MovieID, &show.HallID, &show.Time, &show.Price); err != nil {
-			log.Println(err)
-			continue
-		}
-		shows = append(shows, show)
+	// 1. Check for overlaps with existing shows in the same hall
+	if err := s.checkOverlap(ctx, show, ""); err != nil {
+		return models.Show{}, err
+	}
+	if err := s.validateCategoryPrices(ctx, show); err != nil {
+		return models.Show{}, err
+	}
+
+	// 2. If no overlap, proceed with insertion
+	show.Status = models.ShowStatusScheduled
+	_, err = insertWithNewID(func(id string) error {
+		show.ID = id
+		return s.shows.Create(ctx, show)
+	})
+	if err != nil {
+		return models.Show{}, err
+	}
+
+	return show, nil
+}
+
+// UpdateShow reschedules or reprices a show. Existing tickets keep the price they were bought at.
+// Moving a show with active bookings to another hall is refused because seat IDs are hall-specific;
+// changing its time flags the affected bookings instead.
+func (s *ShowServiceImpl) UpdateShow(ctx context.Context, id string, show models.Show) (models.Show, error) {
+	existing, err := s.GetShow(ctx, id)
+	if err != nil {
+		return models.Show{}, err
+	}
+	if existing.Status == models.ShowStatusCancelled {
+		return models.Show{}, &ErrShowCancelled{}
+	}
+	if existing.ArchivedAt != nil {
+		return models.Show{}, &ErrArchived{Entity: "show"}
+	}
+	if err := checkHallAccess(ctx, s.hallService, existing.HallID); err != nil {
+		return models.Show{}, err
+	}
+
+	movie, hall, err := s.validateShow(ctx, show)
+	if err != nil {
+		return models.Show{}, err
+	}
+	if err := checkNotArchived("movie", movie.ArchivedAt, nil); err != nil {
+		return models.Show{}, err
+	}
+	if err := checkNotArchived("hall", hall.ArchivedAt, nil); err != nil {
+		return models.Show{}, err
+	}
+	show.Time = normalizeShowTime(show.Time)
+
+	show.ID = id
+	show.Status = existing.Status
+	if err := s.checkOverlap(ctx, show, id); err != nil {
+		return models.Show{}, err
+	}
+	if err := s.validateCategoryPrices(ctx, show); err != nil {
+		return models.Show{}, err
+	}
+
+	activeBookings, err := s.bookings.CountByShow(ctx, id, models.BookingStatusConfirmed)
+	if err != nil {
+		return models.Show{}, err
+	}
+	if activeBookings > 0 && show.HallID != existing.HallID {
+		return models.Show{}, &ErrShowHasBookings{Reason: "cannot move it to another hall"}
 	}
 
-	return shows, nil
+	var notice string
+	if activeBookings > 0 && !sameShowTime(existing.Time, show.Time) {
+		notice = fmt.Sprintf("Show rescheduled from %s to %s", normalizeShowTime(existing.Time), show.Time)
+	}
+	if err := s.shows.Update(ctx, show, notice); err != nil {
+		return models.Show{}, err
+	}
+	if notice != "" {
+		log.Printf("Show %s rescheduled; flagged %d bookings", id, activeBookings)
+	}
+
+	return show, nil
 }
 
-func (s *ShowServiceImpl) CreateShow(show models.Show) (models.Show, error) {
+// CancelShow marks a show as cancelled, releases its seats and flags its bookings. History is kept.
+func (s *ShowServiceImpl) CancelShow(ctx context.Context, id string) (models.Show, error) {
+	show, err := s.GetShow(ctx, id)
+	if err != nil {
+		return models.Show{}, err
+	}
+	if err := checkHallAccess(ctx, s.hallService, show.HallID); err != nil {
+		return models.Show{}, err
+	}
+	if show.Status == models.ShowStatusCancelled {
+		return models.Show{}, &ErrShowCancelled{}
+	}
+
+	if err := fromRepository(s.shows.Cancel(ctx, id, "Show cancelled"), &ErrShowNotFound{}); err != nil {
+		return models.Show{}, err
+	}
+
+	show.Status = models.ShowStatusCancelled
+	return show, nil
+}
+
+// DeleteShow permanently removes a show. Shows with any bookings must be cancelled instead
+// so that booking history is preserved.
+func (s *ShowServiceImpl) DeleteShow(ctx context.Context, id string) error {
+	if err := s.checkShowAccess(ctx, id); err != nil {
+		return err
+	}
+
+	bookingCount, err := s.bookings.CountByShow(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	if bookingCount > 0 {
+		return &ErrShowHasBookings{Reason: "cancel the show instead of deleting it"}
+	}
+
+	return s.shows.Delete(ctx, id)
+}
+
+// validateShow checks a show's price and time, that its movie and hall exist and that the hall
+// is in the caller's theatre scope. It returns the movie and hall so callers can check them
+// further.
+func (s *ShowServiceImpl) validateShow(ctx context.Context, show models.Show) (movie models.Movie, hall models.Hall, err error) {
+	v := &validator{}
+	v.check(show.Price > 0, "price", "must be greater than 0")
+	v.check(validShowTime(show.Time), "time", "must be an RFC3339 time")
+	err = v.checkReference(show.MovieID, "movie_id", "movie", func() (err error) {
+		movie, err = s.movieService.GetMovie(ctx, show.MovieID)
+		return err
+	})
+	if err != nil {
+		return movie, hall, err
+	}
+	err = v.checkReference(show.HallID, "hall_id", "hall", func() (err error) {
+		hall, err = s.hallService.GetHall(ctx, show.HallID)
+		return err
+	})
+	if err != nil {
+		return movie, hall, err
+	}
+	if err := v.err(); err != nil {
+		return movie, hall, err
+	}
+	return movie, hall, checkTheatreAccess(ctx, hall.TheatreID)
+}
+
+// checkOverlap verifies that show does not overlap any other scheduled show in its hall,
+// ignoring the show with ID excludeShowID.
+func (s *ShowServiceImpl) checkOverlap(ctx context.Context, show models.Show, excludeShowID string) error {
 	// 1. Get movie duration
-	movieService := &MovieServiceImpl{}
-	movie, err := movieService.GetMovie(show.MovieID)
+	movie, err := s.movieService.GetMovie(ctx, show.MovieID)
 	if err != nil {
-		return models.Show{}, fmt.Errorf("could not get movie details: %w", err)
+		return fmt.Errorf("could not get movie details: %w", err)
 	}
 
 	// 2. Parse show time and calculate end time
 	showStartTime, err := time.Parse(time.RFC3339, show.Time)
 	if err != nil {
-		return models.Show{}, fmt.Errorf("invalid show time format: %w", err)
+		return fmt.Errorf("invalid show time format: %w", err)
 	}
 	showEndTime := showStartTime.Add(time.Duration(movie.DurationMinutes) * time.Minute)
 
-	// 3. Check for overlaps with existing shows in the same hall
-	existingShowsRows, err := database.DB.Query("SELECT id, movie_id, time FROM shows WHERE hall_id = ?", show.HallID)
+	// 3. Check for overlaps with existing shows in the same hall, archived ones included
+	existingShows, err := s.shows.List(ctx, repository.ShowFilter{HallID: show.HallID, IncludeArchived: true})
 	if err != nil {
-		return models.Show{}, fmt.Errorf("could not query existing shows: %w", err)
+		return fmt.Errorf("could not query existing shows: %w", err)
 	}
-	defer existingShowsRows.Close()
 
-	for existingShowsRows.Next() {
-		var existingShow models.Show
-		if err := existingShowsRows.Scan(&existingShow.ID, &existingShow.MovieID, &existingShow.Time); err != nil {
-			log.Printf("Error scanning existing show: %v", err)
+	for _, existingShow := range existingShows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if existingShow.ID == excludeShowID {
 			continue
 		}
 
 		// Get existing movie duration
-		existingMovie, err := movieService.GetMovie(existingShow.MovieID)
+		existingMovie, err := s.movieService.GetMovie(ctx, existingShow.MovieID)
 		if err != nil {
 			log.Printf("Could not get existing movie details for show %s: %v", existingShow.ID, err)
 			continue
@@ -77,22 +328,145 @@ func (s *ShowServiceImpl) CreateShow(show models.Show) (models.Show, error) {
 
 		// Check for overlap: (start1 < end2 && end1 > start2)
 		if showStartTime.Before(existingShowEndTime) && showEndTime.After(existingShowStartTime) {
-			return models.Show{}, fmt.Errorf("show overlaps with existing show %s in the same hall", existingShow.ID)
+			return &ErrShowOverlap{ShowID: existingShow.ID}
+		}
+	}
+
+	return nil
+}
+
+// validateCategoryPrices checks that a show's prices are not negative and only name
+// seat categories that exist in its hall.
+func (s *ShowServiceImpl) validateCategoryPrices(ctx context.Context, show models.Show) error {
+	if show.Price < 0 {
+		return &ErrInvalidCategoryPrices{Reason: "price must not be negative"}
+	}
+	if len(show.CategoryPrices) == 0 {
+		return nil
+	}
+
+	hall, err := s.hallService.GetHall(ctx, show.HallID)
+	if err != nil {
+		return fmt.Errorf("could not get hall %s: %w", show.HallID, err)
+	}
+	categories := hallSeatCategories(hall)
+	for category, price := range show.CategoryPrices {
+		if !categories[category] {
+			return &ErrInvalidCategoryPrices{Reason: fmt.Sprintf("hall %s has no %q seats", show.HallID, category)}
+		}
+		if price < 0 {
+			return &ErrInvalidCategoryPrices{Reason: fmt.Sprintf("price for %q must not be negative", category)}
 		}
 	}
+	return nil
+}
 
-	// 4. If no overlap, proceed with insertion
-	show.ID = strconv.Itoa(rand.Intn(1000000))
-	stmt, err := database.DB.Prepare("INSERT INTO shows(id, movie_id, hall_id, time, price) VALUES(?, ?, ?, ?, ?)")
+// seatPrice returns what a seat of the given category costs for a show.
+func seatPrice(show models.Show, category string) float64 {
+	if price, ok := show.CategoryPrices[category]; ok {
+		return price
+	}
+	return show.Price
+}
+
+// GetShowSeats lists the seats of a show as bookings and holds see them: a cancelled show has
+// none to offer, and the free seats of an archived show cannot be booked either.
+func (s *ShowServiceImpl) GetShowSeats(ctx context.Context, showID string, includeArchived bool) (models.ShowSeatAvailability, error) {
+	show, err := s.GetShow(ctx, showID)
 	if err != nil {
-		return models.Show{}, err
+		return models.ShowSeatAvailability{}, err
+	}
+	if show.ArchivedAt != nil && !includeArchived {
+		return models.ShowSeatAvailability{}, &ErrShowNotFound{}
+	}
+	if show.Status == models.ShowStatusCancelled {
+		return models.ShowSeatAvailability{}, &ErrShowCancelled{}
+	}
+	hallID := show.HallID
+
+	hall, err := s.hallService.GetHall(ctx, hallID)
+	if err != nil {
+		return models.ShowSeatAvailability{}, fmt.Errorf("could not get hall %s: %w", hallID, err)
+	}
+
+	bookedSeatIDs, err := s.bookings.BookedSeatIDs(ctx, showID)
+	if err != nil {
+		return models.ShowSeatAvailability{}, fmt.Errorf("could not get booked seats for show %s: %w", showID, err)
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(show.ID, show.MovieID, show.HallID, show.Time, show.Price)
+	heldSeatIDs, err := s.seatHolds.HeldSeatIDs(ctx, showID, "", time.Now().UTC())
 	if err != nil {
+		return models.ShowSeatAvailability{}, fmt.Errorf("could not get held seats for show %s: %w", showID, err)
+	}
+
+	availability := models.ShowSeatAvailability{
+		ShowID: showID,
+		HallID: hallID,
+		Seats:  []models.ShowSeat{},
+	}
+
+	seatsByRow := buildSeatsByRow(hall)
+	for _, rowNum := range sortedRowNums(seatsByRow) {
+		for _, seat := range seatsByRow[rowNum] {
+			status := models.SeatStatusAvailable
+			if bookedSeatIDs[seat.ID] {
+				status = models.SeatStatusBooked
+			} else if heldSeatIDs[seat.ID] {
+				status = models.SeatStatusHeld
+			} else if show.ArchivedAt != nil {
+				status = models.SeatStatusUnavailable
+			}
+
+			switch status {
+			case models.SeatStatusAvailable:
+				availability.Available++
+			case models.SeatStatusBooked:
+				availability.Booked++
+			case models.SeatStatusHeld:
+				availability.Held++
+			}
+
+			availability.Seats = append(availability.Seats, models.ShowSeat{
+				ID:       seat.ID,
+				Row:      seat.Row,
+				Column:   seat.Column,
+				Number:   seat.Number,
+				Category: seat.Category,
+				Price:    seatPrice(show, seat.Category),
+				Status:   status,
+			})
+		}
+	}
+	availability.Total = len(availability.Seats)
+
+	return availability, nil
+}
+
+// ArchiveShow hides a show from public listings, keeping its bookings.
+func (s *ShowServiceImpl) ArchiveShow(ctx context.Context, id string) error {
+	if err := s.checkShowAccess(ctx, id); err != nil {
+		return err
+	}
+	return fromRepository(s.shows.Archive(ctx, id, archiveTime(), refuseActiveBookings), &ErrShowNotFound{})
+}
+
+// RestoreShow restores an archived show.
+func (s *ShowServiceImpl) RestoreShow(ctx context.Context, id string) (models.Show, error) {
+	if err := s.checkShowAccess(ctx, id); err != nil {
+		return models.Show{}, err
+	}
+	if err := fromRepository(s.shows.Restore(ctx, id), &ErrShowNotFound{}); err != nil {
 		return models.Show{}, err
 	}
+	return s.GetShow(ctx, id)
+}
 
-	return show, nil
+// checkShowAccess returns ErrNotTheatreMember unless the caller's theatre scope covers show id,
+// or ErrShowNotFound if there is no such show.
+func (s *ShowServiceImpl) checkShowAccess(ctx context.Context, id string) error {
+	show, err := s.GetShow(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkHallAccess(ctx, s.hallService, show.HallID)
 }
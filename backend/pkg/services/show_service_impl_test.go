@@ -0,0 +1,231 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUpdateShowChecksOverlap(t *testing.T) {
+	// The fixture movie runs for two hours, so the first show ends at 12:00 and the second at 15:00
+	const firstTime, secondTime = "2030-01-01T10:00:00Z", "2030-01-01T13:00:00Z"
+
+	tests := []struct {
+		name        string
+		updateFirst bool
+		time        string
+		price       float64
+		wantErr     error
+		wantOverlap bool
+	}{
+		{
+			name:        "same time overlaps only itself",
+			updateFirst: true,
+			time:        firstTime,
+			price:       150,
+		},
+		{
+			name:        "moved within its own slot",
+			updateFirst: true,
+			time:        "2030-01-01T10:30:00Z",
+			price:       100,
+		},
+		{
+			name:        "moved to end as the other starts",
+			updateFirst: true,
+			time:        "2030-01-01T11:00:00Z",
+			price:       100,
+		},
+		{
+			name:        "moved onto the other show",
+			updateFirst: true,
+			time:        "2030-01-01T12:30:00Z",
+			price:       100,
+			wantErr:     &ErrShowOverlap{},
+			wantOverlap: true,
+		},
+		{
+			name:        "moved after the other show",
+			updateFirst: true,
+			time:        "2030-01-01T15:00:00Z",
+			price:       100,
+		},
+		{
+			name:        "other show moved onto the first",
+			updateFirst: false,
+			time:        "2030-01-01T11:59:00Z",
+			price:       100,
+			wantErr:     &ErrShowOverlap{},
+			wantOverlap: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			first := f.addShow(t, firstTime)
+			second := f.addShow(t, secondTime)
+			updated, other := first, second
+			if !tt.updateFirst {
+				updated, other = second, first
+			}
+
+			show, err := f.shows.UpdateShow(f.ctx, updated.ID, models.Show{MovieID: f.movie.ID, HallID: f.hall.ID, Time: tt.time, Price: tt.price})
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("UpdateShow() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantOverlap {
+				if overlap := err.(*ErrShowOverlap); overlap.ShowID != other.ID {
+					t.Errorf("UpdateShow() overlaps show %s, want %s", overlap.ShowID, other.ID)
+				}
+				return
+			}
+
+			stored, err := f.shows.GetShow(f.ctx, updated.ID)
+			if err != nil {
+				t.Fatalf("GetShow() error = %v", err)
+			}
+			if stored.Time != tt.time || stored.Price != tt.price || show.ID != updated.ID {
+				t.Errorf("UpdateShow() stored %s at %v, want %s at %v", stored.Time, stored.Price, tt.time, tt.price)
+			}
+		})
+	}
+}
+
+func TestGetShowsFiltersByInstant(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    ShowFilter
+		wantTimes []string
+	}{
+		{
+			name:      "all shows in time order",
+			wantTimes: []string{"2030-01-01T08:00:00Z", "2030-01-01T10:00:00Z", "2030-01-01T23:00:00Z"},
+		},
+		{
+			name:      "from is inclusive",
+			filter:    ShowFilter{From: "2030-01-01T10:00:00Z"},
+			wantTimes: []string{"2030-01-01T10:00:00Z", "2030-01-01T23:00:00Z"},
+		},
+		{
+			name:      "to is exclusive",
+			filter:    ShowFilter{To: "2030-01-01T10:00:00Z"},
+			wantTimes: []string{"2030-01-01T08:00:00Z"},
+		},
+		{
+			name:      "one UTC day",
+			filter:    ShowFilter{From: "2030-01-01T09:00:00Z", To: "2030-01-02T00:00:00Z"},
+			wantTimes: []string{"2030-01-01T10:00:00Z", "2030-01-01T23:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			// Written with offsets whose text sorts differently from the instants they name
+			f.addShow(t, "2030-01-01T15:30:00+05:30")
+			f.addShow(t, "2030-01-01T18:00:00-05:00")
+			f.addShow(t, "2030-01-01T09:00:00+01:00")
+
+			page, err := f.shows.GetShows(f.ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("GetShows() error = %v", err)
+			}
+			var times []string
+			for _, show := range page.Shows {
+				times = append(times, show.Time)
+			}
+			if !slices.Equal(times, tt.wantTimes) {
+				t.Errorf("GetShows() = %v, want %v", times, tt.wantTimes)
+			}
+		})
+	}
+}
+
+func TestUpdateShowOfBookedShow(t *testing.T) {
+	const showTime = "2030-01-01T10:00:00Z"
+
+	tests := []struct {
+		name string
+		// movieID and hallID name "archived" for a movie or hall archived before the update
+		movieID    string
+		hallID     string
+		time       string
+		price      float64
+		wantErr    error
+		wantNotice string
+	}{
+		{
+			name:  "price only",
+			time:  showTime,
+			price: 150,
+		},
+		{
+			name:  "same instant at another offset",
+			time:  "2030-01-01T15:30:00+05:30",
+			price: 100,
+		},
+		{
+			name:       "rescheduled",
+			time:       "2030-01-01T15:30:00+01:00",
+			price:      100,
+			wantNotice: "Show rescheduled from 2030-01-01T10:00:00Z to 2030-01-01T14:30:00Z",
+		},
+		{
+			name:    "archived movie",
+			movieID: "archived",
+			time:    showTime,
+			price:   100,
+			wantErr: &ErrArchived{},
+		},
+		{
+			name:    "archived hall",
+			hallID:  "archived",
+			time:    showTime,
+			price:   100,
+			wantErr: &ErrArchived{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture(t)
+			show := f.addShow(t, showTime)
+			booking := f.book(t, show, "alice", "1-1-1")
+			archived := time.Now().UTC()
+			if err := f.repos.Movies.Create(f.ctx, models.Movie{ID: "archived", Title: "Archived Movie", DurationMinutes: 60}); err != nil {
+				t.Fatalf("storing movie: %v", err)
+			}
+			if err := f.repos.Movies.Archive(f.ctx, "archived", archived, nil); err != nil {
+				t.Fatalf("archiving movie: %v", err)
+			}
+			if err := f.repos.Halls.Create(f.ctx, models.Hall{ID: "archived", Name: "Archived Hall", TheatreID: f.hall.TheatreID}, nil); err != nil {
+				t.Fatalf("storing hall: %v", err)
+			}
+			if err := f.repos.Halls.Archive(f.ctx, "archived", archived, nil); err != nil {
+				t.Fatalf("archiving hall: %v", err)
+			}
+
+			update := models.Show{MovieID: f.movie.ID, HallID: f.hall.ID, Time: tt.time, Price: tt.price}
+			if tt.movieID != "" {
+				update.MovieID = tt.movieID
+			}
+			if tt.hallID != "" {
+				update.HallID = tt.hallID
+			}
+			_, err := f.shows.UpdateShow(f.ctx, show.ID, update)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("UpdateShow() error = %v, want %T", err, tt.wantErr)
+			}
+
+			stored, err := f.repos.Bookings.Get(f.ctx, booking.ID)
+			if err != nil {
+				t.Fatalf("getting booking: %v", err)
+			}
+			if stored.Notice != tt.wantNotice {
+				t.Errorf("booking notice = %q, want %q", stored.Notice, tt.wantNotice)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// legacyKeyID is the key ID of a key configured with JWT_SECRET instead of JWT_KEYS.
+	legacyKeyID = "default"
+	// minSecretBytes is the shortest HS256 secret accepted in production.
+	minSecretBytes = 32
+	// minRSABits is the smallest RSA key accepted.
+	minRSABits = 2048
+)
+
+// placeholderSecrets are the example secrets from the docs, which must never sign real tokens.
+var placeholderSecrets = []string{"my_secret_key", "your-secret-key-here", "your-very-secure-secret-key-here"}
+
+// signingKey is one key access tokens are signed or verified with.
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// signKey is nil for keys that only verify, such as the public half of a retired key pair
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// SigningKeys holds every key access tokens are accepted with, named by the kid header of the
+// tokens, and the one new tokens are signed with. Keeping the previous key while a new one
+// signs lets keys be rotated without logging anyone out.
+type SigningKeys struct {
+	current *signingKey
+	keys    map[string]*signingKey
+	// ids lists the keys in configuration order, so the JWKS is stable
+	ids []string
+}
+
+// isProduction reports whether APP_ENV says the server runs in production, where insecure
+// defaults are refused.
+func isProduction() bool {
+	return strings.EqualFold(os.Getenv("APP_ENV"), "production")
+}
+
+// LoadSigningKeys reads the signing keys from the environment. JWT_KEYS lists them as kid=source
+// entries separated by whitespace, where source is file:<path> for a PEM encoded RSA or Ed25519
+// key, or else an HS256 secret. Private keys sign and verify; public keys only verify. Sources
+// may not contain commas, so a list in the old comma-separated format fails loudly instead of
+// becoming one key with a secret that runs into the next entry.
+// JWT_SIGNING_KEY_ID picks the key that signs, by default the first. A lone JWT_SECRET is
+// still accepted as the HS256 key "default".
+//
+// In production (APP_ENV=production) it is an error to configure no key, a short secret or a
+// placeholder one. Elsewhere a random key is generated instead, so tokens do not survive a restart.
+func LoadSigningKeys() (*SigningKeys, error) {
+	production := isProduction()
+	keysEnv := strings.TrimSpace(os.Getenv("JWT_KEYS"))
+	secret := os.Getenv("JWT_SECRET")
+
+	var entries []string
+	switch {
+	case keysEnv != "" && secret != "":
+		return nil, errors.New("set either JWT_KEYS or JWT_SECRET, not both")
+	case keysEnv != "":
+		entries = strings.Fields(keysEnv)
+	case secret != "":
+		entries = []string{legacyKeyID + "=" + secret}
+	case production:
+		return nil, errors.New("JWT_KEYS or JWT_SECRET must be set when APP_ENV=production")
+	default:
+		value := make([]byte, minSecretBytes)
+		if _, err := rand.Read(value); err != nil {
+			return nil, err
+		}
+		log.Println("Warning: no JWT_KEYS or JWT_SECRET set; signing tokens with a random key that is lost on restart")
+		entries = []string{"dev-" + hex.EncodeToString(value[:4]) + "=" + hex.EncodeToString(value)}
+	}
+
+	keys := &SigningKeys{keys: map[string]*signingKey{}}
+	for i, entry := range entries {
+		id, source, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || id == "" || source == "" {
+			// The entry is not quoted, since it may be part of a secret
+			return nil, fmt.Errorf("invalid JWT_KEYS entry %d: want kid=secret or kid=file:<path>", i+1)
+		}
+		if keysEnv != "" && strings.Contains(source, ",") {
+			return nil, fmt.Errorf("JWT key %q contains a comma: separate JWT_KEYS entries with spaces, and set a secret containing commas with JWT_SECRET", id)
+		}
+		if _, exists := keys.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate JWT key ID %q", id)
+		}
+		key, err := parseSigningKey(id, source, production)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		keys.keys[id] = key
+		keys.ids = append(keys.ids, id)
+	}
+
+	currentID := os.Getenv("JWT_SIGNING_KEY_ID")
+	if currentID == "" {
+		currentID = keys.ids[0]
+	}
+	current, ok := keys.keys[currentID]
+	if !ok {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_ID %q is not one of JWT_KEYS", currentID)
+	}
+	if current.signKey == nil {
+		return nil, fmt.Errorf("JWT key %q is a public key and cannot sign tokens", currentID)
+	}
+	keys.current = current
+	return keys, nil
+}
+
+// parseSigningKey parses the source of key id: file:<path> for a PEM file, otherwise an HS256 secret.
+func parseSigningKey(id string, source string, production bool) (*signingKey, error) {
+	path, isFile := strings.CutPrefix(source, "file:")
+	if !isFile {
+		if err := checkSecret(source, production); err != nil {
+			return nil, err
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodHS256, signKey: []byte(source), verifyKey: []byte(source)}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodRS256, verifyKey: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{id: id, method: jwt.SigningMethodEdDSA, signKey: key, verifyKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &signingKey{id: id, method: jwt.SigningMethodEdDSA, verifyKey: key}, nil
+	default:
+		return nil, fmt.Errorf("%s holds a %T; only RSA and Ed25519 keys are supported", path, parsed)
+	}
+}
+
+// checkSecret refuses placeholder HS256 secrets and, in production, short ones.
+func checkSecret(secret string, production bool) error {
+	for _, placeholder := range placeholderSecrets {
+		if secret == placeholder {
+			if production {
+				return errors.New("the example secret from the docs cannot be used in production")
+			}
+			log.Println("Warning: the JWT secret is the example from the docs; anyone can forge tokens with it")
+			return nil
+		}
+	}
+	if len(secret) < minSecretBytes {
+		if production {
+			return fmt.Errorf("HS256 secrets must be at least %d bytes in production", minSecretBytes)
+		}
+		log.Printf("Warning: the JWT secret is shorter than %d bytes", minSecretBytes)
+	}
+	return nil
+}
+
+// sign returns claims signed with the current key, naming it in the kid header.
+func (k *SigningKeys) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.method, claims)
+	token.Header["kid"] = k.current.id
+	return token.SignedString(k.current.signKey)
+}
+
+// verifyKey returns the key to verify token with: the one its kid header names, provided
+// the token uses that key's algorithm. Checking the algorithm stops a public key from being
+// passed off as an HS256 secret.
+func (k *SigningKeys) verifyKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", id)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("key %q does not use %s", id, token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// methods returns the algorithms of the keys, the only ones tokens may be signed with.
+func (k *SigningKeys) methods() []string {
+	var methods []string
+	seen := map[string]bool{}
+	for _, id := range k.ids {
+		if alg := k.keys[id].method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS returns the public keys as a JSON Web Key Set, so other services can verify tokens.
+// HS256 secrets are never published; services sharing one must be given it directly.
+func (k *SigningKeys) JWKS() models.JSONWebKeySet {
+	set := models.JSONWebKeySet{Keys: []models.JSONWebKey{}}
+	for _, id := range k.ids {
+		key := k.keys[id]
+		jwk := models.JSONWebKey{KeyID: id, Use: "sig", Algorithm: key.method.Alg()}
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// testKeyFiles holds PEM files of an RSA and an Ed25519 key pair, generated once because RSA
+// keys are slow to generate.
+type testKeyFiles struct {
+	rsaPrivate, rsaPublic, edPrivate, edPublic string
+	// rsaPublicPEM is the PEM encoded RSA public key, which anyone can fetch
+	rsaPublicPEM []byte
+}
+
+// newTestKeyFiles generates the key pairs and writes them to a temporary directory.
+func newTestKeyFiles(t *testing.T) testKeyFiles {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minRSABits)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating Ed25519 key: %v", err)
+	}
+	edPrivateDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatalf("encoding Ed25519 key: %v", err)
+	}
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	if err != nil {
+		t.Fatalf("encoding Ed25519 public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	write := func(name string, block *pem.Block) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+		return path
+	}
+	rsaPublic := &pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}
+	return testKeyFiles{
+		rsaPrivate:   write("rsa.pem", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		rsaPublic:    write("rsa.pub.pem", rsaPublic),
+		edPrivate:    write("ed.pem", &pem.Block{Type: "PRIVATE KEY", Bytes: edPrivateDER}),
+		edPublic:     write("ed.pub.pem", &pem.Block{Type: "PUBLIC KEY", Bytes: edPublicDER}),
+		rsaPublicPEM: pem.EncodeToMemory(rsaPublic),
+	}
+}
+
+// setSigningKeyEnv sets the signing key environment of a test; empty values are unset.
+func setSigningKeyEnv(t *testing.T, appEnv string, keys string, secret string, signingKeyID string) {
+	t.Setenv("APP_ENV", appEnv)
+	t.Setenv("JWT_KEYS", keys)
+	t.Setenv("JWT_SECRET", secret)
+	t.Setenv("JWT_SIGNING_KEY_ID", signingKeyID)
+}
+
+func TestLoadSigningKeys(t *testing.T) {
+	files := newTestKeyFiles(t)
+	const secret = "a-secret-that-is-at-least-32-bytes-long"
+
+	tests := []struct {
+		name         string
+		appEnv       string
+		keys         string
+		secret       string
+		signingKeyID string
+		wantIDs      []string
+		wantCurrent  string
+		wantErr      bool
+	}{
+		{
+			name:        "secret and key pair separated by a space",
+			keys:        "hs=" + secret + " rsa=file:" + files.rsaPrivate,
+			wantIDs:     []string{"hs", "rsa"},
+			wantCurrent: "hs",
+		},
+		{
+			name:         "entries on separate lines, signing with the second",
+			keys:         "rsa=file:" + files.rsaPublic + "\ned=file:" + files.edPrivate + "\n",
+			signingKeyID: "ed",
+			wantIDs:      []string{"rsa", "ed"},
+			wantCurrent:  "ed",
+		},
+		{
+			name:    "comma-separated entries",
+			keys:    "hs=" + secret + ",rsa=file:" + files.rsaPrivate,
+			wantErr: true,
+		},
+		{
+			name:    "secret containing a comma",
+			keys:    "hs=" + secret + ",more",
+			wantErr: true,
+		},
+		{
+			name:        "JWT_SECRET containing a comma",
+			secret:      secret + ",more",
+			wantIDs:     []string{legacyKeyID},
+			wantCurrent: legacyKeyID,
+		},
+		{
+			name:    "JWT_KEYS and JWT_SECRET together",
+			keys:    "hs=" + secret,
+			secret:  secret,
+			wantErr: true,
+		},
+		{
+			name:    "entry without a key ID",
+			keys:    "=" + secret,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate key ID",
+			keys:    "hs=" + secret + " hs=file:" + files.rsaPrivate,
+			wantErr: true,
+		},
+		{
+			name:         "signing key not listed",
+			keys:         "hs=" + secret,
+			signingKeyID: "rsa",
+			wantErr:      true,
+		},
+		{
+			name:         "signing with a public key",
+			keys:         "hs=" + secret + " rsa=file:" + files.rsaPublic,
+			signingKeyID: "rsa",
+			wantErr:      true,
+		},
+		{
+			name:    "missing key file",
+			keys:    "rsa=file:" + files.rsaPrivate + ".missing",
+			wantErr: true,
+		},
+		{
+			name:    "no keys in production",
+			appEnv:  "production",
+			wantErr: true,
+		},
+		{
+			name:    "short secret in production",
+			appEnv:  "production",
+			keys:    "hs=short",
+			wantErr: true,
+		},
+		{
+			name:    "example secret in production",
+			appEnv:  "Production",
+			secret:  "your-secret-key-here",
+			wantErr: true,
+		},
+		{
+			name:        "key pair in production",
+			appEnv:      "production",
+			keys:        "ed=file:" + files.edPrivate,
+			wantIDs:     []string{"ed"},
+			wantCurrent: "ed",
+		},
+		{
+			name:        "short secret outside production",
+			keys:        "hs=short",
+			wantIDs:     []string{"hs"},
+			wantCurrent: "hs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSigningKeyEnv(t, tt.appEnv, tt.keys, tt.secret, tt.signingKeyID)
+
+			keys, err := LoadSigningKeys()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSigningKeys() error = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				if strings.Contains(err.Error(), secret) {
+					t.Errorf("LoadSigningKeys() error %q reveals the secret", err)
+				}
+				return
+			}
+			if !slices.Equal(keys.ids, tt.wantIDs) || keys.current.id != tt.wantCurrent {
+				t.Errorf("LoadSigningKeys() = keys %v signing with %s, want %v signing with %s", keys.ids, keys.current.id, tt.wantIDs, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestLoadSigningKeysGeneratesKeyOutsideProduction(t *testing.T) {
+	setSigningKeyEnv(t, "", "", "", "")
+
+	keys, err := LoadSigningKeys()
+	if err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+	if len(keys.ids) != 1 || !strings.HasPrefix(keys.current.id, "dev-") || keys.current.method != jwt.SigningMethodHS256 {
+		t.Errorf("LoadSigningKeys() = keys %v signing with %s, want one random HS256 key", keys.ids, keys.current.id)
+	}
+}
+
+func TestParseAccessTokenKeys(t *testing.T) {
+	files := newTestKeyFiles(t)
+	const secret = "a-secret-that-is-at-least-32-bytes-long"
+	setSigningKeyEnv(t, "production", "rsa=file:"+files.rsaPrivate+" ed=file:"+files.edPrivate+" hs="+secret, "", "")
+	keys, err := LoadSigningKeys()
+	if err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		// kid is the header naming the key; empty leaves it out
+		kid     string
+		signKey interface{}
+		wantErr bool
+	}{
+		{
+			name:    "RS256 with its key",
+			method:  jwt.SigningMethodRS256,
+			kid:     "rsa",
+			signKey: keys.keys["rsa"].signKey,
+		},
+		{
+			name:    "EdDSA with a key that is not signing new tokens",
+			method:  jwt.SigningMethodEdDSA,
+			kid:     "ed",
+			signKey: keys.keys["ed"].signKey,
+		},
+		{
+			name:    "HS256 with its secret",
+			method:  jwt.SigningMethodHS256,
+			kid:     "hs",
+			signKey: []byte(secret),
+		},
+		{
+			name:    "unknown key ID",
+			method:  jwt.SigningMethodHS256,
+			kid:     "retired",
+			signKey: []byte(secret),
+			wantErr: true,
+		},
+		{
+			name:    "no key ID",
+			method:  jwt.SigningMethodHS256,
+			signKey: []byte(secret),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 signed with the RSA public key",
+			method:  jwt.SigningMethodHS256,
+			kid:     "rsa",
+			signKey: files.rsaPublicPEM,
+			wantErr: true,
+		},
+		{
+			name:    "RS256 naming the HS256 key",
+			method:  jwt.SigningMethodRS256,
+			kid:     "hs",
+			signKey: keys.keys["rsa"].signKey,
+			wantErr: true,
+		},
+		{
+			name:    "unsigned",
+			method:  jwt.SigningMethodNone,
+			kid:     "hs",
+			signKey: jwt.UnsafeAllowNoneSignatureType,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "token",
+				Subject:   "1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			}}
+			token := jwt.NewWithClaims(tt.method, claims)
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+			signed, err := token.SignedString(tt.signKey)
+			if err != nil {
+				t.Fatalf("signing token: %v", err)
+			}
+
+			parsed, err := parseAccessToken(keys, signed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAccessToken() error = %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && parsed.Subject != "1" {
+				t.Errorf("parseAccessToken() subject = %s, want 1", parsed.Subject)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	files := newTestKeyFiles(t)
+	setSigningKeyEnv(t, "", "hs=a-secret-that-is-at-least-32-bytes-long rsa=file:"+files.rsaPrivate+" ed=file:"+files.edPublic, "", "")
+	keys, err := LoadSigningKeys()
+	if err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+
+	set := keys.JWKS()
+	var published []string
+	for _, key := range set.Keys {
+		published = append(published, key.KeyID+":"+key.KeyType+":"+key.Algorithm)
+	}
+	if want := []string{"rsa:RSA:RS256", "ed:OKP:EdDSA"}; !slices.Equal(published, want) {
+		t.Fatalf("JWKS() = %v, want %v", published, want)
+	}
+
+	public := keys.keys["rsa"].verifyKey.(*rsa.PublicKey)
+	if rsaKey := set.Keys[0]; rsaKey.N == "" || rsaKey.E != "AQAB" || rsaKey.N != base64.RawURLEncoding.EncodeToString(public.N.Bytes()) {
+		t.Errorf("JWKS() RSA key n = %q, e = %q, want the public modulus and exponent", rsaKey.N, rsaKey.E)
+	}
+	if edKey := set.Keys[1]; edKey.Curve != "Ed25519" || edKey.X != base64.RawURLEncoding.EncodeToString(keys.keys["ed"].verifyKey.(ed25519.PublicKey)) {
+		t.Errorf("JWKS() Ed25519 key crv = %q, x = %q, want the public key", edKey.Curve, edKey.X)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// TheatreScope lists the theatres a request may manage. Staff act only on the theatres they
+// are members of; users with PermAllTheatres act on every theatre.
+type TheatreScope struct {
+	All        bool
+	TheatreIDs []string
+}
+
+// theatreScopeKey is the context key of the TheatreScope.
+type theatreScopeKey struct{}
+
+// WithTheatreScope returns a copy of ctx carrying scope. The auth middleware stores the scope
+// of the authenticated user, taken from their token.
+func WithTheatreScope(ctx context.Context, scope TheatreScope) context.Context {
+	return context.WithValue(ctx, theatreScopeKey{}, scope)
+}
+
+// theatreScope returns the scope stored in ctx. Without one no theatre may be managed, so a
+// request that skipped authentication is refused rather than let through.
+func theatreScope(ctx context.Context) TheatreScope {
+	scope, _ := ctx.Value(theatreScopeKey{}).(TheatreScope)
+	return scope
+}
+
+// includes reports whether the scope covers theatreID.
+func (s TheatreScope) includes(theatreID string) bool {
+	if s.All {
+		return true
+	}
+	for _, id := range s.TheatreIDs {
+		if id == theatreID {
+			return true
+		}
+	}
+	return false
+}
+
+// theatreFilter returns the theatres to limit a report to: nil for every theatre, otherwise
+// the scope's theatres, which may be none.
+func (s TheatreScope) theatreFilter() []string {
+	if s.All {
+		return nil
+	}
+	return append([]string{}, s.TheatreIDs...)
+}
+
+// ErrNotTheatreMember is returned when staff act on a theatre they are not a member of.
+type ErrNotTheatreMember struct {
+	TheatreID string
+}
+
+func (e *ErrNotTheatreMember) Error() string {
+	return fmt.Sprintf("you are not a member of theatre %s", e.TheatreID)
+}
+
+func (e *ErrNotTheatreMember) Kind() ErrorKind { return KindForbidden }
+func (e *ErrNotTheatreMember) Code() string    { return "not_theatre_member" }
+
+// checkTheatreAccess returns ErrNotTheatreMember unless the scope of ctx covers theatreID.
+func checkTheatreAccess(ctx context.Context, theatreID string) error {
+	if !theatreScope(ctx).includes(theatreID) {
+		return &ErrNotTheatreMember{TheatreID: theatreID}
+	}
+	return nil
+}
+
+// checkHallAccess returns ErrNotTheatreMember unless the caller's theatre scope covers the
+// theatre of hall hallID, or ErrHallNotFound if there is no such hall.
+func checkHallAccess(ctx context.Context, hallService HallService, hallID string) error {
+	if theatreScope(ctx).All {
+		return nil
+	}
+	hall, err := hallService.GetHall(ctx, hallID)
+	if err != nil {
+		return err
+	}
+	return checkTheatreAccess(ctx, hall.TheatreID)
+}
@@ -1,12 +1,22 @@
 package services
 
-import "algoBharat/backend/pkg/models"
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+)
 
 // TheatreService defines the interface for theatre-related business logic.
 type TheatreService interface {
-	GetTheatres() ([]models.Theatre, error)
-	GetTheatre(id string) (models.Theatre, error)
-	CreateTheatre(theatre models.Theatre) (models.Theatre, error)
-	UpdateTheatre(id string, theatre models.Theatre) (models.Theatre, error)
-	DeleteTheatre(id string) error
+	// GetTheatres lists theatres; archived theatres are only included when includeArchived is set.
+	GetTheatres(ctx context.Context, includeArchived bool) ([]models.Theatre, error)
+	// GetTheatre returns a theatre, including an archived one.
+	GetTheatre(ctx context.Context, id string) (models.Theatre, error)
+	CreateTheatre(ctx context.Context, theatre models.Theatre) (models.Theatre, error)
+	UpdateTheatre(ctx context.Context, id string, theatre models.Theatre) (models.Theatre, error)
+	// DeleteTheatre deletes a theatre with its halls, shows and bookings, or only counts them in a dry run.
+	DeleteTheatre(ctx context.Context, id string, opts DeleteOptions) (models.DeletionSummary, error)
+	// ArchiveTheatre hides a theatre with its halls and shows from public listings, keeping their bookings.
+	ArchiveTheatre(ctx context.Context, id string) error
+	// RestoreTheatre restores an archived theatre and the halls and shows archived with it.
+	RestoreTheatre(ctx context.Context, id string) (models.Theatre, error)
 }
@@ -1,54 +1,40 @@
 package services
 
 import (
-	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/models"
-	"fmt"
+	"algoBharat/backend/pkg/repository"
+	"context"
 	"log"
-	"math/rand"
-	"strconv"
 )
 
-type TheatreServiceImpl struct{}
-
-func (s *TheatreServiceImpl) GetTheatres() ([]models.Theatre, error) {
-	rows, err := database.DB.Query("SELECT id, name FROM theatres")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var theatres []models.Theatre
-	for rows.Next() {
-		var theatre models.Theatre
-		if err := rows.Scan(&theatre.ID, &theatre.Name); err != nil {
-			continue
-		}
-		theatres = append(theatres, theatre)
-	}
-
-	return theatres, nil
+type TheatreServiceImpl struct {
+	theatres repository.TheatreRepository
 }
 
-func (s *TheatreServiceImpl) GetTheatre(id string) (models.Theatre, error) {
-	row := database.DB.QueryRow("SELECT id, name FROM theatres WHERE id = ?", id)
-
-	var theatre models.Theatre
-	if err := row.Scan(&theatre.ID, &theatre.Name); err != nil {
-		return models.Theatre{}, err
-	}
+// NewTheatreService creates a TheatreServiceImpl storing theatres in theatres.
+func NewTheatreService(theatres repository.TheatreRepository) *TheatreServiceImpl {
+	return &TheatreServiceImpl{theatres: theatres}
+}
 
-	return theatre, nil
+// GetTheatres lists theatres; archived theatres are only included when includeArchived is set.
+func (s *TheatreServiceImpl) GetTheatres(ctx context.Context, includeArchived bool) ([]models.Theatre, error) {
+	return s.theatres.List(ctx, includeArchived)
 }
 
-func (s *TheatreServiceImpl) CreateTheatre(theatre models.Theatre) (models.Theatre, error) {
-	theatre.ID = strconv.Itoa(rand.Intn(1000000))
+// GetTheatre returns a theatre, including an archived one.
+func (s *TheatreServiceImpl) GetTheatre(ctx context.Context, id string) (models.Theatre, error) {
+	theatre, err := s.theatres.Get(ctx, id)
+	return theatre, fromRepository(err, &ErrTheatreNotFound{})
+}
 
-	stmt, err := database.DB.Prepare("INSERT INTO theatres(id, name) VALUES(?, ?)")
-	if err != nil {
+func (s *TheatreServiceImpl) CreateTheatre(ctx context.Context, theatre models.Theatre) (models.Theatre, error) {
+	if err := validateTheatre(theatre); err != nil {
 		return models.Theatre{}, err
 	}
-	_, err = stmt.Exec(theatre.ID, theatre.Name)
+	_, err := insertWithNewID(func(id string) error {
+		theatre.ID = id
+		return s.theatres.Create(ctx, theatre)
+	})
 	if err != nil {
 		return models.Theatre{}, err
 	}
@@ -56,158 +42,45 @@ func (s *TheatreServiceImpl) CreateTheatre(theatre models.Theatre) (models.Theat
 	return theatre, nil
 }
 
-func (s *TheatreServiceImpl) UpdateTheatre(id string, theatre models.Theatre) (models.Theatre, error) {
-	stmt, err := database.DB.Prepare("UPDATE theatres SET name = ? WHERE id = ?")
-	if err != nil {
+// UpdateTheatre changes a theatre, failing with ErrTheatreNotFound if there is no such theatre, and
+// returns it as stored.
+func (s *TheatreServiceImpl) UpdateTheatre(ctx context.Context, id string, theatre models.Theatre) (models.Theatre, error) {
+	if err := validateTheatre(theatre); err != nil {
 		return models.Theatre{}, err
 	}
-	_, err = stmt.Exec(theatre.Name, id)
-	if err != nil {
+	theatre.ID = id
+	if err := fromRepository(s.theatres.Update(ctx, theatre), &ErrTheatreNotFound{}); err != nil {
 		return models.Theatre{}, err
 	}
 
-	theatre.ID = id
-	return theatre, nil
+	return s.GetTheatre(ctx, id)
 }
 
-func (s *TheatreServiceImpl) DeleteTheatre(id string) error {
-	// Check if theatre exists
-	_, err := s.GetTheatre(id)
-	if err != nil {
-		return fmt.Errorf("theatre not found: %w", err)
+// DeleteTheatre deletes a theatre with its halls and everything scheduled in them in one transaction.
+func (s *TheatreServiceImpl) DeleteTheatre(ctx context.Context, id string, opts DeleteOptions) (models.DeletionSummary, error) {
+	if opts.DryRun {
+		summary, err := s.theatres.CountDeletion(ctx, id)
+		return summary, fromRepository(err, &ErrTheatreNotFound{})
 	}
 
-	// Get all halls for this theatre
-	hallRows, err := database.DB.Query("SELECT id FROM halls WHERE theatre_id = ?", id)
+	summary, err := s.theatres.Delete(ctx, id, deletionGuard(opts))
 	if err != nil {
-		return fmt.Errorf("error getting halls for theatre: %w", err)
+		return summary, fromRepository(err, &ErrTheatreNotFound{})
 	}
-	defer hallRows.Close()
-
-	var hallIDs []string
-	for hallRows.Next() {
-		var hallID string
-		if err := hallRows.Scan(&hallID); err != nil {
-			continue
-		}
-		hallIDs = append(hallIDs, hallID)
-	}
-
-	// Get all shows for halls in this theatre
-	var showIDs []string
-	if len(hallIDs) > 0 {
-		// Build the IN clause for hall IDs
-		placeholders := make([]string, len(hallIDs))
-		args := make([]interface{}, len(hallIDs))
-		for i, hallID := range hallIDs {
-			placeholders[i] = "?"
-			args[i] = hallID
-		}
 
-		placeholdersStr := ""
-		for i, placeholder := range placeholders {
-			if i > 0 {
-				placeholdersStr += ","
-			}
-			placeholdersStr += placeholder
-		}
-
-		showRows, err := database.DB.Query(fmt.Sprintf("SELECT id FROM shows WHERE hall_id IN (%s)", placeholdersStr), args...)
-		if err != nil {
-			log.Printf("Warning: error getting shows for theatre %s: %v", id, err)
-		} else {
-			defer showRows.Close()
-			for showRows.Next() {
-				var showID string
-				if err := showRows.Scan(&showID); err != nil {
-					continue
-				}
-				showIDs = append(showIDs, showID)
-			}
-		}
-	}
-
-	// Delete all bookings for shows in this theatre
-	if len(showIDs) > 0 {
-		placeholders := make([]string, len(showIDs))
-		args := make([]interface{}, len(showIDs))
-		for i, showID := range showIDs {
-			placeholders[i] = "?"
-			args[i] = showID
-		}
-
-		placeholdersStr := ""
-		for i, placeholder := range placeholders {
-			if i > 0 {
-				placeholdersStr += ","
-			}
-			placeholdersStr += placeholder
-		}
-
-		_, err = database.DB.Exec(fmt.Sprintf("DELETE FROM bookings WHERE show_id IN (%s)", placeholdersStr), args...)
-		if err != nil {
-			log.Printf("Warning: error deleting bookings for theatre %s: %v", id, err)
-		}
-	}
-
-	// Delete all shows for halls in this theatre
-	if len(hallIDs) > 0 {
-		placeholders := make([]string, len(hallIDs))
-		args := make([]interface{}, len(hallIDs))
-		for i, hallID := range hallIDs {
-			placeholders[i] = "?"
-			args[i] = hallID
-		}
-
-		placeholdersStr := ""
-		for i, placeholder := range placeholders {
-			if i > 0 {
-				placeholdersStr += ","
-			}
-			placeholdersStr += placeholder
-		}
-
-		_, err = database.DB.Exec(fmt.Sprintf("DELETE FROM shows WHERE hall_id IN (%s)", placeholdersStr), args...)
-		if err != nil {
-			log.Printf("Warning: error deleting shows for theatre %s: %v", id, err)
-		}
-	}
-
-	// Delete all seats for halls in this theatre
-	if len(hallIDs) > 0 {
-		placeholders := make([]string, len(hallIDs))
-		args := make([]interface{}, len(hallIDs))
-		for i, hallID := range hallIDs {
-			placeholders[i] = "?"
-			args[i] = hallID
-		}
-
-		placeholdersStr := ""
-		for i, placeholder := range placeholders {
-			if i > 0 {
-				placeholdersStr += ","
-			}
-			placeholdersStr += placeholder
-		}
-
-		_, err = database.DB.Exec(fmt.Sprintf("DELETE FROM seats WHERE hall_id IN (%s)", placeholdersStr), args...)
-		if err != nil {
-			log.Printf("Warning: error deleting seats for theatre %s: %v", id, err)
-		}
-	}
+	log.Printf("Theatre %s and its %d halls, %d shows and %d bookings deleted successfully", id, summary.Halls, summary.Shows, summary.Bookings)
+	return summary, nil
+}
 
-	// Delete all halls for this theatre
-	_, err = database.DB.Exec("DELETE FROM halls WHERE theatre_id = ?", id)
-	if err != nil {
-		return fmt.Errorf("error deleting halls for theatre: %w", err)
-	}
+// ArchiveTheatre hides a theatre with its halls and shows from public listings, keeping their bookings.
+func (s *TheatreServiceImpl) ArchiveTheatre(ctx context.Context, id string) error {
+	return fromRepository(s.theatres.Archive(ctx, id, archiveTime(), refuseActiveBookings), &ErrTheatreNotFound{})
+}
 
-	// Delete the theatre
-	_, err = database.DB.Exec("DELETE FROM theatres WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("error deleting theatre: %w", err)
+// RestoreTheatre restores an archived theatre and the halls and shows archived with it.
+func (s *TheatreServiceImpl) RestoreTheatre(ctx context.Context, id string) (models.Theatre, error) {
+	if err := fromRepository(s.theatres.Restore(ctx, id), &ErrTheatreNotFound{}); err != nil {
+		return models.Theatre{}, err
 	}
-
-	log.Printf("Theatre %s and its %d halls, %d shows deleted successfully", id, len(hallIDs), len(showIDs))
-	return nil
+	return s.GetTheatre(ctx, id)
 }
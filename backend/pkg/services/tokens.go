@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// getAccessTokenTTL returns how long an access token is valid, from the ACCESS_TOKEN_TTL environment variable
+func getAccessTokenTTL() time.Duration {
+	return getDurationEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+}
+
+// getRefreshTokenTTL returns how long a refresh token is valid, from the REFRESH_TOKEN_TTL environment variable
+func getRefreshTokenTTL() time.Duration {
+	return getDurationEnv("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+}
+
+// Claims defines the custom claims for the JWT. The registered ID claim (jti) identifies the
+// token on the revocation list, and TokenVersion must match the user's current token version.
+// Role, Permissions and Theatres are those of the user when the token was issued; changing the
+// role or theatres bumps the token version, so they cannot go stale. PasswordChangeRequired
+// limits the token to changing the password.
+type Claims struct {
+	Username               string       `json:"username"`
+	Role                   string       `json:"role"`
+	Permissions            []Permission `json:"permissions"`
+	Theatres               []string     `json:"theatres,omitempty"`
+	PasswordChangeRequired bool         `json:"pwd_change,omitempty"`
+	TokenVersion           int          `json:"ver"`
+	jwt.RegisteredClaims
+}
+
+// ErrInvalidToken is returned when an access token is malformed, expired or revoked.
+type ErrInvalidToken struct {
+	Reason string
+}
+
+func (e *ErrInvalidToken) Error() string {
+	return "invalid token: " + e.Reason
+}
+
+func (e *ErrInvalidToken) Kind() ErrorKind { return KindUnauthorized }
+func (e *ErrInvalidToken) Code() string    { return "invalid_token" }
+
+// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired, already used or revoked.
+type ErrInvalidRefreshToken struct{}
+
+func (e *ErrInvalidRefreshToken) Error() string {
+	return "invalid refresh token; log in again"
+}
+
+func (e *ErrInvalidRefreshToken) Kind() ErrorKind { return KindUnauthorized }
+func (e *ErrInvalidRefreshToken) Code() string    { return "invalid_refresh_token" }
+
+// parseAccessToken verifies an access token's signature, with the key its kid header names,
+// and its expiry, and returns its claims.
+func parseAccessToken(keys *SigningKeys, accessToken string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(accessToken, claims, keys.verifyKey, jwt.WithValidMethods(keys.methods()))
+
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+		return nil, &ErrInvalidToken{Reason: "token has expired"}
+	}
+	if err != nil || !token.Valid {
+		return nil, &ErrInvalidToken{Reason: "token is malformed or its signature is invalid"}
+	}
+	// Tokens issued before revocation existed have no ID and cannot be revoked
+	if claims.ID == "" {
+		return nil, &ErrInvalidToken{Reason: "token has no ID; log in again"}
+	}
+	return claims, nil
+}
+
+// newSecretToken returns a random refresh or password reset token and the hash it is stored under.
+func newSecretToken() (token string, hash string, err error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(value)
+	return token, hashSecretToken(token), nil
+}
+
+// hashSecretToken returns the hash a refresh or password reset token is stored under, so a
+// leaked database does not leak usable tokens.
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
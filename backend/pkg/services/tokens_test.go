@@ -0,0 +1,235 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// rotations refreshes the login's tokens that many times before one is presented
+		rotations int
+		// reuse presents the login's first refresh token instead of its latest
+		reuse bool
+		// bumpVersion changes the user's role, and so their token version, before presenting
+		bumpVersion bool
+		unknown     bool
+		wantErr     error
+		// wantFamilyRevoked means the latest refresh token of the login stops working too
+		wantFamilyRevoked bool
+	}{
+		{
+			name: "unused token",
+		},
+		{
+			name:      "latest of rotated tokens",
+			rotations: 2,
+		},
+		{
+			name:              "rotated token reused",
+			rotations:         2,
+			reuse:             true,
+			wantErr:           &ErrInvalidRefreshToken{},
+			wantFamilyRevoked: true,
+		},
+		{
+			name:              "issued before a token version bump",
+			rotations:         1,
+			bumpVersion:       true,
+			wantErr:           &ErrInvalidRefreshToken{},
+			wantFamilyRevoked: true,
+		},
+		{
+			name:    "unknown token",
+			unknown: true,
+			wantErr: &ErrInvalidRefreshToken{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			user := f.register(t, "alice", "password1")
+			first := f.login(t, "alice", "password1").RefreshToken
+			latest := first
+			for i := 0; i < tt.rotations; i++ {
+				tokens, err := f.users.Refresh(f.ctx, latest)
+				if err != nil {
+					t.Fatalf("rotation %d: Refresh() error = %v", i+1, err)
+				}
+				latest = tokens.RefreshToken
+			}
+			if tt.bumpVersion {
+				if _, err := f.users.UpdateUserRole(f.ctx, user.ID, models.RoleBoxOffice); err != nil {
+					t.Fatalf("UpdateUserRole() error = %v", err)
+				}
+			}
+
+			presented := latest
+			switch {
+			case tt.reuse:
+				presented = first
+			case tt.unknown:
+				presented = "no-such-token"
+			}
+			tokens, err := f.users.Refresh(f.ctx, presented)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				f.authenticate(t, tokens.AccessToken)
+				if _, err := f.users.Refresh(f.ctx, presented); !sameError(err, &ErrInvalidRefreshToken{}) {
+					t.Errorf("Refresh() with the same token again error = %v, want invalid_refresh_token", err)
+				}
+			}
+			if tt.wantFamilyRevoked {
+				if _, err := f.users.Refresh(f.ctx, latest); !sameError(err, &ErrInvalidRefreshToken{}) {
+					t.Errorf("Refresh() with the latest token error = %v, want invalid_refresh_token", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name string
+		// change acts on the user and their access token, returning the token to present
+		change  func(t *testing.T, f *userFixture, userID string, accessToken string) string
+		wantErr error
+	}{
+		{
+			name: "fresh token",
+			change: func(t *testing.T, f *userFixture, userID string, accessToken string) string {
+				return accessToken
+			},
+		},
+		{
+			name: "issued before a role change",
+			change: func(t *testing.T, f *userFixture, userID string, accessToken string) string {
+				if _, err := f.users.UpdateUserRole(f.ctx, userID, models.RoleBoxOffice); err != nil {
+					t.Fatalf("UpdateUserRole() error = %v", err)
+				}
+				return accessToken
+			},
+			wantErr: &ErrInvalidToken{},
+		},
+		{
+			name: "issued before a password change",
+			change: func(t *testing.T, f *userFixture, userID string, accessToken string) string {
+				if _, err := f.users.ChangePassword(f.ctx, userID, "password1", "password2"); err != nil {
+					t.Fatalf("ChangePassword() error = %v", err)
+				}
+				return accessToken
+			},
+			wantErr: &ErrInvalidToken{},
+		},
+		{
+			name: "logged out",
+			change: func(t *testing.T, f *userFixture, userID string, accessToken string) string {
+				claims := f.authenticate(t, accessToken)
+				if err := f.users.Logout(f.ctx, userID, claims.ID, claims.ExpiresAt.Time, ""); err != nil {
+					t.Fatalf("Logout() error = %v", err)
+				}
+				return accessToken
+			},
+			wantErr: &ErrInvalidToken{},
+		},
+		{
+			name: "expired",
+			change: func(t *testing.T, f *userFixture, userID string, accessToken string) string {
+				claims := f.authenticate(t, accessToken)
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				expired, err := f.users.keys.sign(claims)
+				if err != nil {
+					t.Fatalf("signing token: %v", err)
+				}
+				return expired
+			},
+			wantErr: &ErrInvalidToken{},
+		},
+		{
+			name: "tampered signature",
+			change: func(t *testing.T, f *userFixture, userID string, accessToken string) string {
+				return accessToken[:len(accessToken)-2] + "xx"
+			},
+			wantErr: &ErrInvalidToken{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			user := f.register(t, "alice", "password1")
+			accessToken := tt.change(t, f, user.ID, f.login(t, "alice", "password1").AccessToken)
+
+			claims, err := f.users.Authenticate(f.ctx, accessToken)
+			if !sameError(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %T", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && claims.Subject != user.ID {
+				t.Errorf("Authenticate() subject = %s, want %s", claims.Subject, user.ID)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	tests := []struct {
+		name string
+		// refreshToken picks the refresh token presented on logout from alice's and bob's
+		refreshToken func(alice string, bob string) string
+		// wantRefreshErr is the error refreshing alice's login afterwards fails with
+		wantRefreshErr error
+	}{
+		{
+			name:           "with own refresh token",
+			refreshToken:   func(alice string, bob string) string { return alice },
+			wantRefreshErr: &ErrInvalidRefreshToken{},
+		},
+		{
+			name:         "without refresh token",
+			refreshToken: func(alice string, bob string) string { return "" },
+		},
+		{
+			name:         "with someone else's refresh token",
+			refreshToken: func(alice string, bob string) string { return bob },
+		},
+		{
+			name:         "with unknown refresh token",
+			refreshToken: func(alice string, bob string) string { return "no-such-token" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			alice := f.register(t, "alice", "password1")
+			f.register(t, "bob", "password1")
+			aliceTokens := f.login(t, "alice", "password1")
+			bobTokens := f.login(t, "bob", "password1")
+			claims := f.authenticate(t, aliceTokens.AccessToken)
+
+			refreshToken := tt.refreshToken(aliceTokens.RefreshToken, bobTokens.RefreshToken)
+			if err := f.users.Logout(f.ctx, alice.ID, claims.ID, claims.ExpiresAt.Time, refreshToken); err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+
+			if _, err := f.users.Authenticate(f.ctx, aliceTokens.AccessToken); !sameError(err, &ErrInvalidToken{}) {
+				t.Errorf("Authenticate() after logout error = %v, want invalid_token", err)
+			}
+			if _, err := f.users.Refresh(f.ctx, aliceTokens.RefreshToken); !sameError(err, tt.wantRefreshErr) {
+				t.Errorf("Refresh() of alice's token error = %v, want %T", err, tt.wantRefreshErr)
+			}
+			// bob's login is never affected by alice logging out
+			f.authenticate(t, bobTokens.AccessToken)
+			if _, err := f.users.Refresh(f.ctx, bobTokens.RefreshToken); err != nil {
+				t.Errorf("Refresh() of bob's token error = %v", err)
+			}
+		})
+	}
+}
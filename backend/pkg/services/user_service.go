@@ -1,6 +1,10 @@
 package services
 
-import "algoBharat/backend/pkg/models"
+import (
+	"algoBharat/backend/pkg/models"
+	"context"
+	"time"
+)
 
 // Credentials represents the user's login credentials.
 type Credentials struct {
@@ -10,8 +14,33 @@ type Credentials struct {
 
 // UserService defines the interface for user-related business logic.
 type UserService interface {
-	Register(credentials Credentials) (models.User, error)
-	Login(credentials Credentials) (string, error) // Returns a JWT token string
-	GetUsers() ([]models.User, error)
-	UpdateUserRole(userID string, newRole string) (models.User, error)
+	Register(ctx context.Context, credentials Credentials) (models.User, error)
+	// Login verifies credentials and returns an access token and a refresh token. Repeated
+	// failures for a username or from clientIP are throttled and then locked out.
+	Login(ctx context.Context, credentials Credentials, clientIP string) (models.TokenPair, error)
+	// Refresh exchanges a refresh token for new tokens; each refresh token works once.
+	Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error)
+	// Logout revokes an access token and, when refreshToken is set, the refresh tokens of the same login.
+	Logout(ctx context.Context, userID string, tokenID string, expiresAt time.Time, refreshToken string) error
+	// Authenticate verifies an access token, rejecting revoked and outdated ones, and returns its claims.
+	Authenticate(ctx context.Context, accessToken string) (*Claims, error)
+	GetUsers(ctx context.Context) ([]models.User, error)
+	UpdateUserRole(ctx context.Context, userID string, newRole string) (models.User, error)
+	// SetUserTheatres replaces the theatres a user is a member of.
+	SetUserTheatres(ctx context.Context, userID string, theatreIDs []string) (models.User, error)
+	// GetPasswordPolicy returns the strength rules new passwords must meet.
+	GetPasswordPolicy() PasswordPolicy
+	// ChangePassword replaces a user's password after checking their current one, and returns
+	// new tokens because the old ones stop working.
+	ChangePassword(ctx context.Context, userID string, currentPassword string, newPassword string) (models.TokenPair, error)
+	// IssuePasswordReset returns a one-time token an admin hands to a user to set a new password.
+	IssuePasswordReset(ctx context.Context, userID string) (models.PasswordReset, error)
+	// ResetPassword sets a new password with a reset token, which then stops working.
+	ResetPassword(ctx context.Context, resetToken string, newPassword string) error
+	// GetLoginLockouts returns the usernames and client IPs locked out after failed logins.
+	GetLoginLockouts(ctx context.Context) ([]models.LoginAttempts, error)
+	// ClearLoginLockout lifts the lockout of a username or client IP.
+	ClearLoginLockout(ctx context.Context, kind string, value string) error
+	// GetJWKS returns the public keys access tokens can be verified with.
+	GetJWKS() models.JSONWebKeySet
 }
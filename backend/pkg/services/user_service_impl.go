@@ -1,144 +1,481 @@
 package services
 
 import (
-	"algoBharat/backend/pkg/database"
 	"algoBharat/backend/pkg/models"
-	"database/sql"
+	"algoBharat/backend/pkg/repository"
+	"context"
 	"fmt"
-	"math/rand"
-	"os"
-	"strconv"
+	"log"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// getJWTKey returns the JWT secret key from environment variables
-func getJWTKey() []byte {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "my_secret_key" // Default fallback
-	}
-	return []byte(secret)
+// ErrUsernameTaken is returned when registering a username that already exists.
+type ErrUsernameTaken struct {
+	Username string
+}
+
+func (e *ErrUsernameTaken) Error() string {
+	return fmt.Sprintf("username %q is already taken", e.Username)
 }
 
-// Claims defines the custom claims for the JWT.
-type Claims struct {
-	Username string `json:"username"`
-	jwt.RegisteredClaims
+func (e *ErrUsernameTaken) Kind() ErrorKind { return KindConflict }
+func (e *ErrUsernameTaken) Code() string    { return "username_taken" }
+
+// ErrInvalidCredentials is returned when a login does not match a user and password.
+type ErrInvalidCredentials struct{}
+
+func (e *ErrInvalidCredentials) Error() string {
+	return "invalid username or password"
+}
+
+func (e *ErrInvalidCredentials) Kind() ErrorKind { return KindUnauthorized }
+func (e *ErrInvalidCredentials) Code() string    { return "invalid_credentials" }
+
+// ErrUserNotFound is returned when a user does not exist.
+type ErrUserNotFound struct{}
+
+func (e *ErrUserNotFound) Error() string {
+	return "user not found"
 }
 
-type UserServiceImpl struct{}
+func (e *ErrUserNotFound) Kind() ErrorKind { return KindNotFound }
+func (e *ErrUserNotFound) Code() string    { return "user_not_found" }
+
+type UserServiceImpl struct {
+	users          repository.UserRepository
+	tokens         repository.TokenRepository
+	logins         *loginLimiter
+	keys           *SigningKeys
+	theatreService TheatreService
+}
+
+// NewUserService creates a UserServiceImpl storing users in users and their refresh tokens and
+// revoked access tokens in tokens. Failed logins are counted in loginAttempts, access tokens
+// are signed and verified with keys, and theatreService checks the theatres users are made
+// members of.
+func NewUserService(users repository.UserRepository, tokens repository.TokenRepository, loginAttempts repository.LoginAttemptRepository, keys *SigningKeys, theatreService TheatreService) *UserServiceImpl {
+	return &UserServiceImpl{users: users, tokens: tokens, logins: &loginLimiter{attempts: loginAttempts}, keys: keys, theatreService: theatreService}
+}
 
 // Register handles the creation of a new user.
-func (s *UserServiceImpl) Register(credentials Credentials) (models.User, error) {
+func (s *UserServiceImpl) Register(ctx context.Context, credentials Credentials) (models.User, error) {
+	if err := validateRegistration(credentials, getPasswordPolicy()); err != nil {
+		return models.User{}, err
+	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(credentials.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return models.User{}, err
 	}
 
 	newUser := models.User{
-		ID:           strconv.Itoa(rand.Intn(1000000)),
 		Username:     credentials.Username,
 		PasswordHash: string(hashedPassword),
-		Role:         "user", // Default role
+		Role:         models.RoleCustomer, // Default role
 	}
 
-	stmt, err := database.DB.Prepare("INSERT INTO users(id, username, password_hash, role) VALUES(?, ?, ?, ?)")
-	if err != nil {
-		return models.User{}, err
+	_, err = insertWithNewID(func(id string) error {
+		newUser.ID = id
+		return s.users.Create(ctx, newUser)
+	})
+	if err == repository.ErrDuplicate {
+		return models.User{}, &ErrUsernameTaken{Username: newUser.Username}
 	}
-	_, err = stmt.Exec(newUser.ID, newUser.Username, newUser.PasswordHash, newUser.Role)
 	if err != nil {
-		// This could be a unique constraint violation if the username is taken
 		return models.User{}, fmt.Errorf("could not create user: %w", err)
 	}
 
 	return newUser, nil
 }
 
-// Login verifies a user's credentials and returns a JWT token if they are valid.
-func (s *UserServiceImpl) Login(credentials Credentials) (string, error) {
-	var user models.User
-	row := database.DB.QueryRow("SELECT id, username, password_hash, role FROM users WHERE username = ?", credentials.Username)
-	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role); err != nil {
-		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("invalid username or password")
+// Login verifies a user's credentials and issues a short-lived access token and a refresh
+// token that starts a new token family. Failed logins are counted per username and per
+// clientIP, which may be empty if unknown; too many are throttled and then locked out.
+// Unknown usernames are treated exactly like wrong passwords, so responses do not reveal
+// which usernames exist.
+func (s *UserServiceImpl) Login(ctx context.Context, credentials Credentials, clientIP string) (models.TokenPair, error) {
+	if err := validateCredentials(credentials); err != nil {
+		return models.TokenPair{}, err
+	}
+	now := time.Now().UTC()
+	if err := s.logins.check(ctx, credentials.Username, clientIP, now); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	user, err := s.users.GetByUsername(ctx, credentials.Username)
+	if err != nil && err != repository.ErrNotFound {
+		return models.TokenPair{}, err
+	}
+	if err == repository.ErrNotFound {
+		compareDummyPassword(credentials.Password)
+	} else {
+		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password))
+	}
+	if err != nil {
+		if lockErr := s.logins.recordFailure(ctx, credentials.Username, clientIP, now); lockErr != nil {
+			return models.TokenPair{}, lockErr
 		}
-		return "", err
+		return models.TokenPair{}, &ErrInvalidCredentials{}
+	}
+	if err := s.logins.recordSuccess(ctx, credentials.Username); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	// Expired refresh tokens, revocation entries and failed logins are useless, so prune them
+	// as users log in
+	if _, err := s.tokens.DeleteExpired(ctx, now); err != nil {
+		log.Printf("Error deleting expired tokens: %v", err)
+	}
+	if _, err := s.logins.deleteStale(ctx, now); err != nil {
+		log.Printf("Error deleting stale login attempts: %v", err)
+	}
+
+	return s.issueTokens(ctx, user, newID(), "")
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token. Each refresh
+// token can be used once: presenting one that was already used means it was stolen or
+// replayed, so every token of its family is revoked and the user has to log in again.
+func (s *UserServiceImpl) Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	if refreshToken == "" {
+		return models.TokenPair{}, NewValidationError("refresh_token", "is required")
+	}
+	stored, err := s.tokens.GetRefreshToken(ctx, hashSecretToken(refreshToken))
+	if err == repository.ErrNotFound {
+		return models.TokenPair{}, &ErrInvalidRefreshToken{}
+	}
+	if err != nil {
+		return models.TokenPair{}, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
-		return "", fmt.Errorf("invalid username or password")
+	now := time.Now().UTC()
+	if stored.RevokedAt != nil {
+		log.Printf("Refresh token reused for user %s; revoking its token family", stored.UserID)
+		return models.TokenPair{}, s.revokeFamily(ctx, stored.FamilyID, now)
 	}
+	if !stored.ExpiresAt.After(now) {
+		return models.TokenPair{}, &ErrInvalidRefreshToken{}
+	}
+
+	user, err := s.users.Get(ctx, stored.UserID)
+	if err == repository.ErrNotFound {
+		return models.TokenPair{}, &ErrInvalidRefreshToken{}
+	}
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	// The user's role or password changed since the token was issued
+	if user.TokenVersion != stored.TokenVersion {
+		return models.TokenPair{}, s.revokeFamily(ctx, stored.FamilyID, now)
+	}
+
+	tokens, err := s.issueTokens(ctx, user, stored.FamilyID, stored.ID)
+	if err == repository.ErrNotFound {
+		// A concurrent request used the same refresh token first
+		return models.TokenPair{}, s.revokeFamily(ctx, stored.FamilyID, now)
+	}
+	return tokens, err
+}
 
-	// Create the JWT claims, which includes the username and expiry time
-	expirationTime := time.Now().Add(24 * time.Hour)
+// revokeFamily revokes a refresh token family and returns ErrInvalidRefreshToken, or the
+// error that stopped the revocation.
+func (s *UserServiceImpl) revokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	if err := s.tokens.RevokeRefreshFamily(ctx, familyID, now); err != nil {
+		return err
+	}
+	return &ErrInvalidRefreshToken{}
+}
+
+// issueTokens signs an access token for user and stores a new refresh token in familyID. When
+// previousID is set, the refresh token with that hash is revoked in the same transaction.
+func (s *UserServiceImpl) issueTokens(ctx context.Context, user models.User, familyID string, previousID string) (models.TokenPair, error) {
+	theatreIDs, err := s.users.TheatreIDs(ctx, user.ID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	now := time.Now().UTC()
+	accessTTL := getAccessTokenTTL()
 	claims := &Claims{
-		Username: user.Username,
+		Username:               user.Username,
+		Role:                   user.Role,
+		Permissions:            RolePermissions(user.Role),
+		Theatres:               theatreIDs,
+		PasswordChangeRequired: user.MustChangePassword,
+		TokenVersion:           user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newID(),
 			Subject:   user.ID,
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			Issuer:    user.Role, // Using issuer to store the role
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTTL)),
 		},
 	}
+	accessToken, err := s.keys.sign(claims)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
 
-	// Declare the token with the algorithm used for signing, and the claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// Create the JWT string
-	tokenString, err := token.SignedString(getJWTKey())
+	refreshToken, refreshHash, err := newSecretToken()
 	if err != nil {
-		return "", err
+		return models.TokenPair{}, err
+	}
+	stored := models.RefreshToken{
+		ID:           refreshHash,
+		UserID:       user.ID,
+		FamilyID:     familyID,
+		TokenVersion: user.TokenVersion,
+		ExpiresAt:    now.Add(getRefreshTokenTTL()),
+	}
+	if previousID == "" {
+		err = s.tokens.CreateRefreshToken(ctx, stored)
+	} else {
+		err = s.tokens.RotateRefreshToken(ctx, previousID, stored, now)
+	}
+	if err != nil {
+		return models.TokenPair{}, err
 	}
 
-	return tokenString, nil
+	return models.TokenPair{
+		AccessToken:            accessToken,
+		TokenType:              "Bearer",
+		ExpiresIn:              int(accessTTL.Seconds()),
+		RefreshToken:           refreshToken,
+		PasswordChangeRequired: user.MustChangePassword,
+	}, nil
 }
 
-// GetUsers retrieves all users from the database.
-func (s *UserServiceImpl) GetUsers() ([]models.User, error) {
-	rows, err := database.DB.Query("SELECT id, username, role FROM users")
+// Logout revokes the access token tokenID of userID until it expires at expiresAt. When
+// refreshToken is set and belongs to the same user, its whole token family is revoked too.
+func (s *UserServiceImpl) Logout(ctx context.Context, userID string, tokenID string, expiresAt time.Time, refreshToken string) error {
+	if err := s.tokens.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.tokens.GetRefreshToken(ctx, hashSecretToken(refreshToken))
+	if err == repository.ErrNotFound || (err == nil && stored.UserID != userID) {
+		// Nothing of this user's to revoke
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.tokens.RevokeRefreshFamily(ctx, stored.FamilyID, time.Now().UTC())
+}
+
+// Authenticate verifies an access token and returns its claims. Besides a valid signature and
+// expiry, the token must not be on the revocation list and must have been issued since the
+// user's role, theatres or password last changed.
+func (s *UserServiceImpl) Authenticate(ctx context.Context, accessToken string) (*Claims, error) {
+	claims, err := parseAccessToken(s.keys, accessToken)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var users []models.User
-	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Role); err != nil {
-			return nil, err
-		}
-		users = append(users, user)
+	revoked, err := s.tokens.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, &ErrInvalidToken{Reason: "token has been revoked"}
+	}
+
+	user, err := s.users.Get(ctx, claims.Subject)
+	if err == repository.ErrNotFound {
+		return nil, &ErrInvalidToken{Reason: "user no longer exists"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, &ErrInvalidToken{Reason: "user's role, theatres or password have changed; log in again"}
 	}
 
-	return users, nil
+	return claims, nil
 }
 
-// UpdateUserRole updates the role of a specific user.
-func (s *UserServiceImpl) UpdateUserRole(userID string, newRole string) (models.User, error) {
-	stmt, err := database.DB.Prepare("UPDATE users SET role = ? WHERE id = ?")
-	if err != nil {
+// GetUsers retrieves all users.
+func (s *UserServiceImpl) GetUsers(ctx context.Context) ([]models.User, error) {
+	return s.users.List(ctx)
+}
+
+// UpdateUserRole updates the role of a specific user. Their outstanding tokens stop working,
+// so they must log in again to act with the new role.
+func (s *UserServiceImpl) UpdateUserRole(ctx context.Context, userID string, newRole string) (models.User, error) {
+	if err := validateRole(newRole); err != nil {
 		return models.User{}, err
 	}
-	defer stmt.Close()
+	if err := s.users.UpdateRole(ctx, userID, newRole); err != nil {
+		return models.User{}, fromRepository(err, &ErrUserNotFound{})
+	}
 
-	res, err := stmt.Exec(newRole, userID)
+	// Fetch the updated user to return
+	updatedUser, err := s.users.Get(ctx, userID)
 	if err != nil {
+		return models.User{}, fmt.Errorf("failed to retrieve updated user: %w", err)
+	}
+
+	return updatedUser, nil
+}
+
+// SetUserTheatres replaces the theatres a user is a member of. Like a role change, it stops
+// their outstanding tokens from working, so their next token carries the new theatres.
+func (s *UserServiceImpl) SetUserTheatres(ctx context.Context, userID string, theatreIDs []string) (models.User, error) {
+	v := &validator{}
+	var unique []string
+	seen := make(map[string]bool)
+	for i, theatreID := range theatreIDs {
+		if seen[theatreID] {
+			continue
+		}
+		seen[theatreID] = true
+		unique = append(unique, theatreID)
+		err := v.checkReference(theatreID, fmt.Sprintf("theatre_ids[%d]", i), "theatre", func() error {
+			_, err := s.theatreService.GetTheatre(ctx, theatreID)
+			return err
+		})
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+	if err := v.err(); err != nil {
 		return models.User{}, err
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil || rowsAffected == 0 {
-		return models.User{}, fmt.Errorf("user with ID %s not found or role not changed", userID)
+	if err := s.users.SetTheatres(ctx, userID, unique); err != nil {
+		return models.User{}, fromRepository(err, &ErrUserNotFound{})
 	}
 
-	// Fetch the updated user to return
-	var updatedUser models.User
-	row := database.DB.QueryRow("SELECT id, username, role FROM users WHERE id = ?", userID)
-	if err := row.Scan(&updatedUser.ID, &updatedUser.Username, &updatedUser.Role); err != nil {
+	user, err := s.users.Get(ctx, userID)
+	if err != nil {
 		return models.User{}, fmt.Errorf("failed to retrieve updated user: %w", err)
 	}
+	user.TheatreIDs, err = s.users.TheatreIDs(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
 
-	return updatedUser, nil
+// GetPasswordPolicy returns the strength rules new passwords must meet.
+func (s *UserServiceImpl) GetPasswordPolicy() PasswordPolicy {
+	return getPasswordPolicy()
+}
+
+// ChangePassword replaces the password of userID after checking their current one. Every
+// token issued before stops working, so it returns new tokens for the caller to continue with.
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, userID string, currentPassword string, newPassword string) (models.TokenPair, error) {
+	user, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return models.TokenPair{}, fromRepository(err, &ErrUserNotFound{})
+	}
+
+	v := &validator{}
+	v.check(currentPassword != "", "current_password", "is required")
+	v.checkPassword(newPassword, "new_password", user.Username, getPasswordPolicy())
+	v.check(newPassword == "" || newPassword != currentPassword, "new_password", "must differ from the current password")
+	if err := v.err(); err != nil {
+		return models.TokenPair{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return models.TokenPair{}, NewValidationError("current_password", "is incorrect")
+	}
+
+	if err := s.setPassword(ctx, userID, newPassword); err != nil {
+		return models.TokenPair{}, err
+	}
+	user, err = s.users.Get(ctx, userID)
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("failed to retrieve updated user: %w", err)
+	}
+	return s.issueTokens(ctx, user, newID(), "")
+}
+
+// IssuePasswordReset returns a one-time token that lets userID choose a new password without
+// knowing their current one. Only the latest token issued for a user works.
+func (s *UserServiceImpl) IssuePasswordReset(ctx context.Context, userID string) (models.PasswordReset, error) {
+	if _, err := s.users.Get(ctx, userID); err != nil {
+		return models.PasswordReset{}, fromRepository(err, &ErrUserNotFound{})
+	}
+
+	reset, stored, err := newPasswordResetToken(userID, time.Now().UTC())
+	if err != nil {
+		return models.PasswordReset{}, err
+	}
+	if err := s.tokens.CreatePasswordReset(ctx, stored); err != nil {
+		return models.PasswordReset{}, err
+	}
+	return reset, nil
+}
+
+// ResetPassword sets a new password with a reset token from IssuePasswordReset and uses the
+// token up. Every token issued to the user before stops working.
+func (s *UserServiceImpl) ResetPassword(ctx context.Context, resetToken string, newPassword string) error {
+	if resetToken == "" {
+		return NewValidationError("reset_token", "is required")
+	}
+	stored, err := s.tokens.GetPasswordReset(ctx, hashSecretToken(resetToken))
+	if err == repository.ErrNotFound || (err == nil && !stored.ExpiresAt.After(time.Now().UTC())) {
+		return &ErrInvalidResetToken{}
+	}
+	if err != nil {
+		return err
+	}
+	user, err := s.users.Get(ctx, stored.UserID)
+	if err == repository.ErrNotFound {
+		return &ErrInvalidResetToken{}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Check the password before using the token up, so a rejected password can be retried
+	v := &validator{}
+	v.checkPassword(newPassword, "new_password", user.Username, getPasswordPolicy())
+	if err := v.err(); err != nil {
+		return err
+	}
+	if err := s.tokens.DeletePasswordReset(ctx, stored.ID); err != nil {
+		// A concurrent request used the same token first
+		return fromRepository(err, &ErrInvalidResetToken{})
+	}
+	return s.setPassword(ctx, user.ID, newPassword)
+}
+
+// setPassword stores the hash of password for userID and lifts any forced password change.
+func (s *UserServiceImpl) setPassword(ctx context.Context, userID string, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, userID, string(hashedPassword), false); err != nil {
+		return fromRepository(err, &ErrUserNotFound{})
+	}
+	return nil
+}
+
+// GetLoginLockouts returns the usernames and client IPs currently locked out of logging in.
+func (s *UserServiceImpl) GetLoginLockouts(ctx context.Context) ([]models.LoginAttempts, error) {
+	return s.logins.attempts.ListLocked(ctx, time.Now().UTC())
+}
+
+// ClearLoginLockout forgets the failed logins of a username or client IP, lifting its lockout.
+func (s *UserServiceImpl) ClearLoginLockout(ctx context.Context, kind string, value string) error {
+	v := &validator{}
+	v.check(kind == models.LoginAttemptUsername || kind == models.LoginAttemptIP, "kind",
+		"must be one of "+models.LoginAttemptUsername+", "+models.LoginAttemptIP)
+	v.check(value != "", "value", "is required")
+	if err := v.err(); err != nil {
+		return err
+	}
+	return fromRepository(s.logins.attempts.Clear(ctx, kind, value), &ErrLockoutNotFound{})
+}
+
+// GetJWKS returns the public keys access tokens can be verified with.
+func (s *UserServiceImpl) GetJWKS() models.JSONWebKeySet {
+	return s.keys.JWKS()
 }
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"algoBharat/backend/pkg/models"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxNameLength matches the width of the name and title columns.
+const maxNameLength = 255
+
+// validator collects the field errors of a request, so that every invalid field is reported
+// at once rather than one per attempt.
+type validator struct {
+	fields []FieldError
+}
+
+// check records message against field unless ok holds.
+func (v *validator) check(ok bool, field string, message string) {
+	if !ok {
+		v.fields = append(v.fields, FieldError{Field: field, Message: message})
+	}
+}
+
+// checkName checks a required, length-limited text field such as a title or name.
+func (v *validator) checkName(value string, field string) {
+	switch {
+	case strings.TrimSpace(value) == "":
+		v.check(false, field, "is required")
+	case utf8.RuneCountInString(value) > maxNameLength:
+		v.check(false, field, "must be at most 255 characters")
+	}
+}
+
+// checkReference checks a required field holding the ID of another record. lookup fetches the
+// record; a record that does not exist is a field error, while other lookup errors, such as a
+// failed query, are returned.
+func (v *validator) checkReference(id string, field string, entity string, lookup func() error) error {
+	if strings.TrimSpace(id) == "" {
+		v.check(false, field, "is required")
+		return nil
+	}
+	err := lookup()
+	var serviceErr Error
+	if errors.As(err, &serviceErr) && serviceErr.Kind() == KindNotFound {
+		v.check(false, field, entity+" does not exist")
+		return nil
+	}
+	return err
+}
+
+// err returns the collected field errors as an ErrValidation, or nil if there are none.
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ErrValidation{Fields: v.fields}
+}
+
+// validateMovie checks the fields of a movie being created or updated.
+func validateMovie(movie models.Movie) error {
+	v := &validator{}
+	v.checkName(movie.Title, "title")
+	v.check(movie.DurationMinutes > 0, "duration_minutes", "must be a positive number of minutes")
+	return v.err()
+}
+
+// validateTheatre checks the fields of a theatre being created or updated.
+func validateTheatre(theatre models.Theatre) error {
+	v := &validator{}
+	v.checkName(theatre.Name, "name")
+	return v.err()
+}
+
+// validateBookingRequest checks that a booking or hold request identifies a show and the
+// seats to reserve.
+func validateBookingRequest(request BookingRequest) error {
+	v := &validator{}
+	if request.ShowID == "" {
+		v.check(request.MovieID != "", "movieId", "is required when showId is not given")
+		v.check(request.HallID != "", "hallId", "is required when showId is not given")
+		if request.Time == "" {
+			v.check(false, "time", "is required when showId is not given")
+		} else if _, err := parseBookingTime(request.Time); err != nil {
+			v.check(false, "time", "must be an RFC3339 time")
+		}
+	}
+	if len(request.SeatIDs) == 0 {
+		v.check(request.NumSeats > 0, "numSeats", "must be positive when seatIds is not given")
+	}
+	return v.err()
+}
+
+// validateCredentials checks that a registration or login names a user and a password.
+func validateCredentials(credentials Credentials) error {
+	v := &validator{}
+	v.checkName(credentials.Username, "username")
+	v.check(credentials.Password != "", "password", "is required")
+	return v.err()
+}
+
+// validateRegistration checks the username of a new user and that their password meets policy.
+func validateRegistration(credentials Credentials, policy PasswordPolicy) error {
+	v := &validator{}
+	v.checkName(credentials.Username, "username")
+	v.checkPassword(credentials.Password, "password", credentials.Username, policy)
+	return v.err()
+}
+
+// validShowTime reports whether value is an RFC3339 time, the format shows are stored in.
+func validShowTime(value string) bool {
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// normalizeShowTime returns a valid show time in UTC, so that stored show times compare as
+// strings in the order of the instants they name.
+func normalizeShowTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// sameShowTime reports whether two show times name the same instant, whatever their offsets.
+func sameShowTime(a, b string) bool {
+	at, errA := time.Parse(time.RFC3339, a)
+	bt, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return at.Equal(bt)
+}
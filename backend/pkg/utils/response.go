@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"algoBharat/backend/pkg/services"
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 // Status represents the status part of the API response.
@@ -11,15 +17,24 @@ type Status struct {
 	Code    int  `json:"code"`
 }
 
-// APIResponse is the standardized JSON response structure.
+// APIError is the machine-readable part of an error response. Code is stable for clients to
+// branch on; Fields lists the invalid request fields of a validation error.
+type APIError struct {
+	Code   string                `json:"code"`
+	Fields []services.FieldError `json:"fields,omitempty"`
+}
+
+// APIResponse is the standardized JSON response structure. Error responses carry a message
+// and an error code, and may carry data that helps the client recover.
 type APIResponse struct {
 	Status  Status      `json:"status"`
 	Data    interface{} `json:"data,omitempty"`
 	Message string      `json:"message,omitempty"`
+	Error   *APIError   `json:"error,omitempty"`
 }
 
 // respond is the base function for sending all JSON responses.
-func respond(w http.ResponseWriter, statusCode int, success bool, data interface{}, message string) {
+func respond(w http.ResponseWriter, statusCode int, success bool, data interface{}, message string, apiError *APIError) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -30,6 +45,7 @@ func respond(w http.ResponseWriter, statusCode int, success bool, data interface
 		},
 		Data:    data,
 		Message: message,
+		Error:   apiError,
 	}
 
 	json.NewEncoder(w).Encode(response)
@@ -37,10 +53,90 @@ func respond(w http.ResponseWriter, statusCode int, success bool, data interface
 
 // RespondJSON sends a standard success response with data.
 func RespondJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	respond(w, statusCode, true, data, "")
+	respond(w, statusCode, true, data, "", nil)
+}
+
+// RespondRawJSON sends data as is, without the standard response envelope, for documents
+// whose format is fixed by a standard, such as a JWKS.
+func RespondRawJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
 }
 
-// RespondError sends a standard error response with a message.
+// RespondError sends a standard error response with a message. The error code is derived
+// from the status code; use RespondServiceError for errors returned by services.
 func RespondError(w http.ResponseWriter, statusCode int, message string) {
-	respond(w, statusCode, false, nil, message)
+	RespondErrorData(w, statusCode, statusErrorCodes[statusCode], message, nil)
+}
+
+// RespondErrorData sends an error response with an explicit error code and data for the client.
+func RespondErrorData(w http.ResponseWriter, statusCode int, code string, message string, data interface{}) {
+	if code == "" {
+		code = "error"
+	}
+	respond(w, statusCode, false, data, message, &APIError{Code: code})
+}
+
+// kindStatuses maps each kind of service error to its HTTP status code.
+var kindStatuses = map[services.ErrorKind]int{
+	services.KindNotFound:     http.StatusNotFound,
+	services.KindConflict:     http.StatusConflict,
+	services.KindValidation:   http.StatusBadRequest,
+	services.KindUnauthorized: http.StatusUnauthorized,
+	services.KindForbidden:    http.StatusForbidden,
+	services.KindGone:         http.StatusGone,
+	services.KindRateLimited:  http.StatusTooManyRequests,
+	services.KindInternal:     http.StatusInternalServerError,
+}
+
+// statusErrorCodes are the error codes of responses that are not built from a service error.
+var statusErrorCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusGone:                "gone",
+	http.StatusTooManyRequests:     "too_many_requests",
+	http.StatusInternalServerError: "internal",
+	http.StatusServiceUnavailable:  "request_cancelled",
+	http.StatusGatewayTimeout:      "timeout",
+}
+
+// RespondServiceError is the one place errors returned by services become HTTP responses.
+// Errors implementing services.Error get the status of their kind, their code, their field
+// errors and their details as data, and a Retry-After header if they are retryable. A request
+// that ran out of time gets 504. Anything else is
+// an unexpected failure: it is logged and reported as a 500 without its message, which may
+// expose internals.
+func RespondServiceError(w http.ResponseWriter, err error) {
+	var serviceErr services.Error
+	switch {
+	case errors.As(err, &serviceErr):
+		status, ok := kindStatuses[serviceErr.Kind()]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		apiError := &APIError{Code: serviceErr.Code()}
+		if fieldErr, ok := serviceErr.(services.FieldErrors); ok {
+			apiError.Fields = fieldErr.FieldErrors()
+		}
+		var data interface{}
+		if detailed, ok := serviceErr.(services.ErrorDetails); ok {
+			data = detailed.Details()
+		}
+		if retryable, ok := serviceErr.(services.Retryable); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryable.RetryAfter().Seconds()))))
+		}
+		respond(w, status, false, data, serviceErr.Error(), apiError)
+	case errors.Is(err, context.DeadlineExceeded):
+		RespondError(w, http.StatusGatewayTimeout, "The request took too long and was cancelled")
+	case errors.Is(err, context.Canceled):
+		// The client has gone away; nobody will read this
+		RespondError(w, http.StatusServiceUnavailable, "The request was cancelled")
+	default:
+		log.Printf("Internal error: %v", err)
+		RespondError(w, http.StatusInternalServerError, "Internal server error")
+	}
 }
@@ -20,64 +20,77 @@ import ShowManagement from './pages/ShowManagement';
 import ShowListing from './pages/ShowListing';
 import AnalyticsDashboard from './pages/AnalyticsDashboard';
 import UserManagement from './pages/UserManagement';
+import ChangePassword from './pages/ChangePassword';
+import ResetPassword from './pages/ResetPassword';
 
 const { Content, Footer } = Layout;
 const { Title } = Typography;
 
-const PrivateRoute = ({ children, adminOnly }) => {
-  const { user, isAdmin } = useAuth();
+// PrivateRoute requires a logged-in user and, when set, a permission granted by their role
+const PrivateRoute = ({ children, adminOnly, permission }) => {
+  const { user, isAdmin, can } = useAuth();
   if (!user) {
     return <Login />;
   }
-  if (adminOnly && !isAdmin) {
-    return <div style={{ textAlign: 'center', padding: '50px', color: '#ff4d4f', fontSize: '18px', fontWeight: 'bold' }}>Access Denied: Admins only</div>;
+  if ((adminOnly && !isAdmin) || (permission && !can(permission))) {
+    return <div style={{ textAlign: 'center', padding: '50px', color: '#ff4d4f', fontSize: '18px', fontWeight: 'bold' }}>Access Denied</div>;
   }
   return children;
 };
 
 function AppContent() {
+  const { user } = useAuth();
   return (
     <Layout style={{ minHeight: '100vh' }}>
       <Navbar />
       <Content style={{ padding: '24px' }}>
-        <Routes>
-          <Route path="/" element={<Home />} />
-          <Route path="/movies" element={<Movies />} />
-          <Route path="/movies/:id" element={<ShowListing />} />
-          <Route path="/theatres" element={<Theatres />} />
-          <Route path="/login" element={<Login />} />
-          <Route path="/register" element={<Register />} />
-          <Route path="/admin/movies" element={
-            <PrivateRoute adminOnly={true}>
-              <MovieManagement />
-            </PrivateRoute>
-          } />
-          <Route path="/admin/theatres" element={
-            <PrivateRoute adminOnly={true}>
-              <TheatreManagement />
-            </PrivateRoute>
-          } />
-          <Route path="/admin/shows" element={
-            <PrivateRoute adminOnly={true}>
-              <ShowManagement />
-            </PrivateRoute>
-          } />
-          <Route path="/admin/analytics" element={
-            <PrivateRoute adminOnly={true}>
-              <AnalyticsDashboard />
-            </PrivateRoute>
-          } />
-          <Route path="/admin/users" element={
-            <PrivateRoute adminOnly={true}>
-              <UserManagement />
-            </PrivateRoute>
-          } />
-          <Route path="/admin-dashboard" element={
-            <PrivateRoute adminOnly={true}>
-              <Title level={2}>Admin Dashboard</Title>
-            </PrivateRoute>
-          } />
-        </Routes>
+        {/* Users who must change their password can do nothing else until they have */}
+        {user?.mustChangePassword ? <ChangePassword /> : (
+          <Routes>
+            <Route path="/" element={<Home />} />
+            <Route path="/movies" element={<Movies />} />
+            <Route path="/movies/:id" element={<ShowListing />} />
+            <Route path="/theatres" element={<Theatres />} />
+            <Route path="/login" element={<Login />} />
+            <Route path="/register" element={<Register />} />
+            <Route path="/reset-password" element={<ResetPassword />} />
+            <Route path="/change-password" element={
+              <PrivateRoute>
+                <ChangePassword />
+              </PrivateRoute>
+            } />
+            <Route path="/admin/movies" element={
+              <PrivateRoute permission="movies:manage">
+                <MovieManagement />
+              </PrivateRoute>
+            } />
+            <Route path="/admin/theatres" element={
+              <PrivateRoute permission="halls:manage">
+                <TheatreManagement />
+              </PrivateRoute>
+            } />
+            <Route path="/admin/shows" element={
+              <PrivateRoute permission="shows:manage">
+                <ShowManagement />
+              </PrivateRoute>
+            } />
+            <Route path="/admin/analytics" element={
+              <PrivateRoute permission="analytics:view">
+                <AnalyticsDashboard />
+              </PrivateRoute>
+            } />
+            <Route path="/admin/users" element={
+              <PrivateRoute permission="users:manage">
+                <UserManagement />
+              </PrivateRoute>
+            } />
+            <Route path="/admin-dashboard" element={
+              <PrivateRoute adminOnly={true}>
+                <Title level={2}>Admin Dashboard</Title>
+              </PrivateRoute>
+            } />
+          </Routes>
+        )}
       </Content>
       <Footer style={{ textAlign: 'center' }}>
         AlgoBharat ©2025 Created by Garv Luthra
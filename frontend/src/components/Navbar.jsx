@@ -7,7 +7,7 @@ import { useAuth } from "../context/AuthContext";
 const { Header } = Layout;
 
 function Navbar() {
-    const { user, logout, isAdmin } = useAuth();
+    const { user, logout, can } = useAuth();
     const location = useLocation();
     const [open, setOpen] = useState(false);
 
@@ -16,17 +16,17 @@ function Navbar() {
         { key: "/", label: <Link to="/">Home</Link> },
         { key: "/movies", label: <Link to="/movies">Movies</Link> },
         { key: "/theatres", label: <Link to="/theatres">Theatres</Link> },
-        ...(isAdmin
+        // Management pages are shown to the roles whose permissions they need
+        ...(can("movies:manage") ? [{ key: "/admin/movies", label: <Link to="/admin/movies">Movie Management</Link> }] : []),
+        ...(can("halls:manage") ? [{ key: "/admin/theatres", label: <Link to="/admin/theatres">Theatre Management</Link> }] : []),
+        ...(can("shows:manage") ? [{ key: "/admin/shows", label: <Link to="/admin/shows">Show Management</Link> }] : []),
+        ...(can("analytics:view") ? [{ key: "/admin/analytics", label: <Link to="/admin/analytics">Analytics Dashboard</Link> }] : []),
+        ...(can("users:manage") ? [{ key: "/admin/users", label: <Link to="/admin/users">User Management</Link> }] : []),
+        ...(user
             ? [
-                { key: "/admin/movies", label: <Link to="/admin/movies">Movie Management</Link> },
-                { key: "/admin/theatres", label: <Link to="/admin/theatres">Theatre Management</Link> },
-                { key: "/admin/shows", label: <Link to="/admin/shows">Show Management</Link> },
-                { key: "/admin/analytics", label: <Link to="/admin/analytics">Analytics Dashboard</Link> },
-                { key: "/admin/users", label: <Link to="/admin/users">User Management</Link> },
+                { key: "/change-password", label: <Link to="/change-password">Change Password</Link> },
+                { key: "logout", label: <span onClick={logout}>Logout ({user.username})</span> },
             ]
-            : []),
-        ...(user
-            ? [{ key: "logout", label: <span onClick={logout}>Logout ({user.username})</span> }]
             : [
                 { key: "/login", label: <Link to="/login">Login</Link> },
                 { key: "/register", label: <Link to="/register">Register</Link> },
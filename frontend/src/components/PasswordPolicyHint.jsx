@@ -0,0 +1,30 @@
+import React, { useEffect, useState } from 'react';
+import axios from 'axios';
+import { Typography } from 'antd';
+import { API_BASE_URL } from '../config/api';
+
+const { Text } = Typography;
+
+// PasswordPolicyHint describes the strength rules the server enforces on new passwords
+function PasswordPolicyHint() {
+  const [policy, setPolicy] = useState(null);
+
+  useEffect(() => {
+    axios.get(`${API_BASE_URL}/password-policy`)
+      .then((response) => setPolicy(response.data.data))
+      .catch((error) => console.error('Failed to fetch password policy:', error));
+  }, []);
+
+  if (!policy) {
+    return null;
+  }
+  const rules = [`at least ${policy.min_length} characters`];
+  if (policy.require_uppercase) rules.push('an uppercase letter');
+  if (policy.require_lowercase) rules.push('a lowercase letter');
+  if (policy.require_digit) rules.push('a digit');
+  if (policy.require_symbol) rules.push('a symbol');
+
+  return <Text type="secondary">Passwords need {rules.join(', ')}.</Text>;
+}
+
+export default PasswordPolicyHint;
@@ -1,19 +1,58 @@
-import React from 'react';
-import { InputNumber, Button, Space, Typography, Row, Col, message, Divider } from 'antd';
+import React, { useEffect, useState } from 'react';
+import { Input, InputNumber, Button, Space, Typography, Row, Col, message, Divider } from 'antd';
 import { MinusCircleOutlined, PlusOutlined } from '@ant-design/icons';
+import axios from 'axios';
+import { API_BASE_URL } from '../config/api';
 
 const { Text } = Typography;
 
-const SeatMapDesigner = ({ value, onChange }) => {
+// Fallback limits, used until the server's layout limits have loaded.
+const DEFAULT_LIMITS = {
+  max_rows: 50,
+  max_blocks_per_row: 10,
+  min_seats_per_block: 1,
+  max_seats_per_block: 50,
+};
+
+const SeatMapDesigner = ({
+  value,
+  onChange,
+  missingSeats = [],
+  onMissingSeatsChange,
+  seatCategories = {},
+  onSeatCategoriesChange,
+}) => {
   const seatMap = value || {};
+  const [limits, setLimits] = useState(DEFAULT_LIMITS);
+
+  useEffect(() => {
+    axios
+      .get(`${API_BASE_URL}/halls/layout-limits`)
+      .then((response) => setLimits(response.data.data || DEFAULT_LIMITS))
+      .catch(() => setLimits(DEFAULT_LIMITS));
+  }, []);
+
+  const updateParent = (newSeatMap, newMissingSeats = missingSeats) => {
+    // Drop missing seats that no longer exist in the layout
+    const prunedMissingSeats = newMissingSeats.filter((seatId) => {
+      const [rowKey, col, num] = seatId.split('-');
+      const blocks = newSeatMap[rowKey];
+      return blocks && blocks[parseInt(col) - 1] >= parseInt(num);
+    });
 
-  const updateParent = (newSeatMap) => {
     if (typeof onChange === 'function') {
       onChange(newSeatMap);
     }
+    if (typeof onMissingSeatsChange === 'function') {
+      onMissingSeatsChange(prunedMissingSeats);
+    }
   };
 
   const handleAddRow = () => {
+    if (Object.keys(seatMap).length >= limits.max_rows) {
+      message.error(`A hall can have at most ${limits.max_rows} rows.`);
+      return;
+    }
     const newRowNum = Object.keys(seatMap).length > 0 ? Math.max(...Object.keys(seatMap).map(k => parseInt(k))) + 1 : 1;
     const newSeatMap = {
       ...seatMap,
@@ -26,18 +65,57 @@ const SeatMapDesigner = ({ value, onChange }) => {
     const newSeatMap = { ...seatMap };
     delete newSeatMap[rowKey];
     updateParent(newSeatMap);
+    handleRowCategoryChange(rowKey, '');
+  };
+
+  const handleRowCategoryChange = (rowKey, category) => {
+    const newSeatCategories = { ...seatCategories };
+    if (category.trim()) {
+      newSeatCategories[rowKey] = category.trim();
+    } else {
+      delete newSeatCategories[rowKey];
+    }
+    if (typeof onSeatCategoriesChange === 'function') {
+      onSeatCategoriesChange(newSeatCategories);
+    }
+  };
+
+  const handleAddColumn = (rowKey) => {
+    if (seatMap[rowKey].length >= limits.max_blocks_per_row) {
+      message.error(`A row can have at most ${limits.max_blocks_per_row} columns.`);
+      return;
+    }
+    const newSeatMap = { ...seatMap, [rowKey]: [...seatMap[rowKey], Math.max(2, limits.min_seats_per_block)] };
+    updateParent(newSeatMap);
+  };
+
+  const handleRemoveColumn = (rowKey, colIndex) => {
+    if (seatMap[rowKey].length <= 1) {
+      message.error('A row needs at least one column.');
+      return;
+    }
+    const newSeatMap = { ...seatMap, [rowKey]: seatMap[rowKey].filter((_, i) => i !== colIndex) };
+    // Seat IDs of later columns shift, so missing seats in this row are reset
+    updateParent(newSeatMap, missingSeats.filter((seatId) => !seatId.startsWith(`${rowKey}-`)));
   };
 
   const handleColumnChange = (rowKey, colIndex, numSeats) => {
-    if (numSeats < 2) {
-      message.error('Minimum 2 seats required per column.');
+    if (numSeats < limits.min_seats_per_block || numSeats > limits.max_seats_per_block) {
+      message.error(`Each column needs between ${limits.min_seats_per_block} and ${limits.max_seats_per_block} seats.`);
       return;
     }
-    const newSeatMap = { ...seatMap };
+    const newSeatMap = { ...seatMap, [rowKey]: [...seatMap[rowKey]] };
     newSeatMap[rowKey][colIndex] = numSeats;
     updateParent(newSeatMap);
   };
 
+  const handleToggleSeat = (seatId) => {
+    const newMissingSeats = missingSeats.includes(seatId)
+      ? missingSeats.filter((id) => id !== seatId)
+      : [...missingSeats, seatId];
+    updateParent(seatMap, newMissingSeats);
+  };
+
   const rows = Object.keys(seatMap).sort((a, b) => parseInt(a) - parseInt(b));
 
   return (
@@ -52,9 +130,19 @@ const SeatMapDesigner = ({ value, onChange }) => {
           {seatMap[rowKey].map((numSeats, colIndex) => (
             <Col key={colIndex} flex="auto">
               <Space direction="vertical" size="small">
-                <Text type="secondary">Column {colIndex + 1}</Text>
+                <Space size="small">
+                  <Text type="secondary">Column {colIndex + 1}</Text>
+                  <Button
+                    type="text"
+                    size="small"
+                    danger
+                    icon={<MinusCircleOutlined />}
+                    onClick={() => handleRemoveColumn(rowKey, colIndex)}
+                  />
+                </Space>
                 <InputNumber
-                  min={2}
+                  min={limits.min_seats_per_block}
+                  max={limits.max_seats_per_block}
                   value={numSeats}
                   onChange={(val) => handleColumnChange(rowKey, colIndex, val)}
                   style={{ width: '100%' }}
@@ -62,6 +150,18 @@ const SeatMapDesigner = ({ value, onChange }) => {
               </Space>
             </Col>
           ))}
+          <Col flex="120px">
+            <Input
+              placeholder="regular"
+              maxLength={50}
+              value={seatCategories[rowKey] || ''}
+              onChange={(e) => handleRowCategoryChange(rowKey, e.target.value)}
+              title="Seat category of this row, e.g. premium or recliner"
+            />
+          </Col>
+          <Col flex="32px">
+            <Button type="text" icon={<PlusOutlined />} onClick={() => handleAddColumn(rowKey)} title="Add column" />
+          </Col>
           <Col flex="32px">
             <Button
               type="text"
@@ -77,6 +177,7 @@ const SeatMapDesigner = ({ value, onChange }) => {
       </Button>
 
       <Divider style={{ margin: '20px 0' }}>Visual Preview</Divider>
+      <Text type="secondary">Click a seat to mark it as missing (a gap, pillar or wheelchair space).</Text>
       <div style={{ backgroundColor: '#fafafa', padding: '10px', borderRadius: '4px' }}>
         <div style={{ width: '100%', backgroundColor: '#333', color: '#fff', textAlign: 'center', padding: '5px', marginBottom: '10px', borderRadius: '2px' }}>Screen</div>
         {rows.map((rowKey) => (
@@ -84,19 +185,27 @@ const SeatMapDesigner = ({ value, onChange }) => {
             <Text style={{ marginRight: '10px', fontWeight: 'bold', minWidth: '30px' }}>R{rowKey}</Text>
             {seatMap[rowKey].map((numSeats, colIndex) => (
               <div key={colIndex} style={{ display: 'flex', border: '1px solid #ccc', margin: '0 5px', padding: '2px', borderRadius: '4px' }}>
-                {Array.from({ length: numSeats }).map((_, seatIndex) => (
-                  <div
-                    key={seatIndex}
-                    style={{
-                      width: '20px',
-                      height: '20px',
-                      backgroundColor: '#1890ff',
-                      margin: '2px',
-                      borderRadius: '3px',
-                    }}
-                    title={`Row ${rowKey}, Col ${colIndex + 1}, Seat ${seatIndex + 1}`}
-                  ></div>
-                ))}
+                {Array.from({ length: numSeats }).map((_, seatIndex) => {
+                  const seatId = `${rowKey}-${colIndex + 1}-${seatIndex + 1}`;
+                  const isMissing = missingSeats.includes(seatId);
+                  return (
+                    <div
+                      key={seatIndex}
+                      onClick={() => handleToggleSeat(seatId)}
+                      style={{
+                        width: '20px',
+                        height: '20px',
+                        backgroundColor: isMissing ? 'transparent' : '#1890ff',
+                        border: isMissing ? '1px dashed #bbb' : 'none',
+                        boxSizing: 'border-box',
+                        margin: '2px',
+                        borderRadius: '3px',
+                        cursor: 'pointer',
+                      }}
+                      title={`Row ${rowKey}, Col ${colIndex + 1}, Seat ${seatIndex + 1}${isMissing ? ' (missing)' : ''}`}
+                    ></div>
+                  );
+                })}
               </div>
             ))}
           </div>
@@ -3,7 +3,7 @@ import { Typography, Space } from 'antd';
 
 const { Text } = Typography;
 
-const SeatMapDisplay = ({ seatMap, bookedSeats = {} }) => {
+const SeatMapDisplay = ({ seatMap, bookedSeats = {}, selectedSeats = [], missingSeats = [], seatDetails = {}, onSeatClick }) => {
   if (!seatMap || Object.keys(seatMap).length === 0) {
     return <Text>No seat map available for this hall.</Text>;
   }
@@ -20,12 +20,21 @@ const SeatMapDisplay = ({ seatMap, bookedSeats = {} }) => {
             <div key={colIndex} className="seat-display-col-group">
               {Array.from({ length: numSeats }).map((_, seatIndex) => {
                 const seatId = `${rowKey}-${colIndex + 1}-${seatIndex + 1}`;
+                if (missingSeats.includes(seatId)) {
+                  return <div key={seatIndex} className="seat-display-seat missing"></div>;
+                }
                 const isBooked = bookedSeats[seatId];
+                const isSelected = selectedSeats.includes(seatId);
+                const status = isBooked ? 'booked' : isSelected ? 'selected' : 'available';
                 return (
                   <div
                     key={seatIndex}
-                    className={`seat-display-seat ${isBooked ? 'booked' : 'available'}`}
-                    title={`Row ${rowKey}, Col ${colIndex + 1}, Seat ${seatIndex + 1}`}
+                    className={`seat-display-seat ${status}`}
+                    title={`Row ${rowKey}, Col ${colIndex + 1}, Seat ${seatIndex + 1}${
+                      seatDetails[seatId] ? ` (${seatDetails[seatId].category}, ₹ ${seatDetails[seatId].price.toFixed(2)})` : ''
+                    }`}
+                    onClick={() => !isBooked && onSeatClick && onSeatClick(seatId)}
+                    style={onSeatClick && !isBooked ? { cursor: 'pointer' } : undefined}
                   ></div>
                 );
               })}
@@ -39,6 +48,12 @@ const SeatMapDisplay = ({ seatMap, bookedSeats = {} }) => {
           <Text>Available</Text>
           <div className="seat-display-legend-color booked"></div>
           <Text>Booked</Text>
+          {onSeatClick && (
+            <>
+              <div className="seat-display-legend-color selected"></div>
+              <Text>Selected</Text>
+            </>
+          )}
         </Space>
       </div>
     </div>
@@ -1,49 +1,99 @@
-import React, { createContext, useState, useEffect, useContext } from 'react';
+import React, { createContext, useState, useEffect, useContext, useCallback } from 'react';
 import { jwtDecode } from 'jwt-decode';
+import axios from 'axios';
+import { API_BASE_URL } from '../config/api';
 
 const AuthContext = createContext(null);
 
+// Refresh the access token this long before it expires
+const REFRESH_MARGIN_MS = 30 * 1000;
+
 export const AuthProvider = ({ children }) => {
   const [user, setUser] = useState(null);
   const [token, setToken] = useState(localStorage.getItem('token'));
+  const [refreshToken, setRefreshToken] = useState(localStorage.getItem('refreshToken'));
+
+  const clearSession = useCallback(() => {
+    localStorage.removeItem('token');
+    localStorage.removeItem('refreshToken');
+    setToken(null);
+    setRefreshToken(null);
+    setUser(null);
+  }, []);
+
+  const login = useCallback((tokens) => {
+    localStorage.setItem('token', tokens.access_token);
+    localStorage.setItem('refreshToken', tokens.refresh_token);
+    setToken(tokens.access_token);
+    setRefreshToken(tokens.refresh_token);
+  }, []);
+
+  // Exchange the refresh token for new tokens; each refresh token only works once
+  const refresh = useCallback(async () => {
+    try {
+      const response = await axios.post(`${API_BASE_URL}/token/refresh`, { refresh_token: refreshToken });
+      login(response.data.data);
+    } catch (error) {
+      console.error("Failed to refresh token:", error);
+      clearSession();
+    }
+  }, [refreshToken, login, clearSession]);
 
   useEffect(() => {
-    if (token) {
-      try {
-        const decoded = jwtDecode(token);
-        // Assuming 'exp' is the expiration time in seconds since epoch
-        if (decoded.exp * 1000 > Date.now()) {
-          setUser({ id: decoded.sub, role: decoded.iss, username: decoded.username }); // 'sub' is subject (user ID), 'iss' is issuer (role), 'username' is the new field
-        } else {
-          // Token expired
-          localStorage.removeItem('token');
-          setToken(null);
-          setUser(null);
-        }
-      } catch (error) {
-        console.error("Failed to decode token:", error);
-        localStorage.removeItem('token');
-        setToken(null);
-        setUser(null);
-      }
+    if (!token) {
+      return undefined;
+    }
+    let decoded;
+    try {
+      decoded = jwtDecode(token);
+    } catch (error) {
+      console.error("Failed to decode token:", error);
+      clearSession();
+      return undefined;
     }
-  }, [token]);
 
-  const login = (newToken) => {
-    localStorage.setItem('token', newToken);
-    setToken(newToken);
-  };
+    if (!refreshToken) {
+      // Sessions from before refresh tokens existed cannot be kept alive
+      clearSession();
+      return undefined;
+    }
 
-  const logout = () => {
-    localStorage.removeItem('token');
-    setToken(null);
-    setUser(null);
+    // 'sub' is subject (user ID); 'permissions' are those granted by the user's role;
+    // 'pwd_change' means the token only allows changing the password
+    setUser({
+      id: decoded.sub,
+      role: decoded.role,
+      username: decoded.username,
+      permissions: decoded.permissions || [],
+      mustChangePassword: Boolean(decoded.pwd_change),
+    });
+
+    // Access tokens are short-lived, so refresh shortly before this one expires
+    const refreshIn = decoded.exp * 1000 - Date.now() - REFRESH_MARGIN_MS;
+    const timer = setTimeout(refresh, Math.max(refreshIn, 0));
+    return () => clearTimeout(timer);
+  }, [token, refreshToken, refresh, clearSession]);
+
+  const logout = async () => {
+    try {
+      // Revoke the tokens server-side so they cannot be used again
+      await axios.post(
+        `${API_BASE_URL}/logout`,
+        { refresh_token: refreshToken },
+        { headers: { Authorization: `Bearer ${token}` } }
+      );
+    } catch (error) {
+      console.error("Failed to log out on the server:", error);
+    }
+    clearSession();
   };
 
   const isAdmin = user && user.role === 'admin';
+  // can reports whether the logged-in user's role grants a permission, such as 'shows:manage'
+  const can = (permission) => Boolean(user && user.permissions.includes(permission));
 
   return (
-    <AuthContext.Provider value={{ user, token, login, logout, isAdmin }}>
+    <AuthContext.Provider value={{ user, token, login, logout, isAdmin, can }}>
       {children}
     </AuthContext.Provider>
   );
@@ -67,6 +67,7 @@ function Booking() {
 
     try {
       const bookingRequest = {
+        showId: show.id,
         movieId: movie.id,
         hallId: hall.id,
         time: show.time,
@@ -83,8 +84,8 @@ function Booking() {
       const errorMessage = error.response?.data?.message || error.response?.data || 'An unexpected error occurred.';
       message.error(errorMessage);
 
-      if (error.response?.status === 409 && error.response?.data?.alternatives) {
-        setAlternativeShows(error.response.data.alternatives);
+      if (error.response?.status === 409 && error.response?.data?.data?.alternatives) {
+        setAlternativeShows(error.response.data.data.alternatives);
       } else {
         setAlternativeShows([]);
       }
@@ -0,0 +1,102 @@
+import React, { useState } from 'react';
+import axios from 'axios';
+import { useNavigate } from 'react-router-dom';
+import { toast } from 'react-toastify';
+import { Card, Form, Input, Button, Typography, Row, Col } from 'antd';
+import { API_BASE_URL } from '../config/api';
+import { useAuth } from '../context/AuthContext';
+import PasswordPolicyHint from '../components/PasswordPolicyHint';
+
+const { Title, Paragraph } = Typography;
+
+function ChangePassword() {
+  const navigate = useNavigate();
+  const { user, token, login } = useAuth();
+  const [loading, setLoading] = useState(false);
+
+  const onFinish = async (values) => {
+    setLoading(true);
+    try {
+      const response = await axios.put(
+        `${API_BASE_URL}/me/password`,
+        { current_password: values.current_password, new_password: values.new_password },
+        { headers: { Authorization: `Bearer ${token}` } }
+      );
+      // Changing the password revokes the old tokens, so continue with the new ones
+      login(response.data.data);
+      toast.success('Password changed successfully!');
+      navigate('/');
+    } catch (error) {
+      toast.error(error.response?.data?.message || 'Failed to change password.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <Row justify="center" align="middle" style={{ minHeight: 'calc(100vh - 134px)' }}>
+      <Col xs={24} sm={18} md={12} lg={8} xl={6}>
+        <Card
+          hoverable
+          style={{
+            borderRadius: '12px',
+            boxShadow: '0 4px 12px rgba(0, 0, 0, 0.08)',
+            border: 'none',
+            padding: '20px',
+          }}
+        >
+          <Title level={2} style={{ textAlign: 'center', marginBottom: '24px', color: '#2c3e50' }}>Change Password</Title>
+          {user?.mustChangePassword && (
+            <Paragraph type="warning">You must choose a new password before continuing.</Paragraph>
+          )}
+          <Form name="change_password" onFinish={onFinish} autoComplete="off" layout="vertical">
+            <Form.Item
+              label="Current Password"
+              name="current_password"
+              rules={[{ required: true, message: 'Please input your current password!' }]}
+            >
+              <Input.Password placeholder="Enter your current password" />
+            </Form.Item>
+
+            <Form.Item
+              label="New Password"
+              name="new_password"
+              extra={<PasswordPolicyHint />}
+              rules={[{ required: true, message: 'Please input a new password!' }]}
+            >
+              <Input.Password placeholder="Choose a new password" />
+            </Form.Item>
+
+            <Form.Item
+              label="Confirm New Password"
+              name="confirm"
+              dependencies={['new_password']}
+              hasFeedback
+              rules={[
+                { required: true, message: 'Please confirm your new password!' },
+                ({ getFieldValue }) => ({
+                  validator(_, value) {
+                    if (!value || getFieldValue('new_password') === value) {
+                      return Promise.resolve();
+                    }
+                    return Promise.reject(new Error('The two passwords that you entered do not match!'));
+                  },
+                }),
+              ]}
+            >
+              <Input.Password placeholder="Confirm your new password" />
+            </Form.Item>
+
+            <Form.Item>
+              <Button type="primary" htmlType="submit" loading={loading} block>
+                Change Password
+              </Button>
+            </Form.Item>
+          </Form>
+        </Card>
+      </Col>
+    </Row>
+  );
+}
+
+export default ChangePassword;
@@ -1,7 +1,7 @@
 import React, { useState } from 'react';
 import { Form, Input, Button, Typography, Row, Col, Card } from 'antd';
 import axios from 'axios';
-import { useNavigate } from 'react-router-dom';
+import { Link, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import { toast } from 'react-toastify';
 import { API_BASE_URL } from '../config/api';
@@ -17,7 +17,12 @@ function Login() {
     setLoading(true);
     try {
       const response = await axios.post(`${API_BASE_URL}/login`, values);
-      login(response.data.data.token);
+      login(response.data.data);
+      if (response.data.data.password_change_required) {
+        toast.info('Please choose a new password before continuing.');
+        navigate('/change-password');
+        return;
+      }
       toast.success('Login successful!');
       navigate('/');
     } catch (error) {
@@ -68,6 +73,7 @@ function Login() {
                 Log in
               </Button>
             </Form.Item>
+            <Link to="/reset-password">Have a password reset token?</Link>
           </Form>
         </Card>
       </Col>
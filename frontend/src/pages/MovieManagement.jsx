@@ -15,12 +15,15 @@ import {
   Typography,
   Row,
   Col,
+  Tag,
 } from "antd";
 import {
   PlusOutlined,
   EditOutlined,
   DeleteOutlined,
   VideoCameraOutlined,
+  InboxOutlined,
+  UndoOutlined,
 } from "@ant-design/icons";
 
 const { Title, Text } = Typography;
@@ -41,6 +44,7 @@ function MovieManagement() {
     try {
       const response = await axios.get(`${API_BASE_URL}/movies`, {
         headers: { Authorization: `Bearer ${token}` },
+        params: { includeArchived: true },
       });
       setMovies(response.data.data || []);
     } catch (error) {
@@ -69,15 +73,33 @@ function MovieManagement() {
     setIsModalOpen(true);
   };
 
-  const handleDelete = async (id) => {
+  // Movies are archived by default; archived movies can be restored or deleted permanently
+  const handleDelete = async (id, permanent = false) => {
     try {
-      await axios.delete(`${API_BASE_URL}/movies/${id}`, {
+      const response = await axios.delete(`${API_BASE_URL}/movies/${id}`, {
         headers: { Authorization: `Bearer ${token}` },
+        params: permanent ? { permanent: true } : undefined,
       });
-      toast.success("Movie deleted successfully!");
+      toast.success(response.data?.data?.message || "Movie archived successfully!");
       fetchMovies();
     } catch (error) {
-      toast.error(error.response?.data?.message || "Failed to delete movie.");
+      toast.error(
+          error.response?.data?.message ||
+          error.response?.data?.data?.message ||
+          "Failed to delete movie."
+      );
+    }
+  };
+
+  const handleRestore = async (id) => {
+    try {
+      await axios.post(`${API_BASE_URL}/movies/${id}/restore`, null, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      toast.success("Movie restored successfully!");
+      fetchMovies();
+    } catch (error) {
+      toast.error(error.response?.data?.message || "Failed to restore movie.");
     }
   };
 
@@ -115,7 +137,12 @@ function MovieManagement() {
       title: "Title",
       dataIndex: "title",
       key: "title",
-      render: (text) => <Text strong>{text}</Text>,
+      render: (text, record) => (
+          <Space>
+            <Text strong>{text}</Text>
+            {record.archived_at && <Tag>Archived</Tag>}
+          </Space>
+      ),
     },
     {
       title: "Duration (min)",
@@ -126,21 +153,35 @@ function MovieManagement() {
     {
       title: "Action",
       key: "action",
-      width: 150,
-      render: (_, record) => (
-          <Space>
-            <Button icon={<EditOutlined />} onClick={() => handleEdit(record)}>
-              Edit
-            </Button>
-            <Button
-                icon={<DeleteOutlined />}
-                danger
-                onClick={() => handleDelete(record.id)}
-            >
-              Delete
-            </Button>
-          </Space>
-      ),
+      width: 260,
+      render: (_, record) =>
+          record.archived_at ? (
+              <Space>
+                <Button icon={<UndoOutlined />} onClick={() => handleRestore(record.id)}>
+                  Restore
+                </Button>
+                <Button
+                    icon={<DeleteOutlined />}
+                    danger
+                    onClick={() => handleDelete(record.id, true)}
+                >
+                  Delete permanently
+                </Button>
+              </Space>
+          ) : (
+              <Space>
+                <Button icon={<EditOutlined />} onClick={() => handleEdit(record)}>
+                  Edit
+                </Button>
+                <Button
+                    icon={<InboxOutlined />}
+                    danger
+                    onClick={() => handleDelete(record.id)}
+                >
+                  Archive
+                </Button>
+              </Space>
+          ),
     },
   ];
 
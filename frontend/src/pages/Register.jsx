@@ -4,6 +4,7 @@ import { useNavigate } from 'react-router-dom';
 import { toast } from 'react-toastify';
 import { Card, Form, Input, Button, Typography, Row, Col } from 'antd';
 import { API_BASE_URL } from '../config/api';
+import PasswordPolicyHint from '../components/PasswordPolicyHint';
 
 const { Title } = Typography;
 
@@ -14,7 +15,8 @@ function Register() {
   const onFinish = async (values) => {
     setLoading(true);
     try {
-      await axios.post(`${API_BASE_URL}/register`, values);
+      // The confirmation field is only checked here
+      await axios.post(`${API_BASE_URL}/register`, { username: values.username, password: values.password });
       toast.success('Registration successful! Please log in.');
       navigate('/login');
     } catch (error) {
@@ -56,6 +58,7 @@ function Register() {
             <Form.Item
               label="Password"
               name="password"
+              extra={<PasswordPolicyHint />}
               rules={[{ required: true, message: 'Please input your password!' }]}
             >
               <Input.Password placeholder="Create a password" />
@@ -0,0 +1,79 @@
+import React, { useState } from 'react';
+import axios from 'axios';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { toast } from 'react-toastify';
+import { Card, Form, Input, Button, Typography, Row, Col } from 'antd';
+import { API_BASE_URL } from '../config/api';
+import PasswordPolicyHint from '../components/PasswordPolicyHint';
+
+const { Title } = Typography;
+
+// ResetPassword sets a new password with a one-time reset token issued by an admin
+function ResetPassword() {
+  const navigate = useNavigate();
+  const [searchParams] = useSearchParams();
+  const [loading, setLoading] = useState(false);
+
+  const onFinish = async (values) => {
+    setLoading(true);
+    try {
+      await axios.post(`${API_BASE_URL}/password/reset`, { reset_token: values.reset_token, new_password: values.new_password });
+      toast.success('Password reset! Please log in with your new password.');
+      navigate('/login');
+    } catch (error) {
+      toast.error(error.response?.data?.message || 'Failed to reset password.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <Row justify="center" align="middle" style={{ minHeight: 'calc(100vh - 134px)' }}>
+      <Col xs={24} sm={18} md={12} lg={8} xl={6}>
+        <Card
+          hoverable
+          style={{
+            borderRadius: '12px',
+            boxShadow: '0 4px 12px rgba(0, 0, 0, 0.08)',
+            border: 'none',
+            padding: '20px',
+          }}
+        >
+          <Title level={2} style={{ textAlign: 'center', marginBottom: '24px', color: '#2c3e50' }}>Reset Password</Title>
+          <Form
+            name="reset_password"
+            initialValues={{ reset_token: searchParams.get('token') || '' }}
+            onFinish={onFinish}
+            autoComplete="off"
+            layout="vertical"
+          >
+            <Form.Item
+              label="Reset Token"
+              name="reset_token"
+              rules={[{ required: true, message: 'Please input the reset token you were given!' }]}
+            >
+              <Input placeholder="Paste your reset token" />
+            </Form.Item>
+
+            <Form.Item
+              label="New Password"
+              name="new_password"
+              extra={<PasswordPolicyHint />}
+              rules={[{ required: true, message: 'Please input a new password!' }]}
+            >
+              <Input.Password placeholder="Choose a new password" />
+            </Form.Item>
+
+            <Form.Item>
+              <Button type="primary" htmlType="submit" loading={loading} block>
+                Reset Password
+              </Button>
+            </Form.Item>
+          </Form>
+        </Card>
+      </Col>
+    </Row>
+  );
+}
+
+export default ResetPassword;
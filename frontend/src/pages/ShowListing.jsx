@@ -9,6 +9,7 @@ import {
   Button,
   Modal,
   InputNumber,
+  Select,
   Space,
   Row,
   Col,
@@ -38,6 +39,9 @@ function ShowListing() {
   const [bookingLoading, setBookingLoading] = useState(false);
   const [currentHallDetails, setCurrentHallDetails] = useState(null);
   const [currentBookedSeats, setCurrentBookedSeats] = useState({});
+  const [selectedSeatIds, setSelectedSeatIds] = useState([]);
+  const [currentSeatDetails, setCurrentSeatDetails] = useState({});
+  const [seatCategory, setSeatCategory] = useState("");
 
   // Alternative shows modal state
   const [isAlternativesModalVisible, setIsAlternativesModalVisible] =
@@ -49,17 +53,13 @@ function ShowListing() {
       try {
         const [movieRes, showsRes, theatresRes, hallsRes] = await Promise.all([
           axios.get(`${API_BASE_URL}/movies/${movieId}`),
-          axios.get(`${API_BASE_URL}/shows`),
+          axios.get(`${API_BASE_URL}/shows`, { params: { movieId } }),
           axios.get(`${API_BASE_URL}/theatres`),
           axios.get(`${API_BASE_URL}/halls`),
         ]);
 
         setMovie(movieRes.data?.data || movieRes.data);
-        setShows(
-            (showsRes.data?.data || showsRes.data || []).filter(
-                (s) => s.movie_id === movieId
-            )
-        );
+        setShows(showsRes.data?.data?.shows || []);
 
         setTheatres(
             (theatresRes.data?.data || theatresRes.data || []).reduce(
@@ -92,6 +92,8 @@ function ShowListing() {
     }
     setSelectedShow(show);
     setNumSeats(1);
+    setSelectedSeatIds([]);
+    setSeatCategory("");
     setBookingLoading(true);
 
     try {
@@ -100,19 +102,19 @@ function ShowListing() {
       );
       setCurrentHallDetails(hallResponse.data.data);
 
-      const bookingsResponse = await axios.get(
-          `${API_BASE_URL}/bookings?showId=${show.id}`,
-          {
-            headers: { Authorization: `Bearer ${token}` },
-          }
+      const seatsResponse = await axios.get(
+          `${API_BASE_URL}/shows/${show.id}/seats`
       );
       const booked = {};
-      (bookingsResponse.data.data || []).forEach((booking) => {
-        booking.seat_ids.forEach((seatId) => {
-          booked[seatId] = true;
-        });
+      const details = {};
+      (seatsResponse.data.data?.seats || []).forEach((seat) => {
+        if (seat.status !== "available") {
+          booked[seat.id] = true;
+        }
+        details[seat.id] = { category: seat.category, price: seat.price };
       });
       setCurrentBookedSeats(booked);
+      setCurrentSeatDetails(details);
 
       setIsBookingModalVisible(true);
     } catch (error) {
@@ -125,15 +127,26 @@ function ShowListing() {
     }
   };
 
+  const handleSeatClick = (seatId) => {
+    setSelectedSeatIds((prev) =>
+        prev.includes(seatId)
+            ? prev.filter((id) => id !== seatId)
+            : [...prev, seatId]
+    );
+  };
+
   const handleBookingConfirm = async () => {
     if (!selectedShow) return;
     setBookingLoading(true);
     try {
       const bookingRequest = {
+        showId: selectedShow.id,
         movieId: selectedShow.movie_id,
         hallId: selectedShow.hall_id,
         time: selectedShow.time, // This should already be in UTC format from the server
         numSeats,
+        seatIds: selectedSeatIds.length > 0 ? selectedSeatIds : undefined,
+        category: seatCategory || undefined,
       };
       
       // Debug logging
@@ -151,7 +164,10 @@ function ShowListing() {
       const errorData = error.response?.data;
       const alternatives = errorData?.data?.alternatives;
 
-      if (error.response?.status === 409 && alternatives) {
+      if (error.response?.status === 409 && errorData?.data?.seat_ids) {
+        toast.error(errorData.message);
+        setSelectedSeatIds([]);
+      } else if (error.response?.status === 409 && alternatives) {
         setAlternativeShows(alternatives);
         setIsAlternativesModalVisible(true);
         setIsBookingModalVisible(false);
@@ -163,6 +179,12 @@ function ShowListing() {
     }
   };
 
+  // Seat categories of the current show with their prices, for the category picker
+  const categoryPrices = {};
+  Object.values(currentSeatDetails).forEach(({ category, price }) => {
+    categoryPrices[category] = price;
+  });
+
   if (loading) {
     return (
         <Spin size="large" style={{ display: "block", margin: "50px auto" }} />
@@ -259,18 +281,41 @@ function ShowListing() {
             width={currentHallDetails ? 800 : 520}
         >
           <Space direction="vertical" style={{ width: "100%" }}>
-            <Text>Select the number of seats you want to book together.</Text>
+            <Text>
+              Select the number of seats you want to book together, or pick
+              exact seats on the map.
+            </Text>
             <InputNumber
                 min={1}
                 max={10}
                 value={numSeats}
                 onChange={setNumSeats}
+                disabled={selectedSeatIds.length > 0}
                 style={{ width: "100%" }}
             />
+            {Object.keys(categoryPrices).length > 1 && (
+                <Select
+                    value={seatCategory}
+                    onChange={setSeatCategory}
+                    disabled={selectedSeatIds.length > 0}
+                    style={{ width: "100%" }}
+                >
+                  <Select.Option value="">Any seat category</Select.Option>
+                  {Object.entries(categoryPrices).map(([category, price]) => (
+                      <Select.Option key={category} value={category}>
+                        {category} (₹ {price.toFixed(2)})
+                      </Select.Option>
+                  ))}
+                </Select>
+            )}
             {currentHallDetails && (
                 <SeatMapDisplay
                     seatMap={currentHallDetails.seat_map}
+                    missingSeats={currentHallDetails.missing_seats || []}
+                    seatDetails={currentSeatDetails}
                     bookedSeats={currentBookedSeats}
+                    selectedSeats={selectedSeatIds}
+                    onSeatClick={handleSeatClick}
                 />
             )}
           </Space>
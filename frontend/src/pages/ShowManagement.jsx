@@ -17,8 +17,16 @@ import {
   Row,
   Col,
   DatePicker,
+  Tag,
 } from 'antd';
-import { PlusOutlined, EditOutlined, DeleteOutlined, VideoCameraOutlined } from '@ant-design/icons';
+import {
+  PlusOutlined,
+  EditOutlined,
+  DeleteOutlined,
+  VideoCameraOutlined,
+  InboxOutlined,
+  UndoOutlined,
+} from '@ant-design/icons';
 
 const { Title, Text } = Typography;
 const { Option } = Select;
@@ -44,14 +52,16 @@ function ShowManagement() {
   const fetchData = async () => {
     setLoading(true);
     try {
+      // Archived records are included so archived shows still show their movie and hall names
+      const params = { includeArchived: true };
       const [showsRes, moviesRes, theatresRes, hallsRes] = await Promise.all([
-        axios.get(`${API_BASE_URL}/shows`, { headers: { Authorization: `Bearer ${token}` } }),
-        axios.get(`${API_BASE_URL}/movies`, { headers: { Authorization: `Bearer ${token}` } }),
-        axios.get(`${API_BASE_URL}/theatres`, { headers: { Authorization: `Bearer ${token}` } }),
-        axios.get(`${API_BASE_URL}/halls`, { headers: { Authorization: `Bearer ${token}` } }),
+        axios.get(`${API_BASE_URL}/shows`, { params: { ...params, limit: 500 }, headers: { Authorization: `Bearer ${token}` } }),
+        axios.get(`${API_BASE_URL}/movies`, { params, headers: { Authorization: `Bearer ${token}` } }),
+        axios.get(`${API_BASE_URL}/theatres`, { params, headers: { Authorization: `Bearer ${token}` } }),
+        axios.get(`${API_BASE_URL}/halls`, { params, headers: { Authorization: `Bearer ${token}` } }),
       ]);
 
-      setShows(showsRes.data.data || []);
+      setShows(showsRes.data.data?.shows || []);
       setMovies(moviesRes.data.data || []);
       setTheatres(theatresRes.data.data || []);
       setHalls(hallsRes.data.data || []);
@@ -68,7 +78,7 @@ function ShowManagement() {
 
   const handleAdd = () => {
     setEditingShow(null);
-    setFormValues({ movie_id: '', theatre_id: '', hall_id: '', time: '', price: 0 });
+    setFormValues({ movie_id: '', theatre_id: '', hall_id: '', time: '', price: 0, category_prices: {} });
     setIsModalOpen(true);
   };
 
@@ -83,28 +93,61 @@ function ShowManagement() {
       hall_id: record.hall_id,
       time: dayjs(record.time).toISOString(),
       price: record.price,
+      category_prices: record.category_prices || {},
     });
     setIsModalOpen(true);
   };
 
-  const handleDelete = async (id) => {
+  // Shows are archived by default; archived shows can be restored or deleted permanently
+  const handleDelete = async (id, permanent = false) => {
+    try {
+      const response = await axios.delete(`${API_BASE_URL}/shows/${id}`, {
+        headers: { Authorization: `Bearer ${token}` },
+        params: permanent ? { permanent: true } : undefined,
+      });
+      toast.success(response.data?.data?.message || 'Show archived successfully!');
+      fetchData();
+    } catch (error) {
+      toast.error(
+          error.response?.data?.message ||
+          error.response?.data?.data?.message ||
+          'Failed to delete show.'
+      );
+    }
+  };
+
+  const handleRestore = async (id) => {
     try {
-      await axios.delete(`${API_BASE_URL}/shows/${id}`, {
+      await axios.post(`${API_BASE_URL}/shows/${id}/restore`, null, {
         headers: { Authorization: `Bearer ${token}` },
       });
-      toast.success('Show deleted successfully!');
+      toast.success('Show restored successfully!');
       fetchData();
     } catch (error) {
-      toast.error(error.response?.data?.message || 'Failed to delete show.');
+      toast.error(error.response?.data?.message || 'Failed to restore show.');
     }
   };
 
+  // Non-regular seat categories of the selected hall, which can be priced separately
+  const selectedHall = halls.find((h) => h.id === formValues.hall_id);
+  const hallCategories = [...new Set(Object.values(selectedHall?.seat_categories || {}))].filter(
+      (category) => category !== 'regular'
+  );
+
   const saveShow = async () => {
     try {
+      // theatre_id only narrows down the hall picker; the API rejects fields a show does not have
+      const { theatre_id: _theatreId, ...showValues } = formValues;
       const payload = {
-        ...formValues,
+        ...showValues,
         time: new Date(formValues.time).toISOString(),
         price: Number(formValues.price),
+        // Only send prices for categories the selected hall actually has
+        category_prices: Object.fromEntries(
+            Object.entries(formValues.category_prices || {}).filter(
+                ([category, price]) => hallCategories.includes(category) && price !== null && price !== undefined
+            )
+        ),
       };
 
       if (editingShow) {
@@ -142,7 +185,12 @@ function ShowManagement() {
       title: 'Time',
       dataIndex: 'time',
       key: 'time',
-      render: (time) => dayjs(time).format('YYYY-MM-DD HH:mm'),
+      render: (time, record) => (
+          <Space>
+            {dayjs(time).format('YYYY-MM-DD HH:mm')}
+            {record.archived_at && <Tag>Archived</Tag>}
+          </Space>
+      ),
     },
     {
       title: 'Price',
@@ -153,20 +201,34 @@ function ShowManagement() {
     {
       title: 'Action',
       key: 'action',
-      render: (_, record) => (
-          <Space>
-            <Button icon={<EditOutlined />} onClick={() => handleEdit(record)}>
-              Edit
-            </Button>
-            <Button
-                icon={<DeleteOutlined />}
-                danger
-                onClick={() => handleDelete(record.id)}
-            >
-              Delete
-            </Button>
-          </Space>
-      ),
+      render: (_, record) =>
+          record.archived_at ? (
+              <Space>
+                <Button icon={<UndoOutlined />} onClick={() => handleRestore(record.id)}>
+                  Restore
+                </Button>
+                <Button
+                    icon={<DeleteOutlined />}
+                    danger
+                    onClick={() => handleDelete(record.id, true)}
+                >
+                  Delete permanently
+                </Button>
+              </Space>
+          ) : (
+              <Space>
+                <Button icon={<EditOutlined />} onClick={() => handleEdit(record)}>
+                  Edit
+                </Button>
+                <Button
+                    icon={<InboxOutlined />}
+                    danger
+                    onClick={() => handleDelete(record.id)}
+                >
+                  Archive
+                </Button>
+              </Space>
+          ),
     },
   ];
 
@@ -233,7 +295,7 @@ function ShowManagement() {
                   value={formValues.movie_id}
                   onChange={(e) => setFormValues({ ...formValues, movie_id: e })}
               >
-                {movies.map((movie) => (
+                {movies.filter((movie) => !movie.archived_at).map((movie) => (
                     <Option key={movie.id} value={movie.id}>
                       {movie.title}
                     </Option>
@@ -249,7 +311,7 @@ function ShowManagement() {
                     setFormValues({ ...formValues, theatre_id: value, hall_id: '' });
                   }}
               >
-                {theatres.map((theatre) => (
+                {theatres.filter((theatre) => !theatre.archived_at).map((theatre) => (
                     <Option key={theatre.id} value={theatre.id}>
                       {theatre.name}
                     </Option>
@@ -265,7 +327,7 @@ function ShowManagement() {
                   onChange={(e) => setFormValues({ ...formValues, hall_id: e })}
               >
                 {halls
-                    .filter((hall) => hall.theatre_id === formValues.theatre_id)
+                    .filter((hall) => hall.theatre_id === formValues.theatre_id && !hall.archived_at)
                     .map((hall) => (
                         <Option key={hall.id} value={hall.id}>
                           {hall.name}
@@ -295,6 +357,23 @@ function ShowManagement() {
                   onChange={(e) => setFormValues({ ...formValues, price: e })}
               />
             </Form.Item>
+
+            {hallCategories.map((category) => (
+                <Form.Item key={category} label={`${category} seat price (defaults to ticket price)`}>
+                  <InputNumber
+                      min={0}
+                      step={0.01}
+                      style={{ width: '100%' }}
+                      value={formValues.category_prices?.[category]}
+                      onChange={(e) =>
+                          setFormValues({
+                            ...formValues,
+                            category_prices: { ...formValues.category_prices, [category]: e },
+                          })
+                      }
+                  />
+                </Form.Item>
+            ))}
           </Form>
         </Modal>
       </div>
@@ -12,6 +12,7 @@ import {
   Col,
   Card,
   Divider,
+  Tag,
 } from "antd";
 import {
   PlusOutlined,
@@ -19,6 +20,8 @@ import {
   DeleteOutlined,
   ClusterOutlined,
   HomeOutlined,
+  InboxOutlined,
+  UndoOutlined,
 } from "@ant-design/icons";
 import axios from "axios";
 import { useAuth } from "../context/AuthContext";
@@ -41,6 +44,8 @@ function TheatreManagement() {
   const [editingHall, setEditingHall] = useState(null);
 
   const [currentSeatMap, setCurrentSeatMap] = useState({});
+  const [currentMissingSeats, setCurrentMissingSeats] = useState([]);
+  const [currentSeatCategories, setCurrentSeatCategories] = useState({});
 
   const [form] = Form.useForm();
   const [hallForm] = Form.useForm();
@@ -50,6 +55,7 @@ function TheatreManagement() {
     try {
       const response = await axios.get(`${API_BASE_URL}/theatres`, {
         headers: { Authorization: `Bearer ${token}` },
+        params: { includeArchived: true },
       });
       setTheatres(response.data.data || []);
     } catch (error) {
@@ -63,8 +69,11 @@ function TheatreManagement() {
     setLoading(true);
     try {
       const response = await axios.get(
-          `${API_BASE_URL}/halls?theatreId=${theatreId}`,
-          { headers: { Authorization: `Bearer ${token}` } }
+          `${API_BASE_URL}/halls`,
+          {
+            headers: { Authorization: `Bearer ${token}` },
+            params: { theatreId, includeArchived: true },
+          }
       );
       setHalls(response.data.data || []);
     } catch (error) {
@@ -91,14 +100,60 @@ function TheatreManagement() {
     setIsTheatreModalOpen(true);
   };
 
-  const handleDeleteTheatre = async (id) => {
+  // Upcoming shows with confirmed bookings block a delete until the admin confirms it
+  const confirmForceDelete = (entity, summary, onConfirm) => {
+    Modal.confirm({
+      title: `Delete this ${entity} anyway?`,
+      content: `${summary.upcoming_shows} upcoming shows have ${summary.active_bookings} confirmed bookings. Deleting the ${entity} removes ${summary.shows} shows and ${summary.bookings} bookings.`,
+      okText: "Delete anyway",
+      okType: "danger",
+      onOk: onConfirm,
+    });
+  };
+
+  // Theatres are archived by default; only archived theatres are deleted permanently
+  const handleArchiveTheatre = async (id) => {
     try {
       await axios.delete(`${API_BASE_URL}/theatres/${id}`, {
         headers: { Authorization: `Bearer ${token}` },
       });
+      toast.success("Theatre archived successfully!");
+      fetchTheatres();
+    } catch (error) {
+      toast.error(
+          error.response?.data?.message ||
+          error.response?.data?.data?.message ||
+          "Failed to archive theatre."
+      );
+    }
+  };
+
+  const handleRestoreTheatre = async (id) => {
+    try {
+      await axios.post(`${API_BASE_URL}/theatres/${id}/restore`, null, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      toast.success("Theatre restored successfully!");
+      fetchTheatres();
+    } catch (error) {
+      toast.error(error.response?.data?.message || "Failed to restore theatre.");
+    }
+  };
+
+  const handleDeleteTheatre = async (id, force = false) => {
+    try {
+      await axios.delete(`${API_BASE_URL}/theatres/${id}`, {
+        headers: { Authorization: `Bearer ${token}` },
+        params: force ? { permanent: true, force: true } : { permanent: true },
+      });
       toast.success("Theatre deleted successfully!");
       fetchTheatres();
     } catch (error) {
+      const summary = error.response?.data?.data?.summary;
+      if (error.response?.status === 409 && summary && !force) {
+        confirmForceDelete("theatre", summary, () => handleDeleteTheatre(id, true));
+        return;
+      }
       toast.error(error.response?.data?.message || "Failed to delete theatre.");
     }
   };
@@ -148,6 +203,8 @@ function TheatreManagement() {
     setEditingHall(null);
     hallForm.resetFields();
     setCurrentSeatMap({});
+    setCurrentMissingSeats([]);
+    setCurrentSeatCategories({});
     setIsAddEditHallModalOpen(true);
   };
 
@@ -155,17 +212,53 @@ function TheatreManagement() {
     setEditingHall(record);
     hallForm.setFieldsValue({ name: record.name });
     setCurrentSeatMap(record.seat_map || {});
+    setCurrentMissingSeats(record.missing_seats || []);
+    setCurrentSeatCategories(record.seat_categories || {});
     setIsAddEditHallModalOpen(true);
   };
 
-  const handleDeleteHall = async (id) => {
+  const handleArchiveHall = async (id) => {
     try {
       await axios.delete(`${API_BASE_URL}/halls/${id}`, {
         headers: { Authorization: `Bearer ${token}` },
       });
+      toast.success("Hall archived successfully!");
+      fetchHalls(selectedTheatre.id);
+    } catch (error) {
+      toast.error(
+          error.response?.data?.message ||
+          error.response?.data?.data?.message ||
+          "Failed to archive hall."
+      );
+    }
+  };
+
+  const handleRestoreHall = async (id) => {
+    try {
+      await axios.post(`${API_BASE_URL}/halls/${id}/restore`, null, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      toast.success("Hall restored successfully!");
+      fetchHalls(selectedTheatre.id);
+    } catch (error) {
+      toast.error(error.response?.data?.message || "Failed to restore hall.");
+    }
+  };
+
+  const handleDeleteHall = async (id, force = false) => {
+    try {
+      await axios.delete(`${API_BASE_URL}/halls/${id}`, {
+        headers: { Authorization: `Bearer ${token}` },
+        params: force ? { permanent: true, force: true } : { permanent: true },
+      });
       toast.success("Hall deleted successfully!");
       fetchHalls(selectedTheatre.id);
     } catch (error) {
+      const summary = error.response?.data?.data?.summary;
+      if (error.response?.status === 409 && summary && !force) {
+        confirmForceDelete("hall", summary, () => handleDeleteHall(id, true));
+        return;
+      }
       toast.error(error.response?.data?.message || "Failed to delete hall.");
     }
   };
@@ -181,6 +274,8 @@ function TheatreManagement() {
       const payload = {
         ...formValues,
         seat_map: currentSeatMap,
+        missing_seats: currentMissingSeats,
+        seat_categories: currentSeatCategories,
         theatre_id: selectedTheatre.id,
       };
 
@@ -207,22 +302,49 @@ function TheatreManagement() {
   // --- Columns ---
   const theatreColumns = [
     { title: "ID", dataIndex: "id", key: "id", width: 80 },
-    { title: "Name", dataIndex: "name", key: "name" },
+    {
+      title: "Name",
+      dataIndex: "name",
+      key: "name",
+      render: (name, record) => (
+          <Space>
+            {name}
+            {record.archived_at && <Tag>Archived</Tag>}
+          </Space>
+      ),
+    },
     {
       title: "Action",
       key: "action",
       render: (_, record) => (
           <Space>
-            <Button icon={<EditOutlined />} onClick={() => handleEditTheatre(record)}>
-              Edit
-            </Button>
-            <Button
-                icon={<DeleteOutlined />}
-                danger
-                onClick={() => handleDeleteTheatre(record.id)}
-            >
-              Delete
-            </Button>
+            {record.archived_at ? (
+                <>
+                  <Button icon={<UndoOutlined />} onClick={() => handleRestoreTheatre(record.id)}>
+                    Restore
+                  </Button>
+                  <Button
+                      icon={<DeleteOutlined />}
+                      danger
+                      onClick={() => handleDeleteTheatre(record.id)}
+                  >
+                    Delete permanently
+                  </Button>
+                </>
+            ) : (
+                <>
+                  <Button icon={<EditOutlined />} onClick={() => handleEditTheatre(record)}>
+                    Edit
+                  </Button>
+                  <Button
+                      icon={<InboxOutlined />}
+                      danger
+                      onClick={() => handleArchiveTheatre(record.id)}
+                  >
+                    Archive
+                  </Button>
+                </>
+            )}
             <Button
                 icon={<ClusterOutlined />}
                 type="dashed"
@@ -237,7 +359,17 @@ function TheatreManagement() {
 
   const hallColumns = [
     { title: "ID", dataIndex: "id", key: "id", width: 80 },
-    { title: "Name", dataIndex: "name", key: "name" },
+    {
+      title: "Name",
+      dataIndex: "name",
+      key: "name",
+      render: (name, record) => (
+          <Space>
+            {name}
+            {record.archived_at && <Tag>Archived</Tag>}
+          </Space>
+      ),
+    },
     {
       title: "Seat Map",
       dataIndex: "seat_map",
@@ -249,20 +381,34 @@ function TheatreManagement() {
     {
       title: "Action",
       key: "action",
-      render: (_, record) => (
-          <Space>
-            <Button icon={<EditOutlined />} onClick={() => handleEditHall(record)}>
-              Edit
-            </Button>
-            <Button
-                icon={<DeleteOutlined />}
-                danger
-                onClick={() => handleDeleteHall(record.id)}
-            >
-              Delete
-            </Button>
-          </Space>
-      ),
+      render: (_, record) =>
+          record.archived_at ? (
+              <Space>
+                <Button icon={<UndoOutlined />} onClick={() => handleRestoreHall(record.id)}>
+                  Restore
+                </Button>
+                <Button
+                    icon={<DeleteOutlined />}
+                    danger
+                    onClick={() => handleDeleteHall(record.id)}
+                >
+                  Delete permanently
+                </Button>
+              </Space>
+          ) : (
+              <Space>
+                <Button icon={<EditOutlined />} onClick={() => handleEditHall(record)}>
+                  Edit
+                </Button>
+                <Button
+                    icon={<InboxOutlined />}
+                    danger
+                    onClick={() => handleArchiveHall(record.id)}
+                >
+                  Archive
+                </Button>
+              </Space>
+          ),
     },
   ];
 
@@ -382,7 +528,14 @@ function TheatreManagement() {
             </Form.Item>
           </Form>
           <Divider orientation="left">Seat Map Designer</Divider>
-          <SeatMapDesigner value={currentSeatMap} onChange={setCurrentSeatMap} />
+          <SeatMapDesigner
+              value={currentSeatMap}
+              onChange={setCurrentSeatMap}
+              missingSeats={currentMissingSeats}
+              onMissingSeatsChange={setCurrentMissingSeats}
+              seatCategories={currentSeatCategories}
+              onSeatCategoriesChange={setCurrentSeatCategories}
+          />
         </Modal>
       </div>
   );
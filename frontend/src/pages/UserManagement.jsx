@@ -11,6 +11,8 @@ const { Option } = Select;
 function UserManagement() {
   const { token, user, isAdmin } = useAuth();
   const [users, setUsers] = useState([]);
+  const [theatres, setTheatres] = useState([]);
+  const [lockouts, setLockouts] = useState([]);
   const [loading, setLoading] = useState(false);
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [editingUser, setEditingUser] = useState(null);
@@ -24,9 +26,13 @@ function UserManagement() {
         setLoading(false);
         return;
       }
-      const response = await axios.get(`${API_BASE_URL}/users`, {
-        headers: { Authorization: `Bearer ${token}` },
-      });
+      const [response, theatresRes, lockoutsRes] = await Promise.all([
+        axios.get(`${API_BASE_URL}/users`, { headers: { Authorization: `Bearer ${token}` } }),
+        axios.get(`${API_BASE_URL}/theatres`),
+        axios.get(`${API_BASE_URL}/login-lockouts`, { headers: { Authorization: `Bearer ${token}` } }),
+      ]);
+      setTheatres(theatresRes.data.data || []);
+      setLockouts(lockoutsRes.data.data || []);
       if (response.data?.status?.success) {
         setUsers(response.data.data || []);
       } else {
@@ -48,7 +54,7 @@ function UserManagement() {
 
   const handleEdit = (record) => {
     setEditingUser(record);
-    form.setFieldsValue(record);
+    form.setFieldsValue({ ...record, theatre_ids: record.theatre_ids || [] });
     setIsModalOpen(true);
   };
 
@@ -60,7 +66,10 @@ function UserManagement() {
         await axios.put(`${API_BASE_URL}/users/${editingUser.id}/role`, { role: values.role }, {
           headers: { Authorization: `Bearer ${token}` },
         });
-        toast.success('User role updated successfully!');
+        await axios.put(`${API_BASE_URL}/users/${editingUser.id}/theatres`, { theatre_ids: values.theatre_ids || [] }, {
+          headers: { Authorization: `Bearer ${token}` },
+        });
+        toast.success('User updated successfully!');
       }
       setIsModalOpen(false);
       fetchUsers();
@@ -73,16 +82,83 @@ function UserManagement() {
 
   const handleCancel = () => setIsModalOpen(false);
 
+  // Issue a one-time reset token for the admin to hand to the user out of band
+  const handleResetPassword = async (record) => {
+    try {
+      const response = await axios.post(`${API_BASE_URL}/users/${record.id}/password-reset`, null, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      const reset = response.data.data;
+      Modal.info({
+        title: `Password reset token for ${record.username}`,
+        content: (
+            <div>
+              <Text copyable code>{reset.reset_token}</Text>
+              <p style={{ marginTop: '12px' }}>
+                Give this token to the user; they can set a new password at /reset-password until {new Date(reset.expires_at).toLocaleString()}.
+                It works once and is not shown again.
+              </p>
+            </div>
+        ),
+      });
+    } catch (error) {
+      toast.error(error.response?.data?.message || 'Failed to issue a password reset token.');
+    }
+  };
+
+  // Lift the lockout of a username or IP locked after too many failed logins
+  const handleClearLockout = async (record) => {
+    try {
+      await axios.delete(`${API_BASE_URL}/login-lockouts`, {
+        headers: { Authorization: `Bearer ${token}` },
+        params: { kind: record.kind, value: record.value },
+      });
+      toast.success('Lockout cleared!');
+      fetchUsers();
+    } catch (error) {
+      toast.error(error.response?.data?.message || 'Failed to clear the lockout.');
+    }
+  };
+
+  const lockoutColumns = [
+    { title: 'Type', dataIndex: 'kind', key: 'kind', width: 100, render: (kind) => (kind === 'ip' ? 'IP address' : 'Username') },
+    { title: 'Username / IP', dataIndex: 'value', key: 'value' },
+    { title: 'Failed Logins', dataIndex: 'failures', key: 'failures', width: 120 },
+    {
+      title: 'Locked Until',
+      dataIndex: 'locked_until',
+      key: 'locked_until',
+      render: (lockedUntil) => new Date(lockedUntil).toLocaleString(),
+    },
+    {
+      title: 'Action',
+      key: 'action',
+      width: 120,
+      render: (_, record) => <Button type="link" onClick={() => handleClearLockout(record)}>Clear</Button>,
+    },
+  ];
+
   const columns = [
     { title: 'ID', dataIndex: 'id', key: 'id', width: 100 },
     { title: 'Username', dataIndex: 'username', key: 'username' },
     { title: 'Role', dataIndex: 'role', key: 'role', width: 120 },
+    {
+      title: 'Theatres',
+      dataIndex: 'theatre_ids',
+      key: 'theatre_ids',
+      render: (ids) => (ids || [])
+          .map((id) => theatres.find((theatre) => theatre.id === id)?.name || id)
+          .join(', '),
+    },
     {
       title: 'Action',
       key: 'action',
-      width: 150,
+      width: 240,
       render: (_, record) => (
-          <Button type="link" onClick={() => handleEdit(record)}>Edit Role</Button>
+          <>
+            <Button type="link" onClick={() => handleEdit(record)}>Edit</Button>
+            <Button type="link" onClick={() => handleResetPassword(record)}>Reset Password</Button>
+          </>
       ),
     },
   ];
@@ -123,13 +199,23 @@ function UserManagement() {
                       locale={{ emptyText: 'No users found.' }}
                   />
               )}
+              <Title level={4} style={{ marginTop: '24px' }}>Login Lockouts</Title>
+              <Table
+                  columns={lockoutColumns}
+                  dataSource={lockouts}
+                  loading={loading}
+                  rowKey={(record) => `${record.kind}:${record.value}`}
+                  pagination={false}
+                  scroll={{ x: true }}
+                  locale={{ emptyText: 'No usernames or addresses are locked out.' }}
+              />
             </Card>
           </Col>
         </Row>
 
         <Modal
             key={isModalOpen ? 'user-modal-open' : 'user-modal-closed'}
-            title="Edit User Role"
+            title="Edit User"
             open={isModalOpen}
             onOk={handleOk}
             onCancel={handleCancel}
@@ -149,10 +235,23 @@ function UserManagement() {
                 rules={[{ required: true, message: 'Please select a role!' }]}
             >
               <Select placeholder="Select a role">
-                <Option value="user">User</Option>
+                <Option value="customer">Customer</Option>
+                <Option value="box_office">Box Office Staff</Option>
+                <Option value="theatre_manager">Theatre Manager</Option>
                 <Option value="admin">Admin</Option>
               </Select>
             </Form.Item>
+            <Form.Item
+                name="theatre_ids"
+                label="Theatres"
+                extra="Box office staff and theatre managers can only act on these theatres. Admins act on every theatre."
+            >
+              <Select mode="multiple" placeholder="Select theatres" optionFilterProp="children">
+                {theatres.map((theatre) => (
+                    <Option key={theatre.id} value={theatre.id}>{theatre.name}</Option>
+                ))}
+              </Select>
+            </Form.Item>
           </Form>
         </Modal>
       </div>